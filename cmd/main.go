@@ -242,14 +242,21 @@ func validateJsonBySchema(fileJsonUri string, fileJsonBytes []byte) error {
 }
 
 // buildCacheStore builds and configures a cache store based on the provided storeDTO.
-// If storeDTO.Redis is not empty, it returns a new Redis cache store initialized with the Redis address and password.
-// Otherwise, it returns a new Memory cache store.
-func buildCacheStore(storeDTO *dto.Store) infra.CacheStore {
+// If storeDTO.Redis is not empty, it returns a new Redis cache store initialized with the Redis configuration, or an
+// error if the configuration is invalid.
+// Otherwise, it returns a new Memory cache store, limited by storeDTO.Memory if informed.
+func buildCacheStore(storeDTO *dto.Store) (infra.CacheStore, error) {
 	printInfoLog("Configuring cache store...")
-	if helper.IsNotNil(storeDTO) {
-		return infra.NewRedisStore(storeDTO.Redis.Address, storeDTO.Redis.Password)
+	if helper.IsNotNil(storeDTO) && helper.IsNotNil(storeDTO.Redis) {
+		redisStore, err := infra.NewRedisStore(storeDTO.Redis)
+		if helper.IsNotNil(err) {
+			return nil, errors.New("Error configure redis store:", err)
+		}
+		return redisStore, nil
+	} else if helper.IsNotNil(storeDTO) && helper.IsNotNil(storeDTO.Memory) {
+		return infra.NewMemoryStore(storeDTO.Memory.MaxEntries, vo.NewBytes(storeDTO.Memory.MaxMemory)), nil
 	}
-	return infra.NewMemoryStore()
+	return infra.NewMemoryStore(0, 0), nil
 }
 
 // listerAndServer initializes and runs the Gopen application with the provided cache store and Gopen configuration.
@@ -259,12 +266,12 @@ func listerAndServer(cacheStore infra.CacheStore, gopenVO *vo.Gopen) {
 	printInfoLog("Building infra..")
 	restTemplate := infra.NewRestTemplate()
 	traceProvider := infra.NewTraceProvider()
-	logProvider := infra.NewLogProvider()
+	logProvider := infra.NewLogProvider(gopenVO.Redaction().LogRules())
 
 	printInfoLog("Building domain..")
 	modifierService := service.NewModifier()
-	backendService := service.NewBackend(modifierService, restTemplate)
-	endpointService := service.NewEndpoint(backendService)
+	backendService := service.NewBackend(modifierService, restTemplate, cacheStore)
+	endpointService := service.NewEndpoint(modifierService, backendService)
 
 	printInfoLog("Building middlewares..")
 	traceMiddleware := middleware.NewTrace(traceProvider)
@@ -276,6 +283,7 @@ func listerAndServer(cacheStore infra.CacheStore, gopenVO *vo.Gopen) {
 
 	printInfoLog("Building controllers..")
 	staticController := controller.NewStatic(gopenVO)
+	cacheController := controller.NewCache(gopenVO, cacheStore)
 	endpointController := controller.NewEndpoint(endpointService)
 
 	printInfoLog("Building application..")
@@ -288,6 +296,7 @@ func listerAndServer(cacheStore infra.CacheStore, gopenVO *vo.Gopen) {
 		limiterMiddleware,
 		cacheMiddleware,
 		staticController,
+		cacheController,
 		endpointController,
 	)
 
@@ -358,6 +367,13 @@ func configureWatcher(env string, gopenDTO *dto.Gopen) *fsnotify.Watcher {
 	if helper.IsNotNil(err) {
 		printWarningLogf("Error add watcher on file: %s err: %s", fileJsonUri, err)
 	}
+	// por último adicionamos os arquivos de schema, para compilar novamente quando alterados
+	for _, schemaFile := range getSchemaFiles(gopenDTO) {
+		err = watcher.Add(schemaFile)
+		if helper.IsNotNil(err) {
+			printWarningLogf("Error add watcher on file: %s err: %s", schemaFile, err)
+		}
+	}
 
 	return watcher
 }
@@ -408,14 +424,11 @@ func executeErrorEvent(err error, ok bool) {
 	printWarningLogf("Watcher event error triggered! err: %s", err)
 }
 
-// startApp configures the store interface, sets up the watcher
-// for listening to configuration file changes, builds the value
-// objects, and calls the listerAndServer function.
+// startApp sets up the watcher for listening to configuration file
+// changes, builds and validates the value objects, configures the
+// store interface, and calls the listerAndServer function. It panics
+// if the configuration or the store configuration is invalid.
 func startApp(env string, gopenDTO *dto.Gopen) {
-	// configuramos o store interface
-	cacheStore := buildCacheStore(gopenDTO.Store)
-	defer closeCacheStore(cacheStore)
-
 	// configuramos o watch para ouvir mudanças do json de configuração
 	watcher := configureWatcher(env, gopenDTO)
 	defer closeWatcher(watcher)
@@ -424,6 +437,19 @@ func startApp(env string, gopenDTO *dto.Gopen) {
 	printInfoLog("Building value objects..")
 	gopenVO := vo.NewGopen(env, gopenDTO)
 
+	// validamos os modificadores e schemas, rejeitando os que nunca poderão ser aplicados
+	printInfoLog("Validating configuration..")
+	if err := gopenVO.Validate(); helper.IsNotNil(err) {
+		panic(err)
+	}
+
+	// configuramos o store interface, rejeitando a configuração do redis inválida
+	cacheStore, err := buildCacheStore(gopenDTO.Store)
+	if helper.IsNotNil(err) {
+		panic(err)
+	}
+	defer closeCacheStore(cacheStore)
+
 	// salvamos o gopenDTO resultante
 	writeGopenJsonResult(gopenVO, gopenDTO.Store)
 
@@ -516,6 +542,29 @@ func getFileJsonUri(env string) string {
 	return fmt.Sprintf("./gopen/%s/.json", env)
 }
 
+// getSchemaFiles returns the files of the request schemas of the endpoints and the response schemas of the
+// middlewares and backends configured in the gopenDTO.
+func getSchemaFiles(gopenDTO *dto.Gopen) []string {
+	var files []string
+	addResponseSchemaFile := func(backendDTO dto.Backend) {
+		if helper.IsNotNil(backendDTO.ResponseSchema) && helper.IsNotEmpty(backendDTO.ResponseSchema.File) {
+			files = append(files, backendDTO.ResponseSchema.File)
+		}
+	}
+	for _, middlewareDTO := range gopenDTO.Middlewares {
+		addResponseSchemaFile(middlewareDTO)
+	}
+	for _, endpointDTO := range gopenDTO.Endpoints {
+		if helper.IsNotNil(endpointDTO.RequestSchema) && helper.IsNotEmpty(endpointDTO.RequestSchema.File) {
+			files = append(files, endpointDTO.RequestSchema.File)
+		}
+		for _, backendDTO := range endpointDTO.Backends {
+			addResponseSchemaFile(backendDTO)
+		}
+	}
+	return files
+}
+
 // printInfoLog prints an informational log message using the logger package.
 func printInfoLog(msg ...any) {
 	logger.InfoOpts(loggerOptions, msg...)
@@ -0,0 +1,292 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"github.com/GabrielHCataldo/go-errors/errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/app/mapper"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/vo"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/infra"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"regexp"
+	"sort"
+)
+
+// cache represents the admin handler of the cache, used to inspect and purge the cached responses.
+// It contains a gopenVO field, used to find the endpoints, and the cacheStore where the responses are cached.
+type cache struct {
+	gopenVO    *vo.Gopen
+	cacheStore infra.CacheStore
+}
+
+// Cache represents an interface for handling the admin requests related to the cached responses.
+// The keys are selected by the query `endpoint` and `method` (default GET), the path and method of a configured
+// endpoint, including the keys of its backends, and by the query `pattern`, a glob pattern, or `prefix`, the prefix
+// of the keys.
+type Cache interface {
+	// Authorize handles every request to the cache admin routes, aborting it with the status code 401 (Unauthorized)
+	// if the Authorization header is not the bearer token of the admin configuration.
+	Authorize(ctx *gin.Context)
+	// Keys handles the GET request to the "/cache/keys" endpoint, listing the selected keys with their TTLs.
+	Keys(ctx *gin.Context)
+	// PurgeKeys handles the DELETE request to the "/cache/keys" endpoint, deleting the selected keys.
+	PurgeKeys(ctx *gin.Context)
+	// Entry handles the GET request to the "/cache/entry" endpoint, looking up the cache entry of the endpoint for
+	// the request of the query `url`, with the headers of the admin request as strategy headers.
+	Entry(ctx *gin.Context)
+	// PurgeEntry handles the DELETE request to the "/cache/entry" endpoint, deleting the cache entry looked up like
+	// Entry.
+	PurgeEntry(ctx *gin.Context)
+	// Stats handles the GET request to the "/cache/stats" endpoint, responding with the counters of the cache store.
+	Stats(ctx *gin.Context)
+}
+
+// NewCache is a function that creates a new instance of the Cache interface.
+// It takes a vo.Gopen parameter, used to find the endpoints, and the infra.CacheStore where the responses are cached.
+func NewCache(gopenVO *vo.Gopen, cacheStore infra.CacheStore) Cache {
+	return cache{
+		gopenVO:    gopenVO,
+		cacheStore: cacheStore,
+	}
+}
+
+// Authorize is a method that handles the authorization of the cache admin requests.
+// If the Authorization header is not the bearer token of the admin configuration, it aborts the request with the
+// status code 401 (Unauthorized), otherwise it calls the next handler.
+func (c cache) Authorize(ctx *gin.Context) {
+	if helper.IsNil(c.gopenVO.Admin()) || !c.gopenVO.Admin().Authorized(ctx.GetHeader("Authorization")) {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	ctx.Next()
+}
+
+// Keys is a method that handles the "Keys" request.
+// It scans the selected keys and responds with them, sorted, and the TTL of each one in JSON format and a status code
+// of 200 (OK). The keys that expire while listed are not returned.
+func (c cache) Keys(ctx *gin.Context) {
+	keys, ok := c.selectKeys(ctx)
+	if !ok {
+		return
+	}
+
+	result := []dto.CacheKeyView{}
+	for _, key := range keys {
+		var cacheResponse vo.CacheResponse
+		if err := c.cacheStore.Peek(ctx, key, &cacheResponse); errors.Is(err, mapper.ErrCacheNotFound) {
+			continue
+		} else if helper.IsNotNil(err) {
+			result = append(result, dto.CacheKeyView{Key: key})
+			continue
+		}
+		result = append(result, dto.CacheKeyView{Key: key, TTL: cacheResponse.TTL()})
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// PurgeKeys is a method that handles the "PurgeKeys" request.
+// It deletes the selected keys and responds with the number of deleted keys in JSON format and a status code of
+// 200 (OK). Without the query `endpoint`, the keys are deleted directly by the pattern on the cache store.
+func (c cache) PurgeKeys(ctx *gin.Context) {
+	endpointVO, patterns, ok := c.selectPatterns(ctx)
+	if !ok {
+		return
+	} else if helper.IsNil(endpointVO) {
+		deleted, err := c.cacheStore.DelByPattern(ctx, patterns[0])
+		if helper.IsNotNil(err) {
+			ctx.String(http.StatusInternalServerError, "%s", errors.Details(err).GetMessage())
+			return
+		}
+		ctx.JSON(http.StatusOK, dto.CachePurgeView{Deleted: deleted})
+		return
+	}
+
+	keys, ok := c.selectKeys(ctx)
+	if !ok {
+		return
+	}
+	deleted := 0
+	for _, key := range keys {
+		if err := c.cacheStore.Del(ctx, key); helper.IsNil(err) {
+			deleted++
+		}
+	}
+	ctx.JSON(http.StatusOK, dto.CachePurgeView{Deleted: deleted})
+}
+
+// Entry is a method that handles the "Entry" request.
+// It looks up the cache entry of the endpoint for the request and responds with the key, the TTL and the cached
+// response in JSON format and a status code of 200 (OK). If the entry is not found, it responds with the key and a
+// status code of 404 (Not Found).
+func (c cache) Entry(ctx *gin.Context) {
+	key, ok := c.entryKey(ctx)
+	if !ok {
+		return
+	}
+
+	var cacheResponse vo.CacheResponse
+	if err := c.cacheStore.Peek(ctx, key, &cacheResponse); errors.Is(err, mapper.ErrCacheNotFound) {
+		ctx.JSON(http.StatusNotFound, dto.CacheEntryView{Key: key})
+		return
+	} else if helper.IsNotNil(err) {
+		ctx.String(http.StatusInternalServerError, "%s", errors.Details(err).GetMessage())
+		return
+	}
+	ctx.JSON(http.StatusOK, dto.CacheEntryView{Key: key, TTL: cacheResponse.TTL(), Value: cacheResponse})
+}
+
+// PurgeEntry is a method that handles the "PurgeEntry" request.
+// It deletes the cache entry of the endpoint for the request and responds with a status code of 204 (No Content).
+// If the entry is not found, it responds with the key and a status code of 404 (Not Found).
+func (c cache) PurgeEntry(ctx *gin.Context) {
+	key, ok := c.entryKey(ctx)
+	if !ok {
+		return
+	}
+
+	var cacheResponse vo.CacheResponse
+	if err := c.cacheStore.Peek(ctx, key, &cacheResponse); errors.Is(err, mapper.ErrCacheNotFound) {
+		ctx.JSON(http.StatusNotFound, dto.CacheEntryView{Key: key})
+		return
+	} else if err = c.cacheStore.Del(ctx, key); helper.IsNotNil(err) {
+		ctx.String(http.StatusInternalServerError, "%s", errors.Details(err).GetMessage())
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// Stats is a method that handles the "Stats" request.
+// It responds with the number of entries, the memory, the limits and the hits, misses and evictions of the cache
+// store in JSON format and a status code of 200 (OK).
+func (c cache) Stats(ctx *gin.Context) {
+	stats, err := c.cacheStore.Stats(ctx)
+	if helper.IsNotNil(err) {
+		ctx.String(http.StatusInternalServerError, "%s", errors.Details(err).GetMessage())
+		return
+	}
+
+	result := dto.CacheStatsView{
+		Entries:    stats.Entries,
+		MaxEntries: stats.MaxEntries,
+		Hits:       stats.Hits,
+		Misses:     stats.Misses,
+		Evictions:  stats.Evictions,
+	}
+	if helper.IsGreaterThan(stats.Memory, 0) {
+		result.Memory = stats.Memory.String()
+	}
+	if helper.IsGreaterThan(stats.MaxMemory, 0) {
+		result.MaxMemory = stats.MaxMemory.String()
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// selectPatterns returns the endpoint of the query `endpoint`, if informed, and the glob pattern of the query
+// `pattern`, of the query `prefix` or the glob patterns of the endpoint and of its backends, in this order. If the
+// endpoint is not found, or none of them are informed, it responds with the error and returns false.
+func (c cache) selectPatterns(ctx *gin.Context) (*vo.Endpoint, []string, bool) {
+	var endpointVO *vo.Endpoint
+	if helper.IsNotEmpty(ctx.Query("endpoint")) {
+		endpointVO = c.findEndpoint(ctx)
+		if helper.IsNil(endpointVO) {
+			return nil, nil, false
+		}
+	}
+
+	if helper.IsNotEmpty(ctx.Query("pattern")) {
+		return endpointVO, []string{ctx.Query("pattern")}, true
+	} else if helper.IsNotEmpty(ctx.Query("prefix")) {
+		return endpointVO, []string{vo.CacheKeyPatternByPrefix(ctx.Query("prefix"))}, true
+	} else if helper.IsNotNil(endpointVO) {
+		return endpointVO, []string{endpointVO.CacheKeyPattern(), endpointVO.BackendCacheKeyPattern()}, true
+	}
+	ctx.String(http.StatusBadRequest, "%s", "endpoint, pattern or prefix must be informed")
+	return nil, nil, false
+}
+
+// selectKeys returns the keys matching the selected patterns, see selectPatterns, filtered by the endpoint if
+// informed and sorted. If an error occurs, it responds with the error and returns false.
+func (c cache) selectKeys(ctx *gin.Context) ([]string, bool) {
+	endpointVO, patterns, ok := c.selectPatterns(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	// compilamos o regex do endpoint uma única vez para filtrar as chaves
+	var cacheKeyRegex *regexp.Regexp
+	if helper.IsNotNil(endpointVO) {
+		cacheKeyRegex = endpointVO.CacheKeyRegex()
+	}
+
+	var result []string
+	for _, pattern := range patterns {
+		keys, err := c.cacheStore.Scan(ctx, pattern)
+		if helper.IsNotNil(err) {
+			ctx.String(http.StatusInternalServerError, "%s", errors.Details(err).GetMessage())
+			return nil, false
+		}
+		// filtramos as chaves de outros endpoints que também correspondem ao padrão
+		for _, key := range keys {
+			if helper.IsNil(cacheKeyRegex) || cacheKeyRegex.MatchString(key) {
+				result = append(result, key)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result, true
+}
+
+// entryKey returns the cache key of the endpoint for the request of the query `url`, using EndpointCache.StrategyKey
+// with the method of the endpoint and the headers of the admin request. If the url is not informed or the endpoint is
+// not found, it responds with the error and returns false.
+func (c cache) entryKey(ctx *gin.Context) (string, bool) {
+	if helper.IsEmpty(ctx.Query("url")) {
+		ctx.String(http.StatusBadRequest, "%s", "url must be informed")
+		return "", false
+	}
+	endpointVO := c.findEndpoint(ctx)
+	if helper.IsNil(endpointVO) {
+		return "", false
+	}
+
+	// reconstruímos a requisição do endpoint para obter a chave como o middleware de cache
+	httpRequest, err := http.NewRequestWithContext(ctx, endpointVO.Method(), ctx.Query("url"), nil)
+	if helper.IsNotNil(err) {
+		ctx.String(http.StatusBadRequest, "%s", errors.Details(err).GetMessage())
+		return "", false
+	}
+	httpRequest.Header = ctx.Request.Header.Clone()
+
+	return endpointVO.Cache().StrategyKey(vo.NewRequestByHttp(httpRequest)), true
+}
+
+// findEndpoint returns the endpoint with the path of the query `endpoint` and the method of the query `method`,
+// GET if not informed. If the endpoint is not found, it responds with the status code 404 (Not Found) and returns nil.
+func (c cache) findEndpoint(ctx *gin.Context) *vo.Endpoint {
+	path := ctx.Query("endpoint")
+	method := ctx.DefaultQuery("method", http.MethodGet)
+	for _, endpointVO := range c.gopenVO.Endpoints() {
+		if helper.Equals(endpointVO.Path(), path) && helper.Equals(endpointVO.Method(), method) {
+			return &endpointVO
+		}
+	}
+	ctx.String(http.StatusNotFound, "endpoint %s \"%s\" not found", method, path)
+	return nil
+}
@@ -42,6 +42,10 @@ type Static interface {
 	// Settings behaves as a method of the Static interface that handles the GET request to the "/settings" endpoint.
 	// It takes a *gin.Context parameter and performs the necessary actions to return a response.
 	Settings(ctx *gin.Context)
+	// SchemaViolations behaves as a method of the Static interface that handles the GET request to the
+	// "/schema-violations" admin endpoint, authorized by the admin configuration.
+	// It takes a *gin.Context parameter and performs the necessary actions to return a response.
+	SchemaViolations(ctx *gin.Context)
 }
 
 // NewStatic is a function that creates a new instance of the Static interface.
@@ -78,3 +82,10 @@ func (s static) Version(ctx *gin.Context) {
 func (s static) Settings(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, mapper.BuildSettingViewDTO(s.gopenVO))
 }
+
+// SchemaViolations is a method that handles the "SchemaViolations" request.
+// It responds with the number of backend responses that didn't match the response schema of each backend configured
+// with response-schema, in JSON format and a status code of 200 (OK).
+func (s static) SchemaViolations(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, mapper.BuildSchemaViolationsViewDTO(s.gopenVO))
+}
@@ -62,6 +62,7 @@ type gopen struct {
 	limiterMiddleware      middleware.Limiter
 	cacheMiddleware        middleware.Cache
 	staticController       controller.Static
+	cacheController        controller.Cache
 	endpointController     controller.Endpoint
 }
 
@@ -90,6 +91,7 @@ func NewGopen(
 	limiterMiddleware middleware.Limiter,
 	cacheMiddleware middleware.Cache,
 	staticController controller.Static,
+	cacheController controller.Cache,
 	endpointController controller.Endpoint,
 ) Gopen {
 	return gopen{
@@ -101,6 +103,7 @@ func NewGopen(
 		cacheMiddleware:        cacheMiddleware,
 		securityCorsMiddleware: securityCorsMiddleware,
 		staticController:       staticController,
+		cacheController:        cacheController,
 		endpointController:     endpointController,
 	}
 }
@@ -123,6 +126,11 @@ func (g gopen) ListerAndServer() {
 	// configuramos rotas estáticas
 	g.buildStaticRoutes(engine)
 
+	// configuramos as rotas de administração, caso configuradas
+	if helper.IsNotNil(g.gopenVO.Admin()) {
+		g.buildAdminRoutes(engine)
+	}
+
 	printInfoLog("Starting to read endpoints to register routes...")
 	// iteramos os endpoints para cadastrar as rotas
 	for _, endpointVO := range g.gopenVO.Endpoints() {
@@ -203,6 +211,48 @@ func (g gopen) buildStaticRoutes(engine *gin.Engine) {
 	printInfoLogf(formatLog, settingsMethod, settingsPath)
 }
 
+// buildAdminRoutes is a method of the gopen type that configures the admin routes for the Gin engine, only called if
+// the admin is configured. Every route is authorized by gopen.cacheController.Authorize first.
+// It takes an engine parameter of type *gin.Engine and configures the following routes:
+// - "/cache/keys" with the HTTP method "GET" that maps to gopen.cacheController.Keys
+// - "/cache/keys" with the HTTP method "DELETE" that maps to gopen.cacheController.PurgeKeys
+// - "/cache/entry" with the HTTP method "GET" that maps to gopen.cacheController.Entry
+// - "/cache/entry" with the HTTP method "DELETE" that maps to gopen.cacheController.PurgeEntry
+// - "/cache/stats" with the HTTP method "GET" that maps to gopen.cacheController.Stats
+// - "/schema-violations" with the HTTP method "GET" that maps to gopen.staticController.SchemaViolations, as the
+// samples of the violations can contain fragments of the request and response payloads
+func (g gopen) buildAdminRoutes(engine *gin.Engine) {
+	// imprimimos o log cmd
+	printInfoLog("Configuring admin routes...")
+
+	// format
+	formatLog := "registered route %s --> \"%s\""
+
+	// cache keys
+	cacheKeysPath := "/cache/keys"
+	engine.Handle(http.MethodGet, cacheKeysPath, g.cacheController.Authorize, g.cacheController.Keys)
+	printInfoLogf(formatLog, http.MethodGet, cacheKeysPath)
+	engine.Handle(http.MethodDelete, cacheKeysPath, g.cacheController.Authorize, g.cacheController.PurgeKeys)
+	printInfoLogf(formatLog, http.MethodDelete, cacheKeysPath)
+
+	// cache entry
+	cacheEntryPath := "/cache/entry"
+	engine.Handle(http.MethodGet, cacheEntryPath, g.cacheController.Authorize, g.cacheController.Entry)
+	printInfoLogf(formatLog, http.MethodGet, cacheEntryPath)
+	engine.Handle(http.MethodDelete, cacheEntryPath, g.cacheController.Authorize, g.cacheController.PurgeEntry)
+	printInfoLogf(formatLog, http.MethodDelete, cacheEntryPath)
+
+	// cache stats
+	cacheStatsPath := "/cache/stats"
+	engine.Handle(http.MethodGet, cacheStatsPath, g.cacheController.Authorize, g.cacheController.Stats)
+	printInfoLogf(formatLog, http.MethodGet, cacheStatsPath)
+
+	// backend response schema violations
+	schemaViolationsPath := "/schema-violations"
+	engine.Handle(http.MethodGet, schemaViolationsPath, g.cacheController.Authorize, g.staticController.SchemaViolations)
+	printInfoLogf(formatLog, http.MethodGet, schemaViolationsPath)
+}
+
 // buildEndpointHandles is a method of the gopen type that builds a list of middleware handlers for a given endpoint.
 // It takes an endpointVO of type vo.Endpoint as a parameter and returns a slice of api.HandlerFunc.
 // Each middleware handler is configured based on specific middleware instances defined in the gopen type.
@@ -215,7 +265,8 @@ func (g gopen) buildStaticRoutes(engine *gin.Engine) {
 // 5. limiterHandler: Used to handle limiter requests. The limiter vo is determined based on both the endpointVO and the
 // gopenVO configurations.
 // 6. cacheHandler: Used to handle cache requests. The cache duration, cache strategy headers, and allow cache control
-// configurations are determined based on both the endpointVO and gopenVO
+// configurations are determined based on both the endpointVO and gopenVO. The endpoint handler is also passed to it,
+// to revalidate the stale cache responses outside the request.
 func (g gopen) buildEndpointHandles(endpointVO vo.Endpoint) []api.HandlerFunc {
 	// configuramos o handler do log como o middleware
 	logHandler := g.logMiddleware.Do
@@ -227,10 +278,10 @@ func (g gopen) buildEndpointHandles(endpointVO vo.Endpoint) []api.HandlerFunc {
 	timeoutHandler := g.timeoutMiddleware.Do(endpointVO.Timeout())
 	// configuramos o handler do limiter do endpoint como o middleware
 	limiterHandler := g.buildLimiterMiddlewareHandler(endpointVO)
-	// configuramos o handler de cache do endpoint como o middleware
-	cacheHandler := g.cacheMiddleware.Do(endpointVO.Cache())
 	// configuramos o handler do endpoint como controlador
 	endpointHandler := g.endpointController.Execute
+	// configuramos o handler de cache do endpoint como o middleware
+	cacheHandler := g.cacheMiddleware.Do(endpointVO.Cache(), endpointHandler)
 	// montamos a lista de manipuladores
 	return []api.HandlerFunc{
 		traceHandler,
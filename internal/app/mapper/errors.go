@@ -18,18 +18,15 @@ package mapper
 
 import "github.com/GabrielHCataldo/go-errors/errors"
 
-// MsgErrCacheNotFound is a string variable that holds the message "cache not found".
-var MsgErrCacheNotFound = "cache not found"
+// MsgErrCacheNotFound is a string constant that holds the message "cache not found".
+const MsgErrCacheNotFound = "cache not found"
 
-// ErrCacheNotFound is an error variable representing the "cache not found" error.
+// ErrCacheNotFound is the immutable sentinel error representing the "cache not found" error, it is never
+// reassigned, the errors returned by NewErrCacheNotFound are compared against it using errors.Is.
 var ErrCacheNotFound = errors.New(MsgErrCacheNotFound)
 
-// NewErrCacheNotFound creates a new error of type "ErrCacheNotFound".
-// It sets the value of the global variable "ErrCacheNotFound" to the error
-// created using "errors.NewSkipCaller" function with skip caller value 2 and
-// the message "cache not found" stored in the variable "MsgErrCacheNotFound".
-// It returns the error "ErrCacheNotFound".
+// NewErrCacheNotFound creates a new "cache not found" error with the caller, comparable to ErrCacheNotFound using
+// errors.Is. A new error is returned on each call, so it is safe to be called by concurrent goroutines.
 func NewErrCacheNotFound() error {
-	ErrCacheNotFound = errors.NewSkipCaller(2, MsgErrCacheNotFound)
-	return ErrCacheNotFound
+	return errors.NewSkipCaller(2, MsgErrCacheNotFound)
 }
@@ -17,10 +17,12 @@
 package mapper
 
 import (
+	"fmt"
 	"github.com/GabrielHCataldo/go-helper/helper"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/vo"
 	"os"
+	"sort"
 )
 
 // BuildSettingViewDTO builds a `SettingView` DTO object using the provided `Gopen` object as input.
@@ -39,6 +41,48 @@ func BuildSettingViewDTO(gopenVO *vo.Gopen) dto.SettingView {
 	}
 }
 
+// BuildSchemaViolationsViewDTO builds a slice of `SchemaViolationsView` DTO objects using the provided `Gopen` object
+// as input, one for each middleware and endpoint backend with response schema, the middlewares sorted by key first.
+func BuildSchemaViolationsViewDTO(gopenVO *vo.Gopen) []dto.SchemaViolationsView {
+	result := []dto.SchemaViolationsView{}
+
+	// ordenamos as chaves dos middlewares para ter sempre a mesma ordem
+	middlewares := gopenVO.Middlewares()
+	keys := make([]string, 0, len(middlewares))
+	for key := range middlewares {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		middlewareBackend := middlewares[key]
+		if helper.IsNotNil(middlewareBackend.ResponseSchema()) {
+			result = append(result, BuildSchemaViolationsViewDTOFromVO("middlewares."+key, middlewareBackend))
+		}
+	}
+	for _, endpointVO := range gopenVO.PureEndpoints() {
+		for index, backendVO := range endpointVO.Backends() {
+			if helper.IsNotNil(backendVO.ResponseSchema()) {
+				location := fmt.Sprintf("%s \"%s\" backends[%v]", endpointVO.Method(), endpointVO.Path(), index)
+				result = append(result, BuildSchemaViolationsViewDTOFromVO(location, backendVO))
+			}
+		}
+	}
+	return result
+}
+
+// BuildSchemaViolationsViewDTOFromVO builds a `SchemaViolationsView` DTO object using the provided location and
+// `Backend` object as input.
+func BuildSchemaViolationsViewDTOFromVO(location string, backendVO vo.Backend) dto.SchemaViolationsView {
+	return dto.SchemaViolationsView{
+		Location:   location,
+		Name:       backendVO.Name(),
+		Path:       backendVO.Path(),
+		Mode:       backendVO.ResponseSchema().Mode(),
+		Violations: backendVO.ResponseSchema().ViolationsCount(),
+	}
+}
+
 // BuildGopenDTO builds a `Gopen` DTO object using the provided `Gopen` object as input.
 // It retrieves various properties from the `Gopen` object and sets them on the `Gopen` object.
 func BuildGopenDTO(gopenVO *vo.Gopen) dto.Gopen {
@@ -51,6 +95,8 @@ func BuildGopenDTO(gopenVO *vo.Gopen) dto.Gopen {
 		Cache:        BuildCacheDTOFromVO(gopenVO.Cache()),
 		SecurityCors: BuildSecurityCorsDTOFromVO(gopenVO.SecurityCors()),
 		Middlewares:  BuildMiddlewaresDTOFromVO(gopenVO.Middlewares()),
+		Redaction:    BuildRedactionDTOFromVO(gopenVO.Redaction()),
+		ErrorFormat:  BuildErrorFormatDTOFromVO(gopenVO.ErrorFormat()),
 		Endpoints:    BuildEndpointsDTOFromVOs(gopenVO.PureEndpoints()),
 	}
 }
@@ -68,6 +114,9 @@ func BuildGopenDTOFromCMD(gopenVO *vo.Gopen, storeDTO *dto.Store) dto.Gopen {
 		Cache:        BuildCacheDTOFromVO(gopenVO.Cache()),
 		SecurityCors: BuildSecurityCorsDTOFromVO(gopenVO.SecurityCors()),
 		Middlewares:  BuildMiddlewaresDTOFromVO(gopenVO.Middlewares()),
+		Redaction:    BuildRedactionDTOFromVO(gopenVO.Redaction()),
+		ErrorFormat:  BuildErrorFormatDTOFromVO(gopenVO.ErrorFormat()),
+		Admin:        BuildAdminDTOFromVO(gopenVO.Admin()),
 		Endpoints:    BuildEndpointsDTOFromVOs(gopenVO.PureEndpoints()),
 	}
 }
@@ -133,11 +182,16 @@ func BuildCacheDTOFromVO(cacheVO *vo.Cache) *dto.Cache {
 		return nil
 	}
 	return &dto.Cache{
-		Duration:          cacheVO.Duration().String(),
-		StrategyHeaders:   cacheVO.StrategyHeaders(),
-		OnlyIfStatusCodes: cacheVO.OnlyIfStatusCodes(),
-		OnlyIfMethods:     cacheVO.OnlyIfMethods(),
-		AllowCacheControl: cacheVO.AllowCacheControl(),
+		Duration:             cacheVO.Duration().String(),
+		StrategyHeaders:      cacheVO.StrategyHeaders(),
+		StrategyKey:          cacheVO.StrategyKeyExpressions(),
+		OnlyIfStatusCodes:    cacheVO.OnlyIfStatusCodes(),
+		OnlyIfMethods:        cacheVO.OnlyIfMethods(),
+		AllowCacheControl:    cacheVO.AllowCacheControl(),
+		OriginTTL:            cacheVO.OriginTTL(),
+		StaleWhileRevalidate: cacheVO.StaleWhileRevalidateStr(),
+		StaleIfError:         cacheVO.StaleIfErrorStr(),
+		CoalesceLock:         cacheVO.CoalesceLockStr(),
 	}
 }
 
@@ -148,12 +202,17 @@ func BuildEndpointCacheDTOFromVO(endpointCacheVO *vo.EndpointCache) *dto.Endpoin
 		return nil
 	}
 	return &dto.EndpointCache{
-		Enabled:           endpointCacheVO.Enabled(),
-		IgnoreQuery:       endpointCacheVO.IgnoreQuery(),
-		Duration:          endpointCacheVO.DurationStr(),
-		StrategyHeaders:   endpointCacheVO.StrategyHeaders(),
-		OnlyIfStatusCodes: endpointCacheVO.OnlyIfStatusCodes(),
-		AllowCacheControl: endpointCacheVO.AllowCacheControl(),
+		Enabled:              endpointCacheVO.Enabled(),
+		IgnoreQuery:          endpointCacheVO.IgnoreQuery(),
+		Duration:             endpointCacheVO.DurationStr(),
+		StrategyHeaders:      endpointCacheVO.StrategyHeaders(),
+		StrategyKey:          endpointCacheVO.StrategyKeyExpressions(),
+		OnlyIfStatusCodes:    endpointCacheVO.OnlyIfStatusCodes(),
+		AllowCacheControl:    endpointCacheVO.AllowCacheControl(),
+		OriginTTL:            endpointCacheVO.OriginTTL(),
+		StaleWhileRevalidate: endpointCacheVO.StaleWhileRevalidateStr(),
+		StaleIfError:         endpointCacheVO.StaleIfErrorStr(),
+		CoalesceLock:         endpointCacheVO.CoalesceLockStr(),
 	}
 }
 
@@ -206,9 +265,17 @@ func BuildEndpointDTOFromVO(endpointVO vo.Endpoint) dto.Endpoint {
 		Cache:              BuildEndpointCacheDTOFromVO(endpointVO.Cache()),
 		ResponseEncode:     endpointVO.ResponseEncode(),
 		AggregateResponses: endpointVO.AggregateResponses(),
+		Aggregation:        BuildAggregationDTOFromVO(endpointVO.Aggregation()),
+		ErrorFormat:        BuildErrorFormatDTOFromVO(endpointVO.ErrorFormat()),
 		AbortIfStatusCodes: endpointVO.AbortIfStatusCodes(),
+		StatusCodeStrategy: endpointVO.StatusCodeStrategy(),
+		StatusCodeMapping:  endpointVO.StatusCodeMapping(),
 		Beforeware:         endpointVO.Beforeware(),
 		Afterware:          endpointVO.Afterware(),
+		Modifiers:          BuildBackendModifiersDTOFromVO(endpointVO.Modifiers()),
+		Projection:         BuildProjectionDTOFromVO(endpointVO.Projection()),
+		Redaction:          endpointVO.Redaction(),
+		RequestSchema:      BuildRequestSchemaDTOFromVO(endpointVO.RequestSchema()),
 		Backends:           BuildBackendsDTOFromVO(endpointVO.Backends()),
 	}
 }
@@ -234,10 +301,31 @@ func BuildBackendDTOFromVO(backendVO vo.Backend) dto.Backend {
 		ForwardHeaders: backendVO.ForwardHeaders(),
 		ForwardQueries: backendVO.ForwardQueries(),
 		Modifiers:      BuildBackendModifiersDTOFromVO(backendVO.BackendModifiers()),
+		Projection:     BuildProjectionDTOFromVO(backendVO.Projection()),
+		Transform:      BuildTransformsDTOFromVO(backendVO.Transforms()),
+		ResponseSchema: BuildResponseSchemaDTOFromVO(backendVO.ResponseSchema()),
+		ForEach:        BuildForEachDTOFromVO(backendVO.ForEach()),
+		Paginate:       BuildPaginateDTOFromVO(backendVO.Paginate()),
+		Cache:          BuildBackendCacheDTOFromVO(backendVO.Cache()),
 		ExtraConfig:    BuildBackendExtraConfigDTOFromVO(backendVO.ExtraConfig()),
 	}
 }
 
+// BuildBackendCacheDTOFromVO builds a `BackendCache` DTO object using the provided `BackendCache` object as input.
+// If the `BackendCache` object is nil, it returns nil.
+func BuildBackendCacheDTOFromVO(backendCacheVO *vo.BackendCache) *dto.BackendCache {
+	if helper.IsNil(backendCacheVO) {
+		return nil
+	}
+	return &dto.BackendCache{
+		Enabled:           backendCacheVO.Enabled(),
+		Duration:          backendCacheVO.DurationStr(),
+		StrategyHeaders:   backendCacheVO.StrategyHeaders(),
+		StrategyKey:       backendCacheVO.StrategyKeyExpressions(),
+		OnlyIfStatusCodes: backendCacheVO.OnlyIfStatusCodes(),
+	}
+}
+
 // BuildBackendExtraConfigDTOFromVO builds a `BackendExtraConfig` DTO object using the provided `BackendExtraConfig` object as input.
 // It checks if the input object is nil or if any of the flag properties are false, and returns nil in that case.
 // Otherwise, it sets the properties of the DTO object using the corresponding properties from the input object.
@@ -269,6 +357,157 @@ func BuildBackendModifiersDTOFromVO(backendModifiersVO *vo.BackendModifiers) *dt
 	}
 }
 
+// BuildProjectionDTOFromVO builds a `Projection` DTO object using the provided `Projection` object as input.
+// If the `Projection` object is nil, it returns nil.
+func BuildProjectionDTOFromVO(projectionVO *vo.Projection) *dto.Projection {
+	if helper.IsNil(projectionVO) {
+		return nil
+	}
+	return &dto.Projection{
+		Allow: projectionVO.Allow(),
+		Deny:  projectionVO.Deny(),
+	}
+}
+
+// BuildAggregationDTOFromVO builds an `Aggregation` DTO object using the provided `Aggregation` object as input.
+// If the `Aggregation` object is nil, it returns nil.
+func BuildAggregationDTOFromVO(aggregationVO *vo.Aggregation) *dto.Aggregation {
+	if helper.IsNil(aggregationVO) {
+		return nil
+	}
+	return &dto.Aggregation{
+		Merge:     aggregationVO.Merge(),
+		Conflict:  aggregationVO.Conflict(),
+		Arrays:    aggregationVO.Arrays(),
+		DropNulls: aggregationVO.DropNulls(),
+	}
+}
+
+// BuildRequestSchemaDTOFromVO builds a `RequestSchema` DTO object using the provided `RequestSchema` object as input.
+// If the `RequestSchema` object is nil, it returns nil.
+func BuildRequestSchemaDTOFromVO(requestSchemaVO *vo.RequestSchema) *dto.RequestSchema {
+	if helper.IsNil(requestSchemaVO) {
+		return nil
+	}
+	return &dto.RequestSchema{
+		File:       requestSchemaVO.File(),
+		Schema:     requestSchemaVO.Schema(),
+		StatusCode: requestSchemaVO.StatusCode(),
+	}
+}
+
+// BuildAdminDTOFromVO builds an `Admin` DTO object using the provided `Admin` object as input.
+// If the `Admin` object is nil, it returns nil.
+func BuildAdminDTOFromVO(adminVO *vo.Admin) *dto.Admin {
+	if helper.IsNil(adminVO) {
+		return nil
+	}
+	return &dto.Admin{
+		Token: adminVO.Token(),
+	}
+}
+
+// BuildErrorFormatDTOFromVO builds an `ErrorFormat` DTO object using the provided `ErrorFormat` object as input.
+// If the `ErrorFormat` object is nil, it returns nil.
+func BuildErrorFormatDTOFromVO(errorFormatVO *vo.ErrorFormat) *dto.ErrorFormat {
+	if helper.IsNil(errorFormatVO) {
+		return nil
+	}
+	return &dto.ErrorFormat{
+		Format:         errorFormatVO.Format(),
+		Type:           errorFormatVO.Type(),
+		HideDetails:    errorFormatVO.HideDetails(),
+		SourceLocation: errorFormatVO.SourceLocation(),
+		Template:       errorFormatVO.Template(),
+	}
+}
+
+// BuildForEachDTOFromVO builds a `ForEach` DTO object using the provided `ForEach` object as input.
+// If the `ForEach` object is nil, it returns nil.
+func BuildForEachDTOFromVO(forEachVO *vo.ForEach) *dto.ForEach {
+	if helper.IsNil(forEachVO) {
+		return nil
+	}
+	return &dto.ForEach{
+		Backend:     forEachVO.Backend(),
+		Path:        forEachVO.Path(),
+		Params:      forEachVO.Params(),
+		Query:       forEachVO.Query(),
+		Concurrency: forEachVO.Concurrency(),
+		Key:         forEachVO.Key(),
+	}
+}
+
+// BuildPaginateDTOFromVO builds a `Paginate` DTO object using the provided `Paginate` object as input.
+// If the `Paginate` object is nil, it returns nil.
+func BuildPaginateDTOFromVO(paginateVO *vo.Paginate) *dto.Paginate {
+	if helper.IsNil(paginateVO) {
+		return nil
+	}
+	return &dto.Paginate{
+		NextPath:   paginateVO.NextPath(),
+		NextQuery:  paginateVO.NextQuery(),
+		LinkHeader: paginateVO.LinkHeader(),
+		ItemsPath:  paginateVO.ItemsPath(),
+		MaxPages:   paginateVO.MaxPages(),
+		MaxItems:   paginateVO.MaxItems(),
+	}
+}
+
+// BuildResponseSchemaDTOFromVO builds a `ResponseSchema` DTO object using the provided `ResponseSchema` object as
+// input. If the `ResponseSchema` object is nil, it returns nil.
+func BuildResponseSchemaDTOFromVO(responseSchemaVO *vo.ResponseSchema) *dto.ResponseSchema {
+	if helper.IsNil(responseSchemaVO) {
+		return nil
+	}
+	return &dto.ResponseSchema{
+		File:   responseSchemaVO.File(),
+		Schema: responseSchemaVO.Schema(),
+		Mode:   responseSchemaVO.Mode(),
+	}
+}
+
+// BuildRedactionDTOFromVO builds a `Redaction` DTO object using the provided `Redaction` object as input.
+// If the `Redaction` object is nil, it returns nil.
+func BuildRedactionDTOFromVO(redactionVO *vo.Redaction) *dto.Redaction {
+	if helper.IsNil(redactionVO) {
+		return nil
+	}
+	rules := map[string]dto.RedactionRule{}
+	for name, ruleVO := range redactionVO.Rules() {
+		rules[name] = dto.RedactionRule{
+			Path:     ruleVO.Path(),
+			Regex:    ruleVO.Regex(),
+			Strategy: ruleVO.Strategy(),
+			Reveal:   ruleVO.Reveal(),
+		}
+	}
+	return &dto.Redaction{
+		Rules: rules,
+		Logs:  redactionVO.Logs(),
+	}
+}
+
+// BuildTransformsDTOFromVO builds a slice of `Transform` DTO objects using the provided slice of `Transform` objects
+// as input.
+func BuildTransformsDTOFromVO(transforms []vo.Transform) (result []dto.Transform) {
+	for _, transformVO := range transforms {
+		result = append(result, dto.Transform{
+			Type:       transformVO.Type(),
+			Path:       transformVO.Path(),
+			Fields:     transformVO.Fields(),
+			KeepOthers: transformVO.KeepOthers(),
+			If:         transformVO.If(),
+			Key:        transformVO.Key(),
+			Desc:       transformVO.Desc(),
+			Separator:  transformVO.Separator(),
+			Offset:     transformVO.Offset(),
+			Limit:      transformVO.Limit(),
+		})
+	}
+	return result
+}
+
 // BuildModifiersDTOFromVO builds a slice of `Modifier` DTO objects using the provided slice of `Modifier` objects as input.
 // It iterates over each `Modifier` in the `modifiers` slice and calls `BuildModifierDTO` to create the DTO object.
 // The DTO object is then appended to the `result` slice and finally returned.
@@ -294,5 +533,7 @@ func BuildModifierDTOFromVO(modifierVO *vo.Modifier) *dto.Modifier {
 		Propagate: modifierVO.Propagate(),
 		Key:       modifierVO.Key(),
 		Value:     modifierVO.Value(),
+		If:        modifierVO.If(),
+		OnError:   modifierVO.OnError(),
 	}
 }
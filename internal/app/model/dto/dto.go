@@ -65,26 +65,102 @@ type Gopen struct {
 	// ForwardQueries, Modifiers, and ExtraConfig, which specify the behavior
 	// and settings of the middleware.
 	Middlewares map[string]Backend `json:"middlewares,omitempty"`
+	// Redaction represents the named rules to mask sensitive data, which can be attached to the endpoints responses
+	// and applied globally to the logged request and response bodies.
+	Redaction *Redaction `json:"redaction,omitempty"`
+	// ErrorFormat represents the format of the error responses generated by the gateway, used by the endpoints that
+	// don't configure their own.
+	ErrorFormat *ErrorFormat `json:"error-format,omitempty"`
+	// Admin represents the configuration of the admin routes, like the cache purge and inspection routes.
+	Admin *Admin `json:"admin,omitempty"`
 	// Endpoints is a field in the Gopen struct that represents a slice of Endpoint objects.
 	// Each Endpoint object defines a specific API endpoint with its corresponding settings such as path, method,
 	// timeout, limiter, cache, etc.
 	Endpoints []Endpoint `json:"endpoints,omitempty"`
 }
 
+// Admin represents the configuration of the admin routes of the Gopen application.
+type Admin struct {
+	// Comment is a field to add a comment to the admin configuration.
+	Comment string `json:"@comment,omitempty"`
+	// Token represents the bearer token that authorizes the requests to the admin routes.
+	Token string `json:"token,omitempty"`
+}
+
 // Store represents the store configuration for the Gopen application.
-// It contains the Redis configuration.
+// It contains the Redis configuration, or the Memory configuration if the responses are cached in memory.
 type Store struct {
 	// Redis represents the Redis configuration for the Gopen application.
 	Redis *Redis `json:"redis,omitempty"`
+	// Memory represents the limits of the in-memory cache store, used if Redis is not configured.
+	Memory *Memory `json:"memory,omitempty"`
 }
 
-// Redis represents the configuration for connecting to a Redis server.
+// Memory represents the configuration of the in-memory cache store.
+// When one of the limits is exceeded, the least recently used entries are evicted.
 // It contains the following fields:
-// - Address: a string representing the address of the Redis server. It defaults to an empty string.
-// - Password: a string representing the password to authenticate with the Redis server. It defaults to an empty string.
+// - MaxEntries: an integer representing the maximum number of entries. It is unlimited if 0.
+// - MaxMemory: a byte unit string, like "64MB", representing the maximum size of the serialized entries. It is
+// unlimited if empty.
+type Memory struct {
+	MaxEntries int    `json:"max-entries,omitempty"`
+	MaxMemory  string `json:"max-memory,omitempty"`
+}
+
+// Redis represents the configuration for connecting to a Redis server, a Redis Sentinel or a Redis Cluster.
 type Redis struct {
-	Address  string `json:"address,omitempty"`
+	// Mode represents how the Redis servers are connected, enum.RedisModeStandalone by default.
+	Mode enum.RedisMode `json:"mode,omitempty"`
+	// Address represents the address of the Redis server, or the first address of the Sentinel or Cluster nodes.
+	Address string `json:"address,omitempty"`
+	// Addresses represents the other addresses of the Sentinel or Cluster nodes.
+	Addresses []string `json:"addresses,omitempty"`
+	// MasterName represents the name of the master monitored by the Sentinel nodes, required on the Sentinel mode.
+	MasterName string `json:"master-name,omitempty"`
+	// DB represents the database selected after connecting, not supported on the Cluster mode.
+	DB int `json:"db,omitempty"`
+	// Username represents the username to authenticate with the Redis server, using the ACL system.
+	Username string `json:"username,omitempty"`
+	// Password represents the password to authenticate with the Redis server.
 	Password string `json:"password,omitempty"`
+	// SentinelUsername represents the username to authenticate with the Sentinel nodes.
+	SentinelUsername string `json:"sentinel-username,omitempty"`
+	// SentinelPassword represents the password to authenticate with the Sentinel nodes.
+	SentinelPassword string `json:"sentinel-password,omitempty"`
+	// TLS represents the TLS configuration of the connections, which are not encrypted if nil or disabled.
+	TLS *RedisTLS `json:"tls,omitempty"`
+	// KeyPrefix represents the prefix added to every key, so one Redis can be shared between environments.
+	KeyPrefix string `json:"key-prefix,omitempty"`
+	// PoolSize represents the maximum number of connections of the pool, per node on the Cluster mode.
+	PoolSize int `json:"pool-size,omitempty"`
+	// MinIdleConns represents the minimum number of idle connections of the pool.
+	MinIdleConns int `json:"min-idle-conns,omitempty"`
+	// PoolTimeout represents the duration to wait for a connection of the pool when all of them are busy.
+	PoolTimeout string `json:"pool-timeout,omitempty"`
+	// DialTimeout represents the duration to establish new connections.
+	DialTimeout string `json:"dial-timeout,omitempty"`
+	// ReadTimeout represents the duration to wait for the replies of the commands.
+	ReadTimeout string `json:"read-timeout,omitempty"`
+	// WriteTimeout represents the duration to wait for the writes of the commands.
+	WriteTimeout string `json:"write-timeout,omitempty"`
+}
+
+// RedisTLS represents the TLS configuration of the connections to the Redis servers.
+type RedisTLS struct {
+	// Enabled represents a boolean indicating whether the connections are encrypted with TLS.
+	Enabled bool `json:"enabled"`
+	// ServerName represents the name used to verify the certificate of the servers, the host of the address by
+	// default.
+	ServerName string `json:"server-name,omitempty"`
+	// CaFile represents the path of the PEM file with the certificate authorities that verify the servers, the
+	// system ones by default.
+	CaFile string `json:"ca-file,omitempty"`
+	// CertFile represents the path of the PEM file with the client certificate, used with the KeyFile.
+	CertFile string `json:"cert-file,omitempty"`
+	// KeyFile represents the path of the PEM file with the key of the client certificate.
+	KeyFile string `json:"key-file,omitempty"`
+	// InsecureSkipVerify represents a boolean indicating whether the certificate of the servers is not verified.
+	InsecureSkipVerify bool `json:"insecure-skip-verify,omitempty"`
 }
 
 // Cache represents the cache configuration in the Gopen struct.
@@ -95,6 +171,11 @@ type Cache struct {
 	Duration string `json:"duration,omitempty"`
 	// StrategyHeaders represents a slice of strings that contains the headers used to determine the cache strategy key.
 	StrategyHeaders []string `json:"strategy-headers,omitempty"`
+	// StrategyKey represents the eval expressions whose values build the cache key, for example
+	// "#request.query.page[0]", "#request.params.id", "#request.jwt.sub" or "#request.cookie.session". The values of
+	// the strategy headers, if informed, are added after them. The jwt claims are not verified, so the key also carries
+	// the hash of the whole bearer token.
+	StrategyKey []string `json:"strategy-key,omitempty"`
 	// OnlyIfStatusCodes represents a slice of integers representing the HTTP status codes
 	// for which the cache should be used. Default is an empty slice. If not provided,
 	// the default value is 2xx success HTTP status codes.
@@ -106,6 +187,19 @@ type Cache struct {
 	// AllowCacheControl represents a pointer to a boolean indicating whether the cache should
 	// honor the Cache-Control header. It defaults to nil. If not provided, the default value is false.
 	AllowCacheControl *bool `json:"allow-cache-control,omitempty"`
+	// OriginTTL represents a pointer to a boolean indicating whether the cache duration is derived from the
+	// Cache-Control max-age/s-maxage or the Expires headers of the response, using the duration if they are absent.
+	// It defaults to nil. If not provided, the default value is false.
+	OriginTTL *bool `json:"origin-ttl,omitempty"`
+	// StaleWhileRevalidate represents the duration, after the cache expires, in which the stale response is served
+	// immediately while it is revalidated.
+	StaleWhileRevalidate string `json:"stale-while-revalidate,omitempty"`
+	// StaleIfError represents the duration, after the cache expires, in which the stale response is served if the
+	// request fails.
+	StaleIfError string `json:"stale-if-error,omitempty"`
+	// CoalesceLock represents the duration of the lock key that coordinates the cache misses across the gateway
+	// instances sharing the cache store, so only one of them calls the backends. It is not used if empty.
+	CoalesceLock string `json:"coalesce-lock,omitempty"`
 }
 
 // EndpointCache represents the cache configuration for an endpoint.
@@ -118,10 +212,27 @@ type EndpointCache struct {
 	Duration string `json:"duration,omitempty"`
 	// StrategyHeaders represents a slice of strings for strategy headers
 	StrategyHeaders []string `json:"strategy-headers,omitempty"`
+	// StrategyKey represents the eval expressions whose values build the cache key, for example
+	// "#request.query.page[0]", "#request.params.id", "#request.jwt.sub" or "#request.cookie.session". The values of
+	// the strategy headers, if informed, are added after them. The jwt claims are not verified, so the key also carries
+	// the hash of the whole bearer token.
+	StrategyKey []string `json:"strategy-key,omitempty"`
 	// OnlyIfStatusCodes represents the status codes that the cache should be applied to.
 	OnlyIfStatusCodes []int `json:"only-if-status-codes,omitempty"`
 	// AllowCacheControl represents a boolean value indicating whether the cache control header is allowed for the endpoint cache.
 	AllowCacheControl *bool `json:"allow-cache-control,omitempty"`
+	// OriginTTL represents a boolean value indicating whether the cache duration is derived from the response headers
+	// for the endpoint cache.
+	OriginTTL *bool `json:"origin-ttl,omitempty"`
+	// StaleWhileRevalidate represents the duration, after the cache expires, in which the stale response is served
+	// immediately while it is revalidated.
+	StaleWhileRevalidate string `json:"stale-while-revalidate,omitempty"`
+	// StaleIfError represents the duration, after the cache expires, in which the stale response is served if the
+	// request fails.
+	StaleIfError string `json:"stale-if-error,omitempty"`
+	// CoalesceLock represents the duration of the lock key that coordinates the cache misses across the gateway
+	// instances sharing the cache store, so only one of them calls the backends. It is not used if empty.
+	CoalesceLock string `json:"coalesce-lock,omitempty"`
 }
 
 // Limiter represents the configuration for rate limiting in the Gopen application.
@@ -197,9 +308,21 @@ type Endpoint struct {
 	// AggregateResponses represents a boolean indicating whether the API endpoint should aggregate responses
 	// from multiple backends.
 	AggregateResponses bool `json:"aggregate-responses,omitempty"`
+	// Aggregation represents the options of the aggregation of the backend responses, used when AggregateResponses
+	// is true. If not provided, the keys present on more than one backend response are collected into an array.
+	Aggregation *Aggregation `json:"aggregation,omitempty"`
+	// ErrorFormat represents the format of the error responses generated by the gateway on this endpoint. If not
+	// provided, the global Gopen.ErrorFormat is used.
+	ErrorFormat *ErrorFormat `json:"error-format,omitempty"`
 	// AbortIfStatusCodes represents a slice of integers representing the HTTP status codes
 	// for which the API endpoint should abort. It is a field in the Endpoint struct.
 	AbortIfStatusCodes *[]int `json:"abort-if-status-codes,omitempty"`
+	// StatusCodeStrategy represents how the status code is resolved when the endpoint has multiple backend
+	// responses. If not provided, the default is enum.StatusCodeStrategyMostFrequent.
+	StatusCodeStrategy enum.StatusCodeStrategy `json:"status-code-strategy,omitempty"`
+	// StatusCodeMapping represents the mapping of the resolved status code to the status code of the response, the
+	// keys are exact status codes like "404" or classes like "5xx", the exact keys have priority.
+	StatusCodeMapping map[string]int `json:"status-code-mapping,omitempty"`
 	// Beforeware represents a slice of strings containing the names of the beforeware middlewares that should be
 	// applied before processing the API endpoint.
 	Beforeware []string `json:"beforeware,omitempty"`
@@ -211,6 +334,19 @@ type Endpoint struct {
 	// transformation of the response or performing any additional actions.
 	// Afterware can be used for logging, error handling, response modification, etc.
 	Afterware []string `json:"afterware,omitempty"`
+	// Modifiers represent the configuration to modify the request and response of the endpoint itself.
+	// The modifiers with REQUEST context are executed before the first beforeware, and the modifiers with RESPONSE
+	// context are executed on the final aggregated response, just before it is written.
+	Modifiers *BackendModifiers `json:"modifiers,omitempty"`
+	// Projection represents the allow-list or deny-list of fields applied to the body of each backend response of the
+	// endpoint, before the aggregation.
+	Projection *Projection `json:"projection,omitempty"`
+	// Redaction represents the names of the redaction rules, configured in Gopen.Redaction, applied to the final
+	// response body and header of the endpoint.
+	Redaction []string `json:"redaction,omitempty"`
+	// RequestSchema represents the JSON Schema that validates the client request before the modifiers and the
+	// beforeware, if the request doesn't match, no backend is called.
+	RequestSchema *RequestSchema `json:"request-schema,omitempty"`
 	// Backends represents the backend configurations for an API endpoint in the Gopen application.
 	// It is a slice of Backend structs.
 	Backends []Backend `json:"backends,omitempty"`
@@ -243,6 +379,23 @@ type Backend struct {
 	ForwardQueries []string `json:"forward-queries,omitempty"`
 	// Modifiers represent the configuration to modify the request and response of a backend and endpoint in the Gopen application.
 	Modifiers *BackendModifiers `json:"modifiers,omitempty"`
+	// Projection represents the allow-list or deny-list of fields applied to the body of the backend response, after
+	// the modifiers and before the aggregation.
+	Projection *Projection `json:"projection,omitempty"`
+	// Transform represents the pipeline of steps applied in order to the body of the backend response, after the
+	// modifiers and before the projection.
+	Transform []Transform `json:"transform,omitempty"`
+	// ResponseSchema represents the JSON Schema that validates the body of the backend response, before the
+	// modifiers.
+	ResponseSchema *ResponseSchema `json:"response-schema,omitempty"`
+	// ForEach represents the fan-out of the backend, called once per element of an array of a previous backend
+	// response, merging each result back into its element. Only applied to the backends of the endpoint.
+	ForEach *ForEach `json:"for-each,omitempty"`
+	// Paginate represents the following of the next pages of the backend, concatenating the items of the pages into
+	// the body of the backend response.
+	Paginate *Paginate `json:"paginate,omitempty"`
+	// Cache represents the cache of the backend responses, before the response modifiers.
+	Cache *BackendCache `json:"cache,omitempty"`
 	// ExtraConfig represents additional configuration options for a backend in the Gopen application.
 	ExtraConfig *BackendExtraConfig `json:"extra-config,omitempty"`
 }
@@ -268,6 +421,198 @@ type BackendModifiers struct {
 	Body []Modifier `json:"body,omitempty"`
 }
 
+// Redaction represents the redaction configuration of the Gopen application.
+type Redaction struct {
+	// Rules represents the redaction rules by name.
+	Rules map[string]RedactionRule `json:"rules,omitempty"`
+	// Logs represents the names of the rules applied to the logged request and response bodies and headers.
+	Logs []string `json:"logs,omitempty"`
+}
+
+// RedactionRule represents a rule to redact sensitive data in the Gopen application.
+// The Path selects the values of a JSON body, using the same syntax of the Projection, or the header by name, and the
+// Regex selects the parts of the values that match. If only the Regex is informed, it is applied to all string
+// values.
+type RedactionRule struct {
+	Comment string `json:"@comment,omitempty"`
+	// Path represents the gjson path of the values, or the header name, to be redacted.
+	Path string `json:"path,omitempty"`
+	// Regex represents the regular expression of the parts of the values to be redacted.
+	Regex string `json:"regex,omitempty"`
+	// Strategy represents how the value is redacted, which can be MASK, HASH, REMOVE or PARTIAL.
+	Strategy enum.RedactionStrategy `json:"strategy,omitempty"`
+	// Reveal represents the number of last characters revealed by the PARTIAL strategy, the default value is 4.
+	Reveal int `json:"reveal,omitempty"`
+}
+
+// RequestSchema represents the JSON Schema validation of the client request of an endpoint.
+// The schema is applied to a document with the fields "body", "query" and "params" of the request, the query
+// values with a single value are strings, and with multiple values are arrays of strings.
+type RequestSchema struct {
+	// Comment represents a comment about the request schema.
+	Comment string `json:"@comment,omitempty"`
+	// File represents the path of the JSON Schema file, used if Schema is not informed.
+	File string `json:"file,omitempty"`
+	// Schema represents the inline JSON Schema.
+	Schema any `json:"schema,omitempty"`
+	// StatusCode represents the status code of the response when the request doesn't match the schema, 400 or 422.
+	// If not informed, the default is 400.
+	StatusCode int `json:"status-code,omitempty"`
+}
+
+// ErrorFormat represents the format of the error responses generated by the gateway.
+type ErrorFormat struct {
+	// Comment represents a comment about the error format.
+	Comment string `json:"@comment,omitempty"`
+	// Format represents the format of the error body, PROBLEM for the RFC 7807 application/problem+json, or LEGACY
+	// for the previous error body. If not provided, the default is PROBLEM.
+	Format enum.ErrorFormat `json:"format,omitempty"`
+	// Type represents the URI of the "type" member of the problem details. If not provided, the default is
+	// "about:blank".
+	Type string `json:"type,omitempty"`
+	// HideDetails indicates whether the internal details, like the source file, line and modifier, and the detail of
+	// the server errors, are hidden from the clients, recommended on production environments.
+	HideDetails bool `json:"hide-details,omitempty"`
+	// SourceLocation indicates whether the source file and line where the error occurred are included in the
+	// problem details, hidden by default as they expose the internals of the gateway. They are never included if
+	// HideDetails is true.
+	SourceLocation bool `json:"source-location,omitempty"`
+	// Template represents a JSON object used as the error body, where the `{{ expression }}` templates of the string
+	// values are evaluated with the #error and #request eval values.
+	Template map[string]any `json:"template,omitempty"`
+}
+
+// Aggregation represents the options of the aggregation of the backend responses of an endpoint.
+type Aggregation struct {
+	// Comment represents a comment about the aggregation.
+	Comment string `json:"@comment,omitempty"`
+	// Merge represents how the bodies are merged, SHALLOW only merges the root keys and DEEP merges the nested
+	// objects. If not provided, the default is SHALLOW.
+	Merge enum.AggregationMerge `json:"merge,omitempty"`
+	// Conflict represents how a key present on more than one backend response is resolved. If not provided, the
+	// default is COLLECT.
+	Conflict enum.AggregationConflict `json:"conflict,omitempty"`
+	// Arrays represents how two arrays on the same key are merged, REPLACE resolves them by the Conflict policy and
+	// CONCAT concatenates them. If not provided, the default is REPLACE.
+	Arrays enum.AggregationArrays `json:"arrays,omitempty"`
+	// DropNulls indicates whether the null values of the backend responses are dropped before the merge.
+	DropNulls bool `json:"drop-nulls,omitempty"`
+}
+
+// ForEach represents the fan-out of a backend over the elements of an array of a previous backend response.
+type ForEach struct {
+	// Comment represents a comment about the fan-out.
+	Comment string `json:"@comment,omitempty"`
+	// Backend represents the name of the previous backend whose response is iterated. If not provided, the last
+	// backend response is used.
+	Backend string `json:"backend,omitempty"`
+	// Path represents the gjson path of the array on the body of the previous backend response. If not provided, the
+	// body itself is iterated.
+	Path string `json:"path,omitempty"`
+	// Params represents the path params of each call, by name, filled with the value of the gjson path on the element.
+	Params map[string]string `json:"params,omitempty"`
+	// Query represents the query values of each call, by key, filled with the value of the gjson path on the element.
+	Query map[string]string `json:"query,omitempty"`
+	// Concurrency represents the maximum number of calls running at the same time. If not provided, the default is 5.
+	Concurrency int `json:"concurrency,omitempty"`
+	// Key represents the key of the element where the body of the response of each call is merged.
+	Key string `json:"key,omitempty"`
+}
+
+// BackendCache represents the cache configuration of the responses of a backend.
+type BackendCache struct {
+	// Comment represents a comment about the backend cache.
+	Comment string `json:"@comment,omitempty"`
+	// Enabled represents a boolean indicating whether caching is enabled for the backend.
+	Enabled bool `json:"enabled"`
+	// Duration represents the duration for which the backend response is cached.
+	Duration string `json:"duration,omitempty"`
+	// StrategyHeaders represents the headers of the backend request whose values are added to the cache key.
+	StrategyHeaders []string `json:"strategy-headers,omitempty"`
+	// StrategyKey represents the eval expressions, evaluated with the values of the endpoint request, whose values
+	// are added to the cache key, see Cache.StrategyKey.
+	StrategyKey []string `json:"strategy-key,omitempty"`
+	// OnlyIfStatusCodes represents the status codes of the backend responses that are cached. If not provided, the
+	// default value is 2xx success HTTP status codes.
+	OnlyIfStatusCodes []int `json:"only-if-status-codes,omitempty"`
+}
+
+// Paginate represents the following of the next pages of a paginated backend.
+type Paginate struct {
+	// Comment represents a comment about the pagination.
+	Comment string `json:"@comment,omitempty"`
+	// NextPath represents the gjson path of the next cursor, or of the next page URL, on the body of each page.
+	NextPath string `json:"next-path,omitempty"`
+	// NextQuery represents the query key where the next cursor is sent. If not provided, the value of NextPath is the
+	// URL of the next page.
+	NextQuery string `json:"next-query,omitempty"`
+	// LinkHeader indicates whether the next page URL is obtained from the Link header with rel="next", instead of
+	// NextPath.
+	LinkHeader bool `json:"link-header,omitempty"`
+	// ItemsPath represents the gjson path of the array of items on the body of each page. If not provided, the body
+	// itself is the array of items.
+	ItemsPath string `json:"items-path,omitempty"`
+	// MaxPages represents the maximum number of pages requested, including the first. If not provided, the default
+	// is 10.
+	MaxPages int `json:"max-pages,omitempty"`
+	// MaxItems represents the maximum number of items concatenated. If not provided, the items are not limited.
+	MaxItems int `json:"max-items,omitempty"`
+}
+
+// ResponseSchema represents the JSON Schema validation of the backend response body, to detect the backends that
+// changed their payloads.
+type ResponseSchema struct {
+	// Comment represents a comment about the response schema.
+	Comment string `json:"@comment,omitempty"`
+	// File represents the path of the JSON Schema file, used if Schema is not informed.
+	File string `json:"file,omitempty"`
+	// Schema represents the inline JSON Schema.
+	Schema any `json:"schema,omitempty"`
+	// Mode represents what is done when the body doesn't match the schema. LOG only logs the violations, FAIL
+	// treats the response as a backend error, and STRIP removes the properties not declared on the schema.
+	// If not informed, the default is LOG.
+	Mode enum.ResponseSchemaMode `json:"mode,omitempty"`
+}
+
+// Projection represents the fields projection of a response body in the Gopen application.
+// The paths follow the gjson syntax separated by dot, "*" or "#" matches any key of an object or any element of an
+// array, and numeric keys match the index of an array. Example: "users.#.id"
+type Projection struct {
+	// Allow represents the list of paths that will be kept in the body, all other fields are removed.
+	Allow []string `json:"allow,omitempty"`
+	// Deny represents the list of paths that will be removed from the body, it is applied after the Allow list.
+	Deny []string `json:"deny,omitempty"`
+}
+
+// Transform represents a step of the transform pipeline applied to the backend response body in the Gopen
+// application. Each step works over the array found on Path, or over the whole body if Path is empty.
+type Transform struct {
+	Comment string `json:"@comment,omitempty"`
+	// Type represents the type of the step, which can be MAP, FILTER, FLATTEN, PLUCK, SORT or LIMIT.
+	Type enum.TransformType `json:"type,omitempty"`
+	// Path represents the gjson path of the array to be transformed, if empty the whole body is used.
+	Path string `json:"path,omitempty"`
+	// Fields represents the mapping of the MAP step, where the key is the new key of the element and the value is the
+	// gjson path of the value inside the element.
+	Fields map[string]string `json:"fields,omitempty"`
+	// KeepOthers indicates if the MAP step keeps the fields of the element that were not mapped.
+	KeepOthers bool `json:"keep-others,omitempty"`
+	// If represents the expression of the FILTER step, the current element is accessed by #item.
+	// Example: "#item.active == true && #item.age >= 18"
+	If string `json:"if,omitempty"`
+	// Key represents the gjson path inside the element used by the PLUCK and SORT steps, if empty the SORT step uses
+	// the element itself.
+	Key string `json:"key,omitempty"`
+	// Desc indicates if the SORT step sorts in descending order.
+	Desc bool `json:"desc,omitempty"`
+	// Separator represents the separator of the keys joined by the FLATTEN step, the default value is "_".
+	Separator string `json:"separator,omitempty"`
+	// Offset represents the number of elements skipped by the LIMIT step.
+	Offset int `json:"offset,omitempty"`
+	// Limit represents the maximum number of elements kept by the LIMIT step, if zero all elements are kept.
+	Limit int `json:"limit,omitempty"`
+}
+
 // BackendExtraConfig represents additional configuration options for a backend in the Gopen application.
 // - OmitRequestBody: a boolean flag indicating whether the backend should omit the request body in the outgoing request.
 // If set to true, the backend will not include the request body in the outgoing request.
@@ -319,4 +664,10 @@ type Modifier struct {
 	// Value represents a string value in the Modifier struct.
 	// It is used as a field to store the value of a modification.
 	Value string `json:"value,omitempty"`
+	// If represents an optional boolean expression evaluated against the request and response, the modification is
+	// only applied if the expression is true. Example: "#response.statusCode == 404".
+	If string `json:"if,omitempty"`
+	// OnError represents the policy applied when the modification fails, which can be IGNORE, WARN or FAIL.
+	// The default value is WARN, which logs the error with the trace id and keeps the value unchanged.
+	OnError enum.ModifierOnError `json:"on-error,omitempty"`
 }
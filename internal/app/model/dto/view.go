@@ -16,6 +16,8 @@
 
 package dto
 
+import "github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
+
 // SettingView represents the configuration view for the application.
 // It includes properties such as version, founder, code helpers, and various counts.
 // It also contains a setting object of type GopenView, which represents the detailed configuration.
@@ -41,3 +43,60 @@ type SettingView struct {
 	// Setting represents the detailed configuration view for the Gopen application.
 	Setting Gopen `json:"setting"`
 }
+
+// SchemaViolationsView represents the number of backend responses that didn't match the response schema of a backend.
+type SchemaViolationsView struct {
+	// Location represents where the backend is configured, for example `middlewares.auth` or
+	// `GET "/users" backends[0]`.
+	Location string `json:"location"`
+	// Name represents the name of the backend.
+	Name string `json:"name,omitempty"`
+	// Path represents the path of the backend.
+	Path string `json:"path"`
+	// Mode represents what is done when the backend response doesn't match the schema.
+	Mode enum.ResponseSchemaMode `json:"mode"`
+	// Violations represents the number of backend responses that didn't match the schema.
+	Violations int64 `json:"violations"`
+}
+
+// CacheKeyView represents the view of a cache key on the cache admin routes.
+type CacheKeyView struct {
+	// Key represents the cache key.
+	Key string `json:"key"`
+	// TTL represents the remaining time to live of the cache entry.
+	TTL string `json:"ttl,omitempty"`
+}
+
+// CacheEntryView represents the view of a cache entry on the cache admin routes.
+type CacheEntryView struct {
+	// Key represents the cache key, built from the endpoint and the request.
+	Key string `json:"key"`
+	// TTL represents the remaining time to live of the cache entry.
+	TTL string `json:"ttl,omitempty"`
+	// Value represents the cached response.
+	Value any `json:"value,omitempty"`
+}
+
+// CachePurgeView represents the result of a purge on the cache admin routes.
+type CachePurgeView struct {
+	// Deleted represents the number of deleted cache entries.
+	Deleted int `json:"deleted"`
+}
+
+// CacheStatsView represents the counters of the cache store on the cache admin routes.
+type CacheStatsView struct {
+	// Entries represents the number of entries on the cache store, only counted by the in-memory store.
+	Entries int `json:"entries"`
+	// Memory represents the size of the serialized entries on the cache store, only counted by the in-memory store.
+	Memory string `json:"memory,omitempty"`
+	// MaxEntries represents the maximum number of entries on the cache store, 0 if unlimited.
+	MaxEntries int `json:"max-entries,omitempty"`
+	// MaxMemory represents the maximum size of the serialized entries on the cache store, empty if unlimited.
+	MaxMemory string `json:"max-memory,omitempty"`
+	// Hits represents the number of reads that found the key.
+	Hits uint64 `json:"hits"`
+	// Misses represents the number of reads that did not find the key, or found it expired.
+	Misses uint64 `json:"misses"`
+	// Evictions represents the number of entries evicted to respect the limits of the cache store.
+	Evictions uint64 `json:"evictions"`
+}
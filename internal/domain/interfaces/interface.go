@@ -17,7 +17,9 @@
 package interfaces
 
 import (
+	"context"
 	"net/http"
+	"time"
 )
 
 // RestTemplate is an interface that represents a template for making HTTP requests.
@@ -39,3 +41,15 @@ type RestTemplate interface {
 	// An HTTP response object and an error.
 	MakeRequest(httpRequest *http.Request) (*http.Response, error)
 }
+
+// CacheStore is an interface that represents the store where the backend responses are cached, implemented by the
+// cache store of the infra layer.
+type CacheStore interface {
+	// Set stores the given value with the provided key in the cache store. The value will expire after the specified
+	// duration. If an error occurs during the set operation, it is returned.
+	Set(ctx context.Context, key string, value any, expire time.Duration) error
+	// Get retrieves the value from the cache store with the given key and stores it in the provided destination
+	// object, which must be a pointer. If the cache entry is not found, or an error occurs while retrieving the value,
+	// an error is returned.
+	Get(ctx context.Context, key string, dest any) error
+}
@@ -17,76 +17,206 @@
 package mapper
 
 import (
+	berrors "errors"
 	"github.com/GabrielHCataldo/go-errors/errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
+	"net/http"
 	"time"
 )
 
 // MsgErrBadGateway represents the error message for a bad gateway error.
 // The constant value is "bad gateway error:".
-var MsgErrBadGateway = "bad gateway error:"
+const MsgErrBadGateway = "bad gateway error:"
 
 // MsgErrGatewayTimeout represents the error message for a gateway timeout error.
 // The constant value is "gateway timeout error:".
-var MsgErrGatewayTimeout = "gateway timeout error:"
+const MsgErrGatewayTimeout = "gateway timeout error:"
 
 // MsgErrPayloadTooLarge represents the error message for a payload that is
 // too large.
 // The constant value is "payload too large error:".
-var MsgErrPayloadTooLarge = "payload too large error:"
+const MsgErrPayloadTooLarge = "payload too large error:"
 
 // MsgErrHeaderTooLarge represents the error message for a header too large error.
 // The constant value is "header too large error:".
-var MsgErrHeaderTooLarge = "header too large error:"
+const MsgErrHeaderTooLarge = "header too large error:"
 
 // MsgErrTooManyRequests represents the error message for a too many requests error.
 // The constant value is "too many requests error:".
-var MsgErrTooManyRequests = "too many requests error:"
+const MsgErrTooManyRequests = "too many requests error:"
+
+// MsgErrInternal represents the error message for an internal error.
+// The constant value is "internal error:".
+const MsgErrInternal = "internal error:"
+
+// GatewayError represents an immutable typed error generated by the gateway. It carries the kind of the error, from
+// which the code, the HTTP status code and the retryable flag are obtained, the wrapped cause, and the go-errors
+// detail with the caller and the message, so errors.Details keeps working on it.
+type GatewayError struct {
+	// kind represents the kind of the error.
+	kind enum.ErrorKind
+	// cause represents the wrapped error that caused this error, if any.
+	cause error
+	// detail represents the go-errors detail with the caller and the message of the error.
+	detail error
+}
+
+// errorKindInfo represents the fixed information of each kind of error.
+type errorKindInfo struct {
+	// message represents the prefix of the message of the error.
+	message string
+	// code represents the stable code of the error, exposed to the clients.
+	code string
+	// statusCode represents the HTTP status code of the responses of the error.
+	statusCode int
+	// retryable indicates whether the request may succeed if it is retried.
+	retryable bool
+}
+
+// errorKindInfos maps each kind of error to its fixed information.
+var errorKindInfos = map[enum.ErrorKind]errorKindInfo{
+	enum.ErrorKindInternal:        {MsgErrInternal, "GTW-000", http.StatusInternalServerError, false},
+	enum.ErrorKindBadGateway:      {MsgErrBadGateway, "GTW-001", http.StatusBadGateway, true},
+	enum.ErrorKindGatewayTimeout:  {MsgErrGatewayTimeout, "GTW-002", http.StatusGatewayTimeout, true},
+	enum.ErrorKindPayloadTooLarge: {MsgErrPayloadTooLarge, "GTW-003", http.StatusRequestEntityTooLarge, false},
+	enum.ErrorKindHeaderTooLarge:  {MsgErrHeaderTooLarge, "GTW-004", http.StatusRequestHeaderFieldsTooLarge, false},
+	enum.ErrorKindTooManyRequests: {MsgErrTooManyRequests, "GTW-005", http.StatusTooManyRequests, true},
+}
+
+// ErrBadGateway represents the kind of the bad gateway errors, to be compared using errors.Is of the standard library.
+var ErrBadGateway error = &GatewayError{kind: enum.ErrorKindBadGateway}
 
-// ErrBadGateway represents an error indicating a bad gateway.
-var ErrBadGateway = errors.New(MsgErrBadGateway)
+// ErrGatewayTimeout represents the kind of the gateway timeout errors, to be compared using errors.Is of the standard
+// library.
+var ErrGatewayTimeout error = &GatewayError{kind: enum.ErrorKindGatewayTimeout}
 
-// ErrGatewayTimeout represents the error for a gateway timeout.
-var ErrGatewayTimeout = errors.New(MsgErrGatewayTimeout)
+// ErrPayloadTooLarge represents the kind of the payload too large errors, to be compared using errors.Is of the
+// standard library.
+var ErrPayloadTooLarge error = &GatewayError{kind: enum.ErrorKindPayloadTooLarge}
 
-// ErrPayloadTooLarge represents the payload too large error.
-var ErrPayloadTooLarge = errors.New(MsgErrPayloadTooLarge)
+// ErrHeaderTooLarge represents the kind of the header too large errors, to be compared using errors.Is of the standard
+// library.
+var ErrHeaderTooLarge error = &GatewayError{kind: enum.ErrorKindHeaderTooLarge}
 
-// ErrHeaderTooLarge represents the error for a header that is too large.
-var ErrHeaderTooLarge = errors.New(MsgErrHeaderTooLarge)
+// ErrTooManyRequests represents the kind of the too many requests errors, to be compared using errors.Is of the
+// standard library.
+var ErrTooManyRequests error = &GatewayError{kind: enum.ErrorKindTooManyRequests}
 
-// ErrTooManyRequests represents the error for when there are too many requests.
-var ErrTooManyRequests = errors.New(MsgErrTooManyRequests)
+// ErrInternal represents the kind of the internal errors, to be compared using errors.Is of the standard library.
+var ErrInternal error = &GatewayError{kind: enum.ErrorKindInternal}
+
+// newGatewayError creates a new GatewayError of the kind, with the cause and the message values appended to the
+// message of the kind. The caller of the detail is the caller of the exported constructor.
+func newGatewayError(kind enum.ErrorKind, cause error, message ...any) *GatewayError {
+	return &GatewayError{
+		kind:   kind,
+		cause:  cause,
+		detail: errors.NewSkipCaller(3, append([]any{errorKindInfos[kind].message}, message...)...),
+	}
+}
 
-// NewErrBadGateway creates a new domainmapper.ErrBadGateway error with the specified error as the cause.
+// NewErrBadGateway creates a new bad gateway GatewayError with the specified error as the cause.
 func NewErrBadGateway(err error) error {
-	ErrBadGateway = errors.NewSkipCaller(2, MsgErrBadGateway, err)
-	return ErrBadGateway
+	return newGatewayError(enum.ErrorKindBadGateway, err, err)
 }
 
-// NewErrGatewayTimeoutByErr creates a new domainmapper.ErrGatewayTimeout error with the specified error as the cause.
-// It takes an error as input and returns the corresponding error after handling it, if any. If the input error is not nil,
-// it checks if it is an url.Error and if it has a timeout. If it has a timeout, it creates a new domainmapper.ErrGatewayTimeout
-// error and returns it. For any other type of error, it returns the error as it is.
+// NewErrGatewayTimeoutByErr creates a new gateway timeout GatewayError with the specified error as the cause.
 func NewErrGatewayTimeoutByErr(err error) error {
-	ErrGatewayTimeout = errors.NewSkipCaller(2, MsgErrGatewayTimeout, err)
-	return ErrGatewayTimeout
+	return newGatewayError(enum.ErrorKindGatewayTimeout, err, err)
 }
 
-// NewErrPayloadTooLarge creates a new domainmapper.ErrPayloadTooLarge error with the specified limit as the permitted limit.
+// NewErrGatewayTimeout creates a new gateway timeout GatewayError with the specified duration as the permitted
+// timeout.
+func NewErrGatewayTimeout(timeout time.Duration) error {
+	return newGatewayError(enum.ErrorKindGatewayTimeout, nil, "permitted timeout is", timeout.String())
+}
+
+// NewErrPayloadTooLarge creates a new payload too large GatewayError with the specified limit as the permitted limit.
 func NewErrPayloadTooLarge(limit string) error {
-	ErrPayloadTooLarge = errors.NewSkipCaller(2, MsgErrPayloadTooLarge, "permitted limit is", limit)
-	return ErrPayloadTooLarge
+	return newGatewayError(enum.ErrorKindPayloadTooLarge, nil, "permitted limit is", limit)
 }
 
-// NewErrHeaderTooLarge creates a new domainmapper.ErrHeaderTooLarge error with the specified limit as the permitted limit.
+// NewErrHeaderTooLarge creates a new header too large GatewayError with the specified limit as the permitted limit.
 func NewErrHeaderTooLarge(limit string) error {
-	ErrHeaderTooLarge = errors.NewSkipCaller(2, MsgErrHeaderTooLarge, "permitted limit is", limit)
-	return ErrHeaderTooLarge
+	return newGatewayError(enum.ErrorKindHeaderTooLarge, nil, "permitted limit is", limit)
 }
 
-// NewErrTooManyRequests creates a new domainmapper.ErrTooManyRequests error with the specified capacity and every value.
+// NewErrTooManyRequests creates a new too many requests GatewayError with the specified capacity and every value.
 func NewErrTooManyRequests(capacity int, every time.Duration) error {
-	ErrTooManyRequests = errors.NewSkipCaller(2, MsgErrTooManyRequests, "permitted limit is", capacity,
-		"every", every.String())
-	return ErrTooManyRequests
+	return newGatewayError(enum.ErrorKindTooManyRequests, nil, "permitted limit is", capacity, "every",
+		every.String())
+}
+
+// NewErrPanic creates a new internal GatewayError for a recovered panic. The recovered value is not part of the
+// message, so it never reaches the client, it must be logged by the caller.
+func NewErrPanic() error {
+	return newGatewayError(enum.ErrorKindInternal, nil, "panic error occurred")
+}
+
+// GatewayErrorOf returns the GatewayError found on the chain of the error, using errors.As of the standard library,
+// or nil if the error is not a GatewayError.
+func GatewayErrorOf(err error) *GatewayError {
+	var gatewayErr *GatewayError
+	if berrors.As(err, &gatewayErr) {
+		return gatewayErr
+	}
+	return nil
+}
+
+// StatusCodeByErr returns the HTTP status code of the GatewayError found on the chain of the error, or
+// http.StatusInternalServerError if the error is not a GatewayError.
+func StatusCodeByErr(err error) int {
+	return GatewayErrorOf(err).StatusCode()
+}
+
+// Error returns the error as a string in the go-errors format, so the caller and the message can be obtained with
+// errors.Details. The errors of the kind, like ErrBadGateway, return only the message of the kind.
+func (g *GatewayError) Error() string {
+	if helper.IsNil(g.detail) {
+		return errorKindInfos[g.kind].message
+	}
+	return g.detail.Error()
+}
+
+// Unwrap returns the cause of the error, used by errors.Is and errors.As of the standard library.
+func (g *GatewayError) Unwrap() error {
+	return g.cause
+}
+
+// Is reports whether the target is a GatewayError of the same kind, used by errors.Is of the standard library.
+func (g *GatewayError) Is(target error) bool {
+	targetGatewayErr, ok := target.(*GatewayError)
+	return ok && helper.Equals(g.kind, targetGatewayErr.kind)
+}
+
+// Kind returns the kind of the error, if the instance is nil it returns enum.ErrorKindInternal.
+func (g *GatewayError) Kind() enum.ErrorKind {
+	if helper.IsNil(g) {
+		return enum.ErrorKindInternal
+	}
+	return g.kind
+}
+
+// Code returns the stable code of the error, exposed to the clients.
+func (g *GatewayError) Code() string {
+	return errorKindInfos[g.Kind()].code
+}
+
+// StatusCode returns the HTTP status code of the responses of the error.
+func (g *GatewayError) StatusCode() int {
+	return errorKindInfos[g.Kind()].statusCode
+}
+
+// Retryable returns whether the request may succeed if it is retried.
+func (g *GatewayError) Retryable() bool {
+	return errorKindInfos[g.Kind()].retryable
+}
+
+// Cause returns the wrapped error that caused this error, nil if there is none.
+func (g *GatewayError) Cause() error {
+	if helper.IsNil(g) {
+		return nil
+	}
+	return g.cause
 }
@@ -39,4 +39,8 @@ const (
 	// XGopenSuccess represents the name of the "X-Gopen-Success" HTTP header.
 	// It is used to indicate the success status of a request.
 	XGopenSuccess = "X-Gopen-Success"
+	// XGopenPartial represents the name of the "X-Gopen-Partial" HTTP header.
+	// It is used to indicate that some backend responses failed when the endpoint uses the PARTIAL status code
+	// strategy.
+	XGopenPartial = "X-Gopen-Partial"
 )
@@ -28,9 +28,45 @@ type ModifierScope string
 // ModifierAction represents the action to be performed in the Modifier struct.
 type ModifierAction string
 
+// ModifierOnError represents the policy applied when a modification fails.
+type ModifierOnError string
+
+// TransformType represents the type of step of a transform pipeline applied to the backend response body.
+type TransformType string
+
+// RedactionStrategy represents how a sensitive value is redacted.
+type RedactionStrategy string
+
+// StatusCodeStrategy represents how the status code of an endpoint with multiple backend responses is resolved.
+type StatusCodeStrategy string
+
+// ErrorFormat represents the format of the error responses generated by the gateway.
+type ErrorFormat string
+
+// CacheStatus represents the value of the X-Gopen-Cache header, how the response was obtained from the cache.
+type CacheStatus string
+
+// ErrorKind represents the kind of the errors generated by the gateway.
+type ErrorKind string
+
+// AggregationMerge represents how the bodies of the backend responses are merged on the aggregation.
+type AggregationMerge string
+
+// AggregationConflict represents how a key present on more than one backend response is resolved on the aggregation.
+type AggregationConflict string
+
+// AggregationArrays represents how two arrays on the same key are merged on the aggregation.
+type AggregationArrays string
+
+// ResponseSchemaMode represents what is done when a backend response doesn't match the response schema.
+type ResponseSchemaMode string
+
 // CacheControl represents the header value of cache control.
 type CacheControl string
 
+// RedisMode represents how the cache store connects to the Redis servers.
+type RedisMode string
+
 // ResponseEncode represents the encoding format for the API endpoint response.
 type ResponseEncode string
 
@@ -46,6 +82,7 @@ const (
 const (
 	CacheControlNoCache CacheControl = "no-cache"
 	CacheControlNoStore CacheControl = "no-store"
+	CacheControlPrivate CacheControl = "private"
 )
 const (
 	ModifierScopeRequest  ModifierScope = "REQUEST"
@@ -62,12 +99,86 @@ const (
 	ModifierActionRpl ModifierAction = "RPL"
 	ModifierActionRen ModifierAction = "REN"
 	ModifierActionDel ModifierAction = "DEL"
+	// ModifierActionPatch applies a JSON Patch document (RFC 6902) to the body.
+	ModifierActionPatch ModifierAction = "PATCH"
+	// ModifierActionMerge applies a JSON Merge Patch document (RFC 7396) to the body.
+	ModifierActionMerge ModifierAction = "MERGE"
+)
+const (
+	ModifierOnErrorIgnore ModifierOnError = "IGNORE"
+	ModifierOnErrorWarn   ModifierOnError = "WARN"
+	ModifierOnErrorFail   ModifierOnError = "FAIL"
+)
+const (
+	TransformTypeMap     TransformType = "MAP"
+	TransformTypeFilter  TransformType = "FILTER"
+	TransformTypeFlatten TransformType = "FLATTEN"
+	TransformTypePluck   TransformType = "PLUCK"
+	TransformTypeSort    TransformType = "SORT"
+	TransformTypeLimit   TransformType = "LIMIT"
+)
+const (
+	RedactionStrategyMask    RedactionStrategy = "MASK"
+	RedactionStrategyHash    RedactionStrategy = "HASH"
+	RedactionStrategyRemove  RedactionStrategy = "REMOVE"
+	RedactionStrategyPartial RedactionStrategy = "PARTIAL"
+)
+const (
+	StatusCodeStrategyMostFrequent StatusCodeStrategy = "MOST_FREQUENT"
+	StatusCodeStrategyWorst        StatusCodeStrategy = "WORST"
+	StatusCodeStrategyFirst        StatusCodeStrategy = "FIRST"
+	StatusCodeStrategyLast         StatusCodeStrategy = "LAST"
+	StatusCodeStrategyPartial      StatusCodeStrategy = "PARTIAL"
+)
+const (
+	ErrorFormatProblem ErrorFormat = "PROBLEM"
+	ErrorFormatLegacy  ErrorFormat = "LEGACY"
+)
+const (
+	CacheStatusHit         CacheStatus = "HIT"
+	CacheStatusStale       CacheStatus = "STALE"
+	CacheStatusMiss        CacheStatus = "MISS"
+	CacheStatusRevalidated CacheStatus = "REVALIDATED"
+)
+const (
+	ErrorKindBadGateway      ErrorKind = "BAD_GATEWAY"
+	ErrorKindGatewayTimeout  ErrorKind = "GATEWAY_TIMEOUT"
+	ErrorKindPayloadTooLarge ErrorKind = "PAYLOAD_TOO_LARGE"
+	ErrorKindHeaderTooLarge  ErrorKind = "HEADER_TOO_LARGE"
+	ErrorKindTooManyRequests ErrorKind = "TOO_MANY_REQUESTS"
+	ErrorKindInternal        ErrorKind = "INTERNAL"
+)
+const (
+	AggregationMergeShallow AggregationMerge = "SHALLOW"
+	AggregationMergeDeep    AggregationMerge = "DEEP"
+)
+const (
+	AggregationConflictCollect   AggregationConflict = "COLLECT"
+	AggregationConflictFirstWins AggregationConflict = "FIRST_WINS"
+	AggregationConflictLastWins  AggregationConflict = "LAST_WINS"
+	AggregationConflictError     AggregationConflict = "ERROR"
+)
+const (
+	AggregationArraysReplace AggregationArrays = "REPLACE"
+	AggregationArraysConcat  AggregationArrays = "CONCAT"
+)
+const (
+	ResponseSchemaModeLog   ResponseSchemaMode = "LOG"
+	ResponseSchemaModeFail  ResponseSchemaMode = "FAIL"
+	ResponseSchemaModeStrip ResponseSchemaMode = "STRIP"
+)
+const (
+	RedisModeStandalone RedisMode = "STANDALONE"
+	RedisModeSentinel   RedisMode = "SENTINEL"
+	RedisModeCluster    RedisMode = "CLUSTER"
 )
 const (
 	ContentTypeJson ContentType = "JSON"
 	ContentTypeXml  ContentType = "XML"
 	ContentTypeYml  ContentType = "YML"
 	ContentTypeText ContentType = "TEXT"
+	// ContentTypeProblemJson represents the RFC 7807 problem details JSON, used only on the gateway error responses.
+	ContentTypeProblemJson ContentType = "PROBLEM_JSON"
 )
 
 // ContentTypeFromString converts a string representation of a content type
@@ -111,22 +222,141 @@ func (m ModifierContext) IsEnumValid() bool {
 
 // IsEnumValid checks if the ModifierAction is a valid enumeration value.
 // It returns true if the ModifierAction is either ModifierActionSet, ModifierActionApd,
-// ModifierActionRpl, ModifierActionAdd, ModifierActionDel, or ModifierActionRen,
-// otherwise it returns false.
+// ModifierActionRpl, ModifierActionAdd, ModifierActionDel, ModifierActionRen, ModifierActionPatch or
+// ModifierActionMerge, otherwise it returns false.
 func (m ModifierAction) IsEnumValid() bool {
 	switch m {
-	case ModifierActionSet, ModifierActionApd, ModifierActionRpl, ModifierActionAdd, ModifierActionDel, ModifierActionRen:
+	case ModifierActionSet, ModifierActionApd, ModifierActionRpl, ModifierActionAdd, ModifierActionDel, ModifierActionRen,
+		ModifierActionPatch, ModifierActionMerge:
+		return true
+	}
+	return false
+}
+
+// IsDocumentAction checks if the ModifierAction receives a whole document as value, which is the case of
+// ModifierActionPatch and ModifierActionMerge.
+func (m ModifierAction) IsDocumentAction() bool {
+	return m == ModifierActionPatch || m == ModifierActionMerge
+}
+
+// IsEnumValid checks if the ModifierOnError is a valid enumeration value.
+// It returns true if the ModifierOnError is either ModifierOnErrorIgnore, ModifierOnErrorWarn or
+// ModifierOnErrorFail, otherwise it returns false.
+func (m ModifierOnError) IsEnumValid() bool {
+	switch m {
+	case ModifierOnErrorIgnore, ModifierOnErrorWarn, ModifierOnErrorFail:
+		return true
+	}
+	return false
+}
+
+// IsEnumValid checks if the TransformType is a valid enumeration value.
+// It returns true if the TransformType is either TransformTypeMap, TransformTypeFilter, TransformTypeFlatten,
+// TransformTypePluck, TransformTypeSort or TransformTypeLimit, otherwise it returns false.
+func (t TransformType) IsEnumValid() bool {
+	switch t {
+	case TransformTypeMap, TransformTypeFilter, TransformTypeFlatten, TransformTypePluck, TransformTypeSort,
+		TransformTypeLimit:
+		return true
+	}
+	return false
+}
+
+// IsEnumValid checks if the RedactionStrategy is a valid enumeration value.
+// It returns true if the RedactionStrategy is either RedactionStrategyMask, RedactionStrategyHash,
+// RedactionStrategyRemove or RedactionStrategyPartial, otherwise it returns false.
+func (r RedactionStrategy) IsEnumValid() bool {
+	switch r {
+	case RedactionStrategyMask, RedactionStrategyHash, RedactionStrategyRemove, RedactionStrategyPartial:
+		return true
+	}
+	return false
+}
+
+// IsEnumValid checks if the StatusCodeStrategy is a valid enumeration value.
+// It returns true if the StatusCodeStrategy is either StatusCodeStrategyMostFrequent, StatusCodeStrategyWorst,
+// StatusCodeStrategyFirst, StatusCodeStrategyLast or StatusCodeStrategyPartial, otherwise it returns false.
+func (s StatusCodeStrategy) IsEnumValid() bool {
+	switch s {
+	case StatusCodeStrategyMostFrequent, StatusCodeStrategyWorst, StatusCodeStrategyFirst, StatusCodeStrategyLast,
+		StatusCodeStrategyPartial:
+		return true
+	}
+	return false
+}
+
+// IsEnumValid checks if the ErrorFormat is a valid enumeration value.
+// It returns true if the ErrorFormat is either ErrorFormatProblem or ErrorFormatLegacy, otherwise it returns false.
+func (e ErrorFormat) IsEnumValid() bool {
+	switch e {
+	case ErrorFormatProblem, ErrorFormatLegacy:
+		return true
+	}
+	return false
+}
+
+// IsEnumValid checks if the AggregationMerge is a valid enumeration value.
+// It returns true if the AggregationMerge is either AggregationMergeShallow or AggregationMergeDeep, otherwise it
+// returns false.
+func (a AggregationMerge) IsEnumValid() bool {
+	switch a {
+	case AggregationMergeShallow, AggregationMergeDeep:
+		return true
+	}
+	return false
+}
+
+// IsEnumValid checks if the AggregationConflict is a valid enumeration value.
+// It returns true if the AggregationConflict is either AggregationConflictCollect, AggregationConflictFirstWins,
+// AggregationConflictLastWins or AggregationConflictError, otherwise it returns false.
+func (a AggregationConflict) IsEnumValid() bool {
+	switch a {
+	case AggregationConflictCollect, AggregationConflictFirstWins, AggregationConflictLastWins,
+		AggregationConflictError:
+		return true
+	}
+	return false
+}
+
+// IsEnumValid checks if the AggregationArrays is a valid enumeration value.
+// It returns true if the AggregationArrays is either AggregationArraysReplace or AggregationArraysConcat, otherwise
+// it returns false.
+func (a AggregationArrays) IsEnumValid() bool {
+	switch a {
+	case AggregationArraysReplace, AggregationArraysConcat:
+		return true
+	}
+	return false
+}
+
+// IsEnumValid checks if the ResponseSchemaMode is a valid enumeration value.
+// It returns true if the ResponseSchemaMode is either ResponseSchemaModeLog, ResponseSchemaModeFail or
+// ResponseSchemaModeStrip, otherwise it returns false.
+func (r ResponseSchemaMode) IsEnumValid() bool {
+	switch r {
+	case ResponseSchemaModeLog, ResponseSchemaModeFail, ResponseSchemaModeStrip:
 		return true
 	}
 	return false
 }
 
 // IsEnumValid checks if the CacheControl is a valid enumeration value.
-// It returns true if the CacheControl is either CacheControlNoCache or CacheControlNoStore,
+// It returns true if the CacheControl is either CacheControlNoCache, CacheControlNoStore or CacheControlPrivate,
 // otherwise it returns false.
 func (c CacheControl) IsEnumValid() bool {
 	switch c {
-	case CacheControlNoCache, CacheControlNoStore:
+	case CacheControlNoCache, CacheControlNoStore, CacheControlPrivate:
+		return true
+	}
+	return false
+}
+
+// IsEnumValid checks if the RedisMode is a valid enumeration value.
+// It returns true if the RedisMode is either RedisModeStandalone, RedisModeSentinel or RedisModeCluster,
+// otherwise it returns false.
+func (r RedisMode) IsEnumValid() bool {
+	switch r {
+	case RedisModeStandalone, RedisModeSentinel, RedisModeCluster:
 		return true
 	}
 	return false
@@ -169,13 +399,16 @@ func (r ResponseEncode) ContentType() ContentType {
 }
 
 // String returns the string representation of the ContentType value.
-// It returns "application/json" if c is ContentTypeJson, "application/xml" if c is ContentTypeXml,
+// It returns "application/json" if c is ContentTypeJson, "application/problem+json" if c is ContentTypeProblemJson,
+// "application/xml" if c is ContentTypeXml,
 // "application/x-yaml" if c is ContentTypeYml, and "text/plain" for any other value of c.
 // This method is used to convert the ContentType value to its corresponding MIME type string representation.
 func (c ContentType) String() string {
 	switch c {
 	case ContentTypeJson:
 		return "application/json"
+	case ContentTypeProblemJson:
+		return "application/problem+json"
 	case ContentTypeXml:
 		return "application/xml"
 	case ContentTypeYml:
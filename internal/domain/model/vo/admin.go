@@ -0,0 +1,63 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"crypto/subtle"
+	"github.com/GabrielHCataldo/go-errors/errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"strings"
+)
+
+// Admin represents the configuration of the admin routes of the gateway, like the cache purge and inspection routes.
+// The admin routes are only registered when configured, and every request must be authorized with the token.
+type Admin struct {
+	// token represents the bearer token that authorizes the requests to the admin routes.
+	token string
+}
+
+// newAdmin creates a new instance of Admin based on the provided adminDTO.
+// If the adminDTO is nil, it returns nil.
+func newAdmin(adminDTO *dto.Admin) *Admin {
+	if helper.IsNil(adminDTO) {
+		return nil
+	}
+	return &Admin{
+		token: adminDTO.Token,
+	}
+}
+
+// Token returns the bearer token that authorizes the requests to the admin routes.
+func (a *Admin) Token() string {
+	return a.token
+}
+
+// Validate checks the configuration of the admin routes, returning an error if the token is not informed.
+func (a *Admin) Validate() error {
+	if helper.IsEmpty(a.token) {
+		return errors.New("token must be informed")
+	}
+	return nil
+}
+
+// Authorized returns whether the value of the Authorization header is the bearer token of the admin routes, compared
+// in constant time.
+func (a *Admin) Authorized(authorization string) bool {
+	token, found := strings.CutPrefix(authorization, "Bearer ")
+	return found && helper.IsNotEmpty(a.token) && subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) == 1
+}
@@ -0,0 +1,299 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"encoding/json"
+	"github.com/GabrielHCataldo/go-errors/errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
+	"github.com/tidwall/gjson"
+	"strings"
+)
+
+// Aggregation represents the options of the aggregation of the backend responses of an endpoint.
+// The bodies are always merged in the order of the response history, that is, the order of the backends on the
+// configuration, so the result is the same regardless of how long each backend takes to respond.
+type Aggregation struct {
+	// merge represents how the bodies are merged.
+	merge enum.AggregationMerge
+	// conflict represents how a key present on more than one backend response is resolved.
+	conflict enum.AggregationConflict
+	// arrays represents how two arrays on the same key are merged.
+	arrays enum.AggregationArrays
+	// dropNulls indicates whether the null values are dropped before the merge.
+	dropNulls bool
+}
+
+// aggregationField represents a field of a JSON object being aggregated, keeping the raw value and whether the
+// value was collected into an array by the enum.AggregationConflictCollect policy.
+type aggregationField struct {
+	// key represents the key of the field.
+	key string
+	// raw represents the raw JSON value of the field.
+	raw string
+	// collected indicates whether the raw value is an array built by the collect policy.
+	collected bool
+	// children represents the fields of the value when it is an object merged deeply.
+	children *aggregationObject
+}
+
+// aggregationObject represents a JSON object being aggregated, keeping the order of the keys by first appearance.
+type aggregationObject struct {
+	// fields represents the fields of the object in order.
+	fields []*aggregationField
+	// index represents the position of each key on fields.
+	index map[string]int
+}
+
+// newAggregation creates a new instance of Aggregation based on the provided aggregationDTO.
+// If the aggregationDTO is nil, it returns nil.
+func newAggregation(aggregationDTO *dto.Aggregation) *Aggregation {
+	if helper.IsNil(aggregationDTO) {
+		return nil
+	}
+	return &Aggregation{
+		merge:     aggregationDTO.Merge,
+		conflict:  aggregationDTO.Conflict,
+		arrays:    aggregationDTO.Arrays,
+		dropNulls: aggregationDTO.DropNulls,
+	}
+}
+
+// Merge returns how the bodies are merged, if not configured it returns enum.AggregationMergeShallow.
+func (a *Aggregation) Merge() enum.AggregationMerge {
+	if helper.IsNotEmpty(a.merge) {
+		return a.merge
+	}
+	return enum.AggregationMergeShallow
+}
+
+// Conflict returns how a key present on more than one backend response is resolved, if not configured it returns
+// enum.AggregationConflictCollect.
+func (a *Aggregation) Conflict() enum.AggregationConflict {
+	if helper.IsNotEmpty(a.conflict) {
+		return a.conflict
+	}
+	return enum.AggregationConflictCollect
+}
+
+// Arrays returns how two arrays on the same key are merged, if not configured it returns
+// enum.AggregationArraysReplace.
+func (a *Aggregation) Arrays() enum.AggregationArrays {
+	if helper.IsNotEmpty(a.arrays) {
+		return a.arrays
+	}
+	return enum.AggregationArraysReplace
+}
+
+// DropNulls returns whether the null values are dropped before the merge.
+func (a *Aggregation) DropNulls() bool {
+	return a.dropNulls
+}
+
+// Validate checks the configuration of the aggregation, returning an error if any enumeration is invalid.
+func (a *Aggregation) Validate() error {
+	if !a.Merge().IsEnumValid() {
+		return errors.New("merge", a.merge, "is invalid")
+	} else if !a.Conflict().IsEnumValid() {
+		return errors.New("conflict", a.conflict, "is invalid")
+	} else if !a.Arrays().IsEnumValid() {
+		return errors.New("arrays", a.arrays, "is invalid")
+	}
+	return nil
+}
+
+// Aggregate merges the JSON objects in the order received, returning the raw JSON of the merged object. The
+// values that are not objects are ignored. If the conflict policy is enum.AggregationConflictError and a key has
+// different values on more than one object, it returns an error naming the key.
+func (a *Aggregation) Aggregate(objects []string) (string, error) {
+	root := newAggregationObject()
+	for _, object := range objects {
+		result := gjson.Parse(object)
+		if !result.IsObject() {
+			continue
+		}
+		if err := a.mergeObject(root, result, ""); helper.IsNotNil(err) {
+			return "", err
+		}
+	}
+	return root.raw(), nil
+}
+
+// mergeObject merges the fields of the source object into the target object.
+func (a *Aggregation) mergeObject(target *aggregationObject, source gjson.Result, path string) (err error) {
+	source.ForEach(func(key, value gjson.Result) bool {
+		// caso configurado, ignoramos os valores nulos
+		if a.DropNulls() && helper.Equals(value.Type, gjson.Null) {
+			return true
+		}
+		err = a.mergeField(target, key.String(), value, joinAggregationPath(path, key.String()))
+		return helper.IsNil(err)
+	})
+	return err
+}
+
+// mergeField merges the value on the key of the target object, resolving the conflict if the key already exists.
+func (a *Aggregation) mergeField(target *aggregationObject, key string, value gjson.Result, path string) error {
+	field := target.get(key)
+	// caso a chave ainda não exista, apenas adicionamos
+	if helper.IsNil(field) {
+		field = &aggregationField{key: key}
+		if helper.Equals(a.Merge(), enum.AggregationMergeDeep) && value.IsObject() {
+			field.children = newAggregationObject()
+			if err := a.mergeObject(field.children, value, path); helper.IsNotNil(err) {
+				return err
+			}
+		} else {
+			field.raw = a.rawValue(value)
+		}
+		target.add(field)
+		return nil
+	}
+
+	// caso ambos sejam objetos no merge profundo, mesclamos os campos
+	if helper.IsNotNil(field.children) && value.IsObject() {
+		return a.mergeObject(field.children, value, path)
+	}
+
+	// caso ambos sejam arrays e configurado para concatenar, concatenamos os itens
+	raw := a.rawValue(value)
+	existing := field.value()
+	if helper.Equals(a.Arrays(), enum.AggregationArraysConcat) && !field.collected &&
+		gjson.Parse(existing).IsArray() && value.IsArray() {
+		field.set(concatJsonArrays(existing, raw), false)
+		return nil
+	}
+
+	// valores iguais não são considerados conflito
+	if helper.Equals(existing, raw) {
+		return nil
+	}
+
+	// resolvemos o conflito pela política configurada
+	switch a.Conflict() {
+	case enum.AggregationConflictFirstWins:
+		return nil
+	case enum.AggregationConflictLastWins:
+		field.set(raw, false)
+	case enum.AggregationConflictError:
+		return errors.New("Aggregation conflict on key", path, "between backend responses!")
+	default:
+		if field.collected {
+			field.set(concatJsonArrays(existing, "["+raw+"]"), true)
+		} else {
+			field.set("["+existing+","+raw+"]", true)
+		}
+	}
+	return nil
+}
+
+// rawValue returns the raw JSON of the value, without the null fields if configured.
+func (a *Aggregation) rawValue(value gjson.Result) string {
+	if a.DropNulls() {
+		return dropJsonNulls(value)
+	}
+	return value.Raw
+}
+
+// newAggregationObject creates a new empty aggregationObject.
+func newAggregationObject() *aggregationObject {
+	return &aggregationObject{index: map[string]int{}}
+}
+
+// get returns the field of the key, or nil if the key doesn't exist.
+func (o *aggregationObject) get(key string) *aggregationField {
+	if i, ok := o.index[key]; ok {
+		return o.fields[i]
+	}
+	return nil
+}
+
+// add appends the field at the end of the object.
+func (o *aggregationObject) add(field *aggregationField) {
+	o.index[field.key] = len(o.fields)
+	o.fields = append(o.fields, field)
+}
+
+// raw returns the raw JSON of the object, with the keys in order of first appearance.
+func (o *aggregationObject) raw() string {
+	fields := make([]string, len(o.fields))
+	for i, field := range o.fields {
+		key, _ := json.Marshal(field.key)
+		fields[i] = string(key) + ":" + field.value()
+	}
+	return "{" + strings.Join(fields, ",") + "}"
+}
+
+// value returns the raw JSON value of the field.
+func (f *aggregationField) value() string {
+	if helper.IsNotNil(f.children) {
+		return f.children.raw()
+	}
+	return f.raw
+}
+
+// set replaces the raw JSON value of the field, discarding the children of the deep merge.
+func (f *aggregationField) set(raw string, collected bool) {
+	f.raw = raw
+	f.collected = collected
+	f.children = nil
+}
+
+// joinAggregationPath returns the path of the key on the aggregated object.
+func joinAggregationPath(path, key string) string {
+	if helper.IsEmpty(path) {
+		return key
+	}
+	return path + "." + key
+}
+
+// concatJsonArrays returns the raw JSON array with the items of both arrays.
+func concatJsonArrays(first, second string) string {
+	var items []string
+	for _, array := range []string{first, second} {
+		gjson.Parse(array).ForEach(func(_, value gjson.Result) bool {
+			items = append(items, value.Raw)
+			return true
+		})
+	}
+	return "[" + strings.Join(items, ",") + "]"
+}
+
+// dropJsonNulls returns the raw JSON value without the null fields of the objects, walking the nested objects and
+// the items of the arrays.
+func dropJsonNulls(result gjson.Result) string {
+	if result.IsArray() {
+		var items []string
+		result.ForEach(func(_, value gjson.Result) bool {
+			items = append(items, dropJsonNulls(value))
+			return true
+		})
+		return "[" + strings.Join(items, ",") + "]"
+	} else if !result.IsObject() {
+		return result.Raw
+	}
+	var fields []string
+	result.ForEach(func(key, value gjson.Result) bool {
+		if helper.IsNotEqualTo(value.Type, gjson.Null) {
+			fields = append(fields, key.Raw+":"+dropJsonNulls(value))
+		}
+		return true
+	})
+	return "{" + strings.Join(fields, ",") + "}"
+}
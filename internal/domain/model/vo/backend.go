@@ -20,8 +20,10 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"github.com/GabrielHCataldo/go-errors/errors"
 	"github.com/GabrielHCataldo/go-helper/helper"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
 	"io"
 	"net/http"
 	"net/url"
@@ -43,6 +45,18 @@ type Backend struct {
 	forwardQueries []string
 	// modifiers is an instance of BackendModifiers containing modifiers for the backend request and response.
 	modifiers *BackendModifiers
+	// projection is an instance of Projection containing the fields projection of the backend response body.
+	projection *Projection
+	// transforms represents the pipeline of steps applied to the backend response body.
+	transforms []Transform
+	// responseSchema is an instance of ResponseSchema containing the validation of the backend response body.
+	responseSchema *ResponseSchema
+	// forEach is an instance of ForEach containing the fan-out of the backend over a previous backend response.
+	forEach *ForEach
+	// paginate is an instance of Paginate containing the following of the next pages of the backend.
+	paginate *Paginate
+	// cache is an instance of BackendCache containing the cache of the backend responses.
+	cache *BackendCache
 	// extraConfig is an instance of BackendExtraConfig containing extra configuration options for the backend.
 	extraConfig *BackendExtraConfig
 }
@@ -147,6 +161,14 @@ func NewBackendRequest(backendVO *Backend, balancedHost string, requestVO *Reque
 
 	// inicializamos a query a ser utilizado na construção do VO filtrada pelo forward-queries
 	query := requestVO.Query().FilterByForwarded(backendVO.forwardQueries)
+	// os valores de query preenchidos pelo for-each são sempre repassados
+	if helper.IsNotNil(backendVO.forEach) {
+		for key := range backendVO.forEach.Query() {
+			if requestVO.Query().Exists(key) {
+				query = query.Set(key, requestVO.Query()[key])
+			}
+		}
+	}
 
 	// inicializamos os params
 	params := NewParamsByPath(backendVO.path, requestVO.params)
@@ -202,6 +224,30 @@ func NewBackendResponse(backendVO *Backend, httpResponse *http.Response) *backen
 	}
 }
 
+// NewBackendResponseByCache creates a new instance of backendResponse based on the provided cache response of the
+// backend, see NewCacheResponseByBackendResponse, with the omit and group values of the backendVO.
+func NewBackendResponseByCache(backendVO *Backend, cacheResponseVO *CacheResponse) *backendResponse {
+	// instanciamos o omit e group
+	var omit bool
+	var group bool
+
+	// se tiver extraConfig preenchemos os valores
+	if helper.IsNotNil(backendVO.ExtraConfig()) {
+		omit = backendVO.ExtraConfig().OmitResponse()
+		group = backendVO.ExtraConfig().GroupResponse()
+	}
+
+	// construímos o objeto de valor do backend response
+	return &backendResponse{
+		name:       backendVO.Name(),
+		omit:       omit,
+		group:      group,
+		statusCode: cacheResponseVO.StatusCode,
+		header:     cacheResponseVO.Header,
+		body:       newBodyFromCacheBody(cacheResponseVO.Body),
+	}
+}
+
 // newBackend creates a new Backend instance based on the provided backendDTO.
 // It takes the backendDTO fields and assigns them to the corresponding fields in the Backend struct.
 // It also creates a new BackendModifiers instance by calling the newBackendModifier function,
@@ -218,6 +264,12 @@ func newBackend(backendDTO dto.Backend) Backend {
 		forwardHeaders: backendDTO.ForwardHeaders,
 		forwardQueries: backendDTO.ForwardQueries,
 		modifiers:      newBackendModifier(backendDTO.Modifiers),
+		projection:     newProjection(backendDTO.Projection),
+		transforms:     newTransforms(backendDTO.Transform),
+		responseSchema: newResponseSchema(backendDTO.ResponseSchema),
+		forEach:        newForEach(backendDTO.ForEach),
+		paginate:       newPaginate(backendDTO.Paginate),
+		cache:          newBackendCache(backendDTO),
 		extraConfig:    newBackendExtraConfig(backendDTO.ExtraConfig),
 	}
 }
@@ -235,6 +287,12 @@ func newMiddlewareBackend(backendVO *Backend, backendExtraConfigVO *BackendExtra
 		forwardHeaders: backendVO.forwardHeaders,
 		forwardQueries: backendVO.forwardQueries,
 		modifiers:      backendVO.modifiers,
+		projection:     backendVO.projection,
+		transforms:     backendVO.transforms,
+		responseSchema: backendVO.responseSchema,
+		forEach:        backendVO.forEach,
+		paginate:       backendVO.paginate,
+		cache:          backendVO.cache,
 		extraConfig:    backendExtraConfigVO,
 	}
 }
@@ -341,6 +399,71 @@ func (b *Backend) BackendModifiers() *BackendModifiers {
 	return b.modifiers
 }
 
+// Projection returns the fields projection applied to the backend response body, or nil if not configured.
+func (b *Backend) Projection() *Projection {
+	return b.projection
+}
+
+// Transforms returns the pipeline of steps applied to the backend response body, in the configured order.
+func (b *Backend) Transforms() []Transform {
+	return b.transforms
+}
+
+// ResponseSchema returns the validation of the backend response body, or nil if not configured.
+func (b *Backend) ResponseSchema() *ResponseSchema {
+	return b.responseSchema
+}
+
+// ForEach returns the fan-out of the Backend instance over a previous backend response, or nil if not configured.
+func (b *Backend) ForEach() *ForEach {
+	return b.forEach
+}
+
+// Paginate returns the following of the next pages of the Backend instance, or nil if not configured.
+func (b *Backend) Paginate() *Paginate {
+	return b.paginate
+}
+
+// Cache returns the cache of the responses of the Backend instance, or nil if not configured.
+func (b *Backend) Cache() *BackendCache {
+	return b.cache
+}
+
+// HasCache returns whether the Backend instance has the cache of the responses enabled.
+func (b *Backend) HasCache() bool {
+	return helper.IsNotNil(b.cache) && b.cache.Enabled()
+}
+
+// Validate validates the response schema, the fan-out, the pagination, the cache and the modifiers of the Backend
+// instance, returning the messages of the configurations that can never be applied. If there is nothing to validate,
+// it returns nil.
+func (b *Backend) Validate() (messages []string) {
+	if helper.IsNotNil(b.responseSchema) {
+		if err := b.responseSchema.Validate(); helper.IsNotNil(err) {
+			messages = append(messages, fmt.Sprintf("response-schema: %s", errors.Details(err).GetMessage()))
+		}
+	}
+	if helper.IsNotNil(b.forEach) {
+		if err := b.forEach.Validate(); helper.IsNotNil(err) {
+			messages = append(messages, fmt.Sprintf("for-each: %s", errors.Details(err).GetMessage()))
+		}
+	}
+	if helper.IsNotNil(b.paginate) {
+		if err := b.paginate.Validate(); helper.IsNotNil(err) {
+			messages = append(messages, fmt.Sprintf("paginate: %s", errors.Details(err).GetMessage()))
+		}
+	}
+	if helper.IsNotNil(b.cache) {
+		if err := b.cache.Validate(); helper.IsNotNil(err) {
+			messages = append(messages, fmt.Sprintf("cache.%s", errors.Details(err).GetMessage()))
+		}
+	}
+	if helper.IsNotNil(b.modifiers) {
+		messages = append(messages, b.modifiers.Validate()...)
+	}
+	return messages
+}
+
 // ExtraConfig returns the extra configuration options for the Backend instance.
 // It returns an instance of BackendExtraConfig that contains additional configuration options
 // such as grouping response, omitting request body, and omitting response.
@@ -385,6 +508,21 @@ func (b *BackendModifiers) Body() []Modifier {
 	return b.body
 }
 
+// Validate validates all modifiers of the BackendModifiers instance, checking the actions supported by each group,
+// and returns the messages of the modifiers that can never be applied, prefixed by the group and index.
+func (b *BackendModifiers) Validate() (messages []string) {
+	allActions := []enum.ModifierAction{enum.ModifierActionAdd, enum.ModifierActionApd, enum.ModifierActionSet,
+		enum.ModifierActionRpl, enum.ModifierActionRen, enum.ModifierActionDel}
+	paramActions := []enum.ModifierAction{enum.ModifierActionSet, enum.ModifierActionRpl, enum.ModifierActionRen,
+		enum.ModifierActionDel}
+	bodyActions := append(allActions, enum.ModifierActionPatch, enum.ModifierActionMerge)
+
+	messages = append(messages, validateModifiers("header", b.header, allActions)...)
+	messages = append(messages, validateModifiers("param", b.param, paramActions)...)
+	messages = append(messages, validateModifiers("query", b.query, allActions)...)
+	return append(messages, validateModifiers("body", b.body, bodyActions)...)
+}
+
 // CountAll returns the total count of modifiers for a BackendModifiers instance.
 // It counts the number of valid `statusCode` and the length of `header`, `params`, `query`, and `body` slices,
 // and adds them up to get the total count.
@@ -656,6 +794,11 @@ func (b *backendResponse) Key(index int) (key string) {
 	return key
 }
 
+// Name returns the name of the backend that generated the backendResponse.
+func (b *backendResponse) Name() string {
+	return b.name
+}
+
 // StatusCode returns the `statusCode` of the `backendResponse` instance.
 func (b *backendResponse) StatusCode() int {
 	return b.statusCode
@@ -696,7 +839,7 @@ func (b *backendResponse) GroupResponse() bool {
 // the body of the response as an interface{} type.
 func (b *backendResponse) Eval() any {
 	var evalBody any
-	if helper.IsNotNil(evalBody) {
+	if helper.IsNotNil(b.body) {
 		evalBody = b.body.Interface()
 	}
 	return map[string]any{
@@ -705,3 +848,16 @@ func (b *backendResponse) Eval() any {
 		"body":       evalBody,
 	}
 }
+
+// validateModifiers validates each modifier of the group with the supported actions, returning the messages of the
+// invalid modifiers in the format "modifiers.{group}[{index}]: {error}".
+func validateModifiers(group string, modifiers []Modifier, supportedActions []enum.ModifierAction) []string {
+	var messages []string
+	for index, modifierVO := range modifiers {
+		if err := modifierVO.Validate(supportedActions...); helper.IsNotNil(err) {
+			messages = append(messages, fmt.Sprintf("modifiers.%s[%v]: %s", group, index,
+				errors.Details(err).GetMessage()))
+		}
+	}
+	return messages
+}
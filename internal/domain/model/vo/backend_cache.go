@@ -0,0 +1,174 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/GabrielHCataldo/go-errors/errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BackendCache represents the cache of the responses of a backend, stored before the response modifiers, so the
+// backends of an endpoint can be cached with their own durations and keys.
+type BackendCache struct {
+	// id represents the stable identity of the backend in the cache keys, see backendCacheId.
+	id string
+	// enabled represents a boolean indicating whether caching is enabled for the backend.
+	enabled bool
+	// duration represents the duration for which the backend response is cached.
+	duration time.Duration
+	// strategyHeaders represents the headers of the backend request whose values are added to the cache key.
+	strategyHeaders []string
+	// strategyKey represents the eval expressions whose values are added to the cache key.
+	strategyKey []string
+	// strategyKeyExpressions represents the parsed expressions of the strategyKey, nil on the malformed ones.
+	strategyKeyExpressions []*Expression
+	// onlyIfStatusCodes represents the status codes of the backend responses that are cached.
+	onlyIfStatusCodes []int
+}
+
+// newBackendCache creates a new instance of BackendCache based on the provided backendDTO cache.
+// If the cache of the backendDTO is nil, it returns nil. If the duration is invalid, it is logged and set to 0, see
+// Validate.
+func newBackendCache(backendDTO dto.Backend) *BackendCache {
+	backendCacheDTO := backendDTO.Cache
+	if helper.IsNil(backendCacheDTO) {
+		return nil
+	}
+	return &BackendCache{
+		id:                     backendCacheId(backendDTO),
+		enabled:                backendCacheDTO.Enabled,
+		duration:               parseDuration("backend.cache.duration", backendCacheDTO.Duration),
+		strategyHeaders:        backendCacheDTO.StrategyHeaders,
+		strategyKey:            backendCacheDTO.StrategyKey,
+		strategyKeyExpressions: parseStrategyKey(backendCacheDTO.StrategyKey),
+		onlyIfStatusCodes:      backendCacheDTO.OnlyIfStatusCodes,
+	}
+}
+
+// Enabled returns whether caching is enabled for the backend.
+func (b *BackendCache) Enabled() bool {
+	return b.enabled
+}
+
+// Duration returns the duration for which the backend response is cached.
+func (b *BackendCache) Duration() time.Duration {
+	return b.duration
+}
+
+// DurationStr returns the duration as a string, empty if not configured.
+func (b *BackendCache) DurationStr() string {
+	if helper.IsEmpty(b.duration) {
+		return ""
+	}
+	return b.duration.String()
+}
+
+// StrategyHeaders returns the headers of the backend request whose values are added to the cache key.
+func (b *BackendCache) StrategyHeaders() []string {
+	return b.strategyHeaders
+}
+
+// StrategyKeyExpressions returns the eval expressions whose values are added to the cache key.
+func (b *BackendCache) StrategyKeyExpressions() []string {
+	return b.strategyKey
+}
+
+// OnlyIfStatusCodes returns the status codes of the backend responses that are cached.
+func (b *BackendCache) OnlyIfStatusCodes() []int {
+	return b.onlyIfStatusCodes
+}
+
+// Validate validates the duration, if the cache is enabled, and the expressions of the strategy key, returning the
+// error of the first invalid configuration.
+func (b *BackendCache) Validate() error {
+	if b.enabled && helper.IsLessThanOrEqual(b.duration, 0) {
+		return errors.New("duration must be greater than 0")
+	}
+	for index, raw := range b.strategyKey {
+		if _, err := newExpression(raw); helper.IsNotNil(err) {
+			return errors.New(fmt.Sprintf("strategy-key[%v]:", index), errors.Details(err).GetMessage())
+		}
+	}
+	return nil
+}
+
+// AllowStatusCode returns whether the backend response with the status code is cached. If the onlyIfStatusCodes
+// field is empty, only the 2xx success status codes are cached.
+func (b *BackendCache) AllowStatusCode(statusCode int) bool {
+	if helper.IsEmpty(b.onlyIfStatusCodes) {
+		return helper.IsGreaterThanOrEqual(statusCode, 200) && helper.IsLessThanOrEqual(statusCode, 299)
+	}
+	return helper.Contains(b.onlyIfStatusCodes, statusCode)
+}
+
+// StrategyKey generates the cache key of the current backend request of the requestVO for the endpoint.
+// The generated key follows the pattern:
+// "{Endpoint Prefix}{Backend Id}:{HTTP Method}:{Backend Path}?{Backend Query}:{Strategy Header 1}:...:{Value 1}:...",
+// where the endpoint prefix scopes the key to the endpoint, so it is purged with it, see
+// Endpoint.BackendCacheKeyPrefix, the backend id identifies the backend service, see backendCacheId, and the backend
+// path has the params filled without the balanced host, so all the hosts of the backend share the key.
+// The strategy headers are obtained from the backend request and the strategy values from the expressions evaluated
+// with the endpoint request, see EndpointCache.StrategyKey. If the part after the path is longer than
+// cacheKeyMaxSuffixLength, it is replaced by its SHA-256 hash.
+func (b *BackendCache) StrategyKey(endpointVO *Endpoint, requestVO *Request) string {
+	backendRequestVO := requestVO.CurrentBackendRequest()
+
+	// construímos o sufixo com a query da requisição do backend
+	var suffix string
+	if rawQuery := backendRequestVO.RawQuery(); helper.IsNotEmpty(rawQuery) {
+		suffix = "?" + rawQuery
+	}
+
+	// obtemos os valores dos headers e das expressões da estratégia
+	var strategyValues []string
+	for _, strategyHeader := range b.strategyHeaders {
+		strategyValues = append(strategyValues, backendRequestVO.Header().Get(strategyHeader))
+	}
+	if helper.IsNotEmpty(b.strategyKeyExpressions) {
+		query := normalizeCacheKeyQuery(requestVO.Query())
+		strategyValues = append(strategyValues, evalStrategyKey(b.strategyKeyExpressions, requestVO, query)...)
+	}
+	if helper.IsNotEmpty(strategyValues) {
+		suffix = fmt.Sprintf("%s:%s", suffix, strings.Join(strategyValues, ":"))
+	}
+
+	// retornamos a key construída sem o host balanceado
+	path := strings.TrimPrefix(backendRequestVO.Url(), backendRequestVO.Host())
+	return fmt.Sprintf("%s%s:%s:%s%s", endpointVO.BackendCacheKeyPrefix(), b.id, backendRequestVO.Method(), path,
+		hashCacheKeySuffix(suffix))
+}
+
+// backendCacheId returns the stable identity of the backend in the cache keys, the first 16 hex characters of the
+// SHA-256 hash of its sorted hosts, name, method and path, so the backend services with the same method and path never
+// share the cache keys, while the order of the hosts does not change the identity.
+func backendCacheId(backendDTO dto.Backend) string {
+	hosts := make([]string, len(backendDTO.Hosts))
+	copy(hosts, backendDTO.Hosts)
+	sort.Strings(hosts)
+
+	identity := fmt.Sprintf("%s|%s|%s|%s", strings.Join(hosts, ","), backendDTO.Name, backendDTO.Method,
+		backendDTO.Path)
+	sum := sha256.Sum256([]byte(identity))
+	return hex.EncodeToString(sum[:])[:16]
+}
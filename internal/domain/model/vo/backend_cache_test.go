@@ -0,0 +1,53 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testBackendCacheKey(backendDTO dto.Backend, balancedHost string) string {
+	endpointVO := newEndpoint(dto.Endpoint{Path: "/users/:id", Method: "GET"})
+	backendVO := newBackend(backendDTO)
+	requestVO := NewRequestByHttp(httptest.NewRequest("GET", "/users/1", nil))
+	requestVO = requestVO.Append(NewBackendRequest(&backendVO, balancedHost, requestVO))
+	return backendVO.Cache().StrategyKey(&endpointVO, requestVO)
+}
+
+func TestBackendCacheStrategyKey(t *testing.T) {
+	cacheDTO := &dto.BackendCache{Enabled: true, Duration: "1m"}
+	users := dto.Backend{Hosts: []string{"http://users-1", "http://users-2"}, Path: "/users/1", Method: "GET",
+		Cache: cacheDTO}
+	accounts := dto.Backend{Hosts: []string{"http://accounts"}, Path: "/users/1", Method: "GET", Cache: cacheDTO}
+
+	usersKey := testBackendCacheKey(users, "http://users-1")
+	// os backends de hosts diferentes com o mesmo método e path não compartilham a chave
+	if accountsKey := testBackendCacheKey(accounts, "http://accounts"); usersKey == accountsKey {
+		t.Fatalf("StrategyKey() = %q for both backends, want distinct keys", usersKey)
+	}
+	// os hosts balanceados do mesmo backend compartilham a chave, independente da ordem dos hosts
+	users.Hosts = []string{"http://users-2", "http://users-1"}
+	if otherHostKey := testBackendCacheKey(users, "http://users-2"); usersKey != otherHostKey {
+		t.Fatalf("StrategyKey() = %q, want %q for the other balanced host", otherHostKey, usersKey)
+	}
+	if strings.Contains(usersKey, "users-1") || !strings.HasSuffix(usersKey, ":GET:/users/1") {
+		t.Fatalf("StrategyKey() = %q, want the path without the balanced host", usersKey)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"github.com/GabrielHCataldo/go-errors/errors"
 	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/mapper"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
 	"github.com/clbanning/mxj/v2"
 	"github.com/tidwall/gjson"
@@ -80,6 +81,23 @@ func NewBody(contentType string, buffer *bytes.Buffer) *Body {
 // converting the errorResponseBody object to a Buffer using the helper.SimpleConvertToBuffer function.
 // Finally, it returns a pointer to the constructed Body instance.
 func newErrorBody(path string, err error) *Body {
+	return newModifierErrorBody(path, nil, err)
+}
+
+// newModifierErrorBody creates a new error body like newErrorBody, including the identification of the modifier
+// that failed, if it is not nil.
+func newModifierErrorBody(path string, modifier *errorModifier, err error) *Body {
+	return newErrorBodyWithDetails(path, modifier, nil, err)
+}
+
+// newSchemaErrorBody creates a new error body like newErrorBody, including the list of schema violations.
+func newSchemaErrorBody(path string, violations []SchemaViolation, err error) *Body {
+	return newErrorBodyWithDetails(path, nil, violations, err)
+}
+
+// newErrorBodyWithDetails creates a new error body like newErrorBody, including the identification of the modifier
+// that failed and the list of schema violations, if they are not empty.
+func newErrorBodyWithDetails(path string, modifier *errorModifier, violations []SchemaViolation, err error) *Body {
 	// obtemos o detalhe do erro usando a lib go-errors
 	detailsErr := errors.Details(err)
 	if helper.IsNil(detailsErr) {
@@ -88,11 +106,18 @@ func newErrorBody(path string, err error) *Body {
 
 	// com os detalhes, construímos o objeto de retorno padrão de erro da API Gateway
 	errResponseBody := errorResponseBody{
-		File:      detailsErr.GetFile(),
-		Line:      detailsErr.GetLine(),
-		Endpoint:  path,
-		Message:   detailsErr.GetMessage(),
-		Timestamp: time.Now(),
+		File:       detailsErr.GetFile(),
+		Line:       detailsErr.GetLine(),
+		Endpoint:   path,
+		Message:    detailsErr.GetMessage(),
+		Modifier:   modifier,
+		Violations: violations,
+		Timestamp:  time.Now(),
+	}
+	// caso seja um erro tipado do gateway, informamos o código e se pode ser tentado novamente
+	if gatewayErr := mapper.GatewayErrorOf(err); helper.IsNotNil(gatewayErr) {
+		errResponseBody.Code = gatewayErr.Code()
+		errResponseBody.Retryable = gatewayErr.Retryable()
 	}
 
 	// construímos o body com esse objeto
@@ -485,6 +510,40 @@ func (b *Body) Delete(key string) (*Body, error) {
 	}
 }
 
+// Patch applies the JSON Patch document (RFC 6902) to the Body instance, returning a new Body instance with the
+// patched value. The operations are applied atomically, if any operation fails, the error is returned and the
+// original Body instance remains unchanged. It is only supported on bodies with ContentTypeJson.
+func (b *Body) Patch(patch string) (*Body, error) {
+	if b.IsNotJson() {
+		return nil, errors.New("PATCH action is only supported on JSON body")
+	}
+	modifiedValue, err := applyJsonPatch(b.String(), patch)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return &Body{
+		contentType: b.contentType,
+		value:       helper.SimpleConvertToBuffer(modifiedValue),
+	}, nil
+}
+
+// Merge applies the JSON Merge Patch document (RFC 7396) to the Body instance, returning a new Body instance with
+// the merged value. If the patch is invalid, the error is returned and the original Body instance remains unchanged.
+// It is only supported on bodies with ContentTypeJson.
+func (b *Body) Merge(patch string) (*Body, error) {
+	if b.IsNotJson() {
+		return nil, errors.New("MERGE action is only supported on JSON body")
+	}
+	modifiedValue, err := applyJsonMergePatch(b.String(), patch)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return &Body{
+		contentType: b.contentType,
+		value:       helper.SimpleConvertToBuffer(modifiedValue),
+	}, nil
+}
+
 // String returns the string representation of the CacheBodyValue instance.
 // It calls the String method of the underlying bytes.Buffer type to get the string representation.
 func (c *CacheBodyValue) String() string {
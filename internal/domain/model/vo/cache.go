@@ -18,9 +18,11 @@ package vo
 
 import (
 	"fmt"
+	"github.com/GabrielHCataldo/go-errors/errors"
 	"github.com/GabrielHCataldo/go-helper/helper"
 	"github.com/GabrielHCataldo/go-logger/logger"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/consts"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
 	"net/http"
 	"strings"
@@ -35,6 +37,8 @@ type Cache struct {
 	// The `StrategyKey` method in the `Cache` struct extracts values from these modifyHeaders and includes them in the cache key.
 	// If no strategy values are found in the modifyHeaders, the cache key will be generated without them.
 	strategyHeaders []string
+	// strategyKey represents the eval expressions whose values build the cache key, see EndpointCache.StrategyKey.
+	strategyKey []string
 	// AllowStatusCode checks if the given status code is allowed based on the onlyIfStatusCodes field in the Cache struct.
 	// If the onlyIfStatusCodes field is empty or if the given status code is present in the onlyIfStatusCodes field, it returns true,
 	// indicating that the status code is allowed. Otherwise, it returns false.
@@ -46,6 +50,18 @@ type Cache struct {
 	// allowCacheControl represents a pointer to a boolean indicating whether the cache should
 	// honor the Cache-Control header
 	allowCacheControl *bool
+	// originTTL represents a pointer to a boolean indicating whether the cache duration is derived from the
+	// Cache-Control max-age/s-maxage or the Expires headers of the response.
+	originTTL *bool
+	// staleWhileRevalidate represents the duration, after the cache expires, in which the stale response is served
+	// immediately while it is revalidated.
+	staleWhileRevalidate time.Duration
+	// staleIfError represents the duration, after the cache expires, in which the stale response is served if the
+	// request fails.
+	staleIfError time.Duration
+	// coalesceLock represents the duration of the lock key that coordinates the cache misses across the gateway
+	// instances.
+	coalesceLock time.Duration
 }
 
 // EndpointCache represents the cache configuration for an endpoint.
@@ -58,6 +74,10 @@ type EndpointCache struct {
 	duration time.Duration
 	// strategyHeaders represents a slice of strings for strategy modifyHeaders
 	strategyHeaders []string
+	// strategyKey represents the eval expressions whose values build the cache key, see StrategyKey.
+	strategyKey []string
+	// strategyKeyExpressions represents the parsed expressions of the strategyKey, nil on the malformed ones.
+	strategyKeyExpressions []*Expression
 	// onlyIfStatusCodes represents the status codes that the cache should be applied to.
 	onlyIfStatusCodes []int
 	// onlyIfMethods is a field in the Cache struct that represents the list of request methods that are allowed for caching.
@@ -66,6 +86,18 @@ type EndpointCache struct {
 	onlyIfMethods []string
 	// allowCacheControl represents a boolean value indicating whether the cache control header is allowed for the endpoint cache.
 	allowCacheControl *bool
+	// originTTL represents a boolean value indicating whether the cache duration is derived from the response headers,
+	// see TTL.
+	originTTL *bool
+	// staleWhileRevalidate represents the duration, after the cache expires, in which the stale response is served
+	// immediately while it is revalidated.
+	staleWhileRevalidate time.Duration
+	// staleIfError represents the duration, after the cache expires, in which the stale response is served if the
+	// request fails.
+	staleIfError time.Duration
+	// coalesceLock represents the duration of the lock key that coordinates the cache misses across the gateway
+	// instances.
+	coalesceLock time.Duration
 }
 
 // newEndpointCache creates a new instance of EndpointCache based on the provided Cache and EndpointCache.
@@ -77,6 +109,7 @@ type EndpointCache struct {
 // The onlyIfStatusCodes field set based on the value of cacheVO or endpointCacheVO.
 // The onlyIfMethods field set based on the value of cacheVO.
 // The allowCacheControl field set based on the value of cacheVO or endpointCacheVO.
+// The originTTL field set based on the value of cacheVO or endpointCacheVO.
 func newEndpointCache(cacheVO *Cache, endpointCacheVO *EndpointCache) *EndpointCache {
 	// se os dois cache VO estiver nil retornamos nil
 	if helper.IsNil(cacheVO) && helper.IsNil(endpointCacheVO) {
@@ -88,17 +121,27 @@ func newEndpointCache(cacheVO *Cache, endpointCacheVO *EndpointCache) *EndpointC
 	var ignoreQuery bool
 	var duration time.Duration
 	var strategyHeaders []string
+	var strategyKey []string
 	var onlyIfStatusCodes []int
 	var onlyIfMethods []string
 	var allowCacheControl *bool
+	var originTTL *bool
+	var staleWhileRevalidate time.Duration
+	var staleIfError time.Duration
+	var coalesceLock time.Duration
 
 	// caso seja informado na raiz
 	if helper.IsNotNil(cacheVO) {
 		duration = cacheVO.Duration()
 		strategyHeaders = cacheVO.StrategyHeaders()
+		strategyKey = cacheVO.StrategyKeyExpressions()
 		onlyIfStatusCodes = cacheVO.OnlyIfStatusCodes()
 		onlyIfMethods = cacheVO.OnlyIfMethods()
 		allowCacheControl = cacheVO.AllowCacheControl()
+		originTTL = cacheVO.OriginTTL()
+		staleWhileRevalidate = cacheVO.StaleWhileRevalidate()
+		staleIfError = cacheVO.StaleIfError()
+		coalesceLock = cacheVO.CoalesceLock()
 	}
 
 	// caso seja informado no endpoint, damos prioridade
@@ -111,23 +154,44 @@ func newEndpointCache(cacheVO *Cache, endpointCacheVO *EndpointCache) *EndpointC
 		if endpointCacheVO.HasStrategyHeaders() {
 			strategyHeaders = endpointCacheVO.StrategyHeaders()
 		}
+		if endpointCacheVO.HasStrategyKeyExpressions() {
+			strategyKey = endpointCacheVO.StrategyKeyExpressions()
+		}
 		if endpointCacheVO.HasAllowCacheControl() {
 			allowCacheControl = endpointCacheVO.AllowCacheControl()
 		}
+		if endpointCacheVO.HasOriginTTL() {
+			originTTL = endpointCacheVO.OriginTTL()
+		}
 		if endpointCacheVO.HasOnlyIfStatusCodes() {
 			onlyIfStatusCodes = endpointCacheVO.OnlyIfStatusCodes()
 		}
+		if helper.IsGreaterThan(endpointCacheVO.staleWhileRevalidate, 0) {
+			staleWhileRevalidate = endpointCacheVO.staleWhileRevalidate
+		}
+		if helper.IsGreaterThan(endpointCacheVO.staleIfError, 0) {
+			staleIfError = endpointCacheVO.staleIfError
+		}
+		if helper.IsGreaterThan(endpointCacheVO.coalesceLock, 0) {
+			coalesceLock = endpointCacheVO.coalesceLock
+		}
 	}
 
 	// construímos o objeto vo com os valores padrões ou informados no json
 	return &EndpointCache{
-		enabled:           enabled,
-		duration:          duration,
-		ignoreQuery:       ignoreQuery,
-		strategyHeaders:   strategyHeaders,
-		onlyIfStatusCodes: onlyIfStatusCodes,
-		onlyIfMethods:     onlyIfMethods,
-		allowCacheControl: allowCacheControl,
+		enabled:                enabled,
+		duration:               duration,
+		ignoreQuery:            ignoreQuery,
+		strategyHeaders:        strategyHeaders,
+		strategyKey:            strategyKey,
+		strategyKeyExpressions: parseStrategyKey(strategyKey),
+		onlyIfStatusCodes:      onlyIfStatusCodes,
+		onlyIfMethods:          onlyIfMethods,
+		allowCacheControl:      allowCacheControl,
+		originTTL:              originTTL,
+		staleWhileRevalidate:   staleWhileRevalidate,
+		staleIfError:           staleIfError,
+		coalesceLock:           coalesceLock,
 	}
 }
 
@@ -141,6 +205,7 @@ func newEndpointCache(cacheVO *Cache, endpointCacheVO *EndpointCache) *EndpointC
 // The onlyIfStatusCodes field is set based on the value of dto.Cache.OnlyIfStatusCodes.
 // The onlyIfMethods field is set based on the value of dto.Cache.OnlyIfMethods.
 // The allowCacheControl field is set based on the value of dto.Cache.AllowCacheControl.
+// The originTTL field is set based on the value of dto.Cache.OriginTTL.
 // Returns a new instance of Cache.
 func newCacheFromDTO(cacheDTO *dto.Cache) *Cache {
 	if helper.IsNil(cacheDTO) {
@@ -156,11 +221,16 @@ func newCacheFromDTO(cacheDTO *dto.Cache) *Cache {
 		}
 	}
 	return &Cache{
-		duration:          duration,
-		strategyHeaders:   cacheDTO.StrategyHeaders,
-		onlyIfStatusCodes: cacheDTO.OnlyIfStatusCodes,
-		onlyIfMethods:     cacheDTO.OnlyIfMethods,
-		allowCacheControl: cacheDTO.AllowCacheControl,
+		duration:             duration,
+		strategyHeaders:      cacheDTO.StrategyHeaders,
+		strategyKey:          cacheDTO.StrategyKey,
+		onlyIfStatusCodes:    cacheDTO.OnlyIfStatusCodes,
+		onlyIfMethods:        cacheDTO.OnlyIfMethods,
+		allowCacheControl:    cacheDTO.AllowCacheControl,
+		originTTL:            cacheDTO.OriginTTL,
+		staleWhileRevalidate: parseDuration("cache.stale-while-revalidate", cacheDTO.StaleWhileRevalidate),
+		staleIfError:         parseDuration("cache.stale-if-error", cacheDTO.StaleIfError),
+		coalesceLock:         parseDuration("cache.coalesce-lock", cacheDTO.CoalesceLock),
 	}
 }
 
@@ -184,11 +254,29 @@ func newEndpointCacheFromDTO(endpointCacheDTO *dto.EndpointCache) *EndpointCache
 		ignoreQuery:       endpointCacheDTO.IgnoreQuery,
 		duration:          duration,
 		strategyHeaders:   endpointCacheDTO.StrategyHeaders,
+		strategyKey:       endpointCacheDTO.StrategyKey,
 		onlyIfStatusCodes: endpointCacheDTO.OnlyIfStatusCodes,
 		allowCacheControl: endpointCacheDTO.AllowCacheControl,
+		originTTL:         endpointCacheDTO.OriginTTL,
+		staleWhileRevalidate: parseDuration("endpoint.cache.stale-while-revalidate",
+			endpointCacheDTO.StaleWhileRevalidate),
+		staleIfError: parseDuration("endpoint.cache.stale-if-error", endpointCacheDTO.StaleIfError),
+		coalesceLock: parseDuration("endpoint.cache.coalesce-lock", endpointCacheDTO.CoalesceLock),
 	}
 }
 
+// parseDuration parses the optional duration of the field, logging a warning and returning 0 if it is invalid.
+func parseDuration(field, value string) time.Duration {
+	if helper.IsEmpty(value) {
+		return 0
+	}
+	duration, err := time.ParseDuration(value)
+	if helper.IsNotNil(err) {
+		logger.Warning("Parse duration", field, "err:", err)
+	}
+	return duration
+}
+
 // Duration returns the value of the duration field in the Cache struct.
 // If the value is greater than zero, it returns the duration value.
 // Otherwise, it returns a default value of 1 minute.
@@ -204,6 +292,11 @@ func (c Cache) StrategyHeaders() []string {
 	return c.strategyHeaders
 }
 
+// StrategyKeyExpressions returns the eval expressions whose values build the cache key.
+func (c Cache) StrategyKeyExpressions() []string {
+	return c.strategyKey
+}
+
 // OnlyIfStatusCodes returns the list of status codes that are allowed for caching.
 // If the onlyIfStatusCodes field is not empty, it returns the list of status codes specified in it.
 // Otherwise, it returns an empty list.
@@ -225,6 +318,53 @@ func (c Cache) AllowCacheControl() *bool {
 	return c.allowCacheControl
 }
 
+// OriginTTL returns whether the cache duration is derived from the response headers, nil if not configured.
+func (c Cache) OriginTTL() *bool {
+	return c.originTTL
+}
+
+// StaleWhileRevalidate returns the duration, after the cache expires, in which the stale response is served
+// immediately while it is revalidated, 0 if not configured.
+func (c Cache) StaleWhileRevalidate() time.Duration {
+	return c.staleWhileRevalidate
+}
+
+// StaleIfError returns the duration, after the cache expires, in which the stale response is served if the request
+// fails, 0 if not configured.
+func (c Cache) StaleIfError() time.Duration {
+	return c.staleIfError
+}
+
+// StaleWhileRevalidateStr returns the stale-while-revalidate duration as a string, empty if not configured.
+func (c Cache) StaleWhileRevalidateStr() string {
+	if helper.IsEmpty(c.staleWhileRevalidate) {
+		return ""
+	}
+	return c.staleWhileRevalidate.String()
+}
+
+// StaleIfErrorStr returns the stale-if-error duration as a string, empty if not configured.
+func (c Cache) StaleIfErrorStr() string {
+	if helper.IsEmpty(c.staleIfError) {
+		return ""
+	}
+	return c.staleIfError.String()
+}
+
+// CoalesceLock returns the duration of the lock key that coordinates the cache misses across the gateway instances,
+// 0 if not configured.
+func (c Cache) CoalesceLock() time.Duration {
+	return c.coalesceLock
+}
+
+// CoalesceLockStr returns the coalesce-lock duration as a string, empty if not configured.
+func (c Cache) CoalesceLockStr() string {
+	if helper.IsEmpty(c.coalesceLock) {
+		return ""
+	}
+	return c.coalesceLock.String()
+}
+
 // Enabled returns the value of the enabled field in the EndpointCache struct.
 // It returns the boolean value indicating whether the endpoint cache is enabled or not.
 func (e EndpointCache) Enabled() bool {
@@ -261,6 +401,81 @@ func (e EndpointCache) DurationStr() string {
 	return e.duration.String()
 }
 
+// StaleWhileRevalidate returns the duration, after the cache expires, in which the stale response is served
+// immediately while it is revalidated, 0 if not configured.
+func (e EndpointCache) StaleWhileRevalidate() time.Duration {
+	return e.staleWhileRevalidate
+}
+
+// StaleIfError returns the duration, after the cache expires, in which the stale response is served if the request
+// fails, 0 if not configured.
+func (e EndpointCache) StaleIfError() time.Duration {
+	return e.staleIfError
+}
+
+// StaleWhileRevalidateStr returns the stale-while-revalidate duration as a string, empty if not configured.
+func (e EndpointCache) StaleWhileRevalidateStr() string {
+	if helper.IsEmpty(e.staleWhileRevalidate) {
+		return ""
+	}
+	return e.staleWhileRevalidate.String()
+}
+
+// StaleIfErrorStr returns the stale-if-error duration as a string, empty if not configured.
+func (e EndpointCache) StaleIfErrorStr() string {
+	if helper.IsEmpty(e.staleIfError) {
+		return ""
+	}
+	return e.staleIfError.String()
+}
+
+// CoalesceLock returns the duration of the lock key that coordinates the cache misses across the gateway instances,
+// 0 if not configured.
+func (e EndpointCache) CoalesceLock() time.Duration {
+	return e.coalesceLock
+}
+
+// CoalesceLockStr returns the coalesce-lock duration as a string, empty if not configured.
+func (e EndpointCache) CoalesceLockStr() string {
+	if helper.IsEmpty(e.coalesceLock) {
+		return ""
+	}
+	return e.coalesceLock.String()
+}
+
+// HasCoalesceLock returns whether the cache misses are coordinated across the gateway instances with a lock key.
+func (e EndpointCache) HasCoalesceLock() bool {
+	return helper.IsGreaterThan(e.coalesceLock, 0)
+}
+
+// TTL returns the duration in which the response is fresh on the cache. If the origin TTL is enabled, it is derived
+// from the Cache-Control s-maxage or max-age directives of the response, minus its Age header, or from the Expires
+// header, see originTTL. Otherwise, or if the response has none of them, the duration of the cache is returned.
+func (e EndpointCache) TTL(responseVO *Response) time.Duration {
+	if e.IsOriginTTL() {
+		if ttl, ok := originTTL(responseVO.Header()); ok {
+			return ttl
+		}
+	}
+	return e.duration
+}
+
+// StoreDuration returns the duration the response is kept on the cache store, the TTL of the response plus the
+// largest stale duration, so the stale response is still available after the cache expires.
+func (e EndpointCache) StoreDuration(ttl time.Duration) time.Duration {
+	return ttl + max(e.staleWhileRevalidate, e.staleIfError)
+}
+
+// CanServeStaleWhileRevalidate returns whether the expired cache response can be served while it is revalidated.
+func (e EndpointCache) CanServeStaleWhileRevalidate(cacheResponseVO *CacheResponse) bool {
+	return helper.IsGreaterThan(e.staleWhileRevalidate, 0) && cacheResponseVO.StaleWithin(e.staleWhileRevalidate)
+}
+
+// CanServeStaleIfError returns whether the expired cache response can be served if the request fails.
+func (e EndpointCache) CanServeStaleIfError(cacheResponseVO *CacheResponse) bool {
+	return helper.IsGreaterThan(e.staleIfError, 0) && cacheResponseVO.StaleWithin(e.staleIfError)
+}
+
 // HasStrategyHeaders returns a boolean value indicating whether the `strategyHeaders` field in the EndpointCache.
 // struct is not nil.
 func (e EndpointCache) HasStrategyHeaders() bool {
@@ -272,6 +487,27 @@ func (e EndpointCache) StrategyHeaders() []string {
 	return e.strategyHeaders
 }
 
+// HasStrategyKeyExpressions returns a boolean value indicating whether the `strategyKey` field in the EndpointCache
+// struct is not nil.
+func (e EndpointCache) HasStrategyKeyExpressions() bool {
+	return helper.IsNotNil(e.strategyKey)
+}
+
+// StrategyKeyExpressions returns the eval expressions whose values build the cache key.
+func (e EndpointCache) StrategyKeyExpressions() []string {
+	return e.strategyKey
+}
+
+// Validate validates the expressions of the strategy key, returning the error of the first malformed expression.
+func (e EndpointCache) Validate() error {
+	for index, raw := range e.strategyKey {
+		if _, err := newExpression(raw); helper.IsNotNil(err) {
+			return errors.New(fmt.Sprintf("strategy-key[%v]:", index), errors.Details(err).GetMessage())
+		}
+	}
+	return nil
+}
+
 // HasAllowCacheControl returns a boolean value indicating whether the `allowCacheControl` field in the EndpointCache struct
 // is not nil. If the field is not nil, it means that the cache control header is allowed for the endpoint cache, and the
 // function returns true. Otherwise, it returns false.
@@ -284,6 +520,21 @@ func (e EndpointCache) AllowCacheControl() *bool {
 	return e.allowCacheControl
 }
 
+// HasOriginTTL returns a boolean value indicating whether the `originTTL` field in the EndpointCache struct is not nil.
+func (e EndpointCache) HasOriginTTL() bool {
+	return helper.IsNotNil(e.originTTL)
+}
+
+// OriginTTL returns the value of the originTTL field in the EndpointCache struct.
+func (e EndpointCache) OriginTTL() *bool {
+	return e.originTTL
+}
+
+// IsOriginTTL returns whether the cache duration is derived from the response headers, see TTL.
+func (e EndpointCache) IsOriginTTL() bool {
+	return helper.IsNotNil(e.originTTL) && *e.originTTL
+}
+
 // HasOnlyIfStatusCodes returns a boolean value indicating whether the `onlyIfStatusCodes` field in the EndpointCache struct
 // is not nil. If the field is not nil, it means that the cache should only be applied to the specified status codes, and the
 // function returns true. Otherwise, it returns false.
@@ -313,16 +564,16 @@ func (e EndpointCache) CanRead(requestVO *Request) bool {
 		return false
 	}
 
-	// obtemos o cache control enum do ctx de requisição
-	cacheControl := e.CacheControlEnum(requestVO.Header())
-
 	// verificamos se no Cache-Control enviado veio como "no-cache" e se o método da requisição contains no campo
 	// de permissão, ou esse campo esteja vazio
-	return helper.IsNotEqualTo(enum.CacheControlNoCache, cacheControl) && e.AllowMethod(requestVO.Method())
+	return !e.HasCacheControl(requestVO.Header(), enum.CacheControlNoCache) && e.AllowMethod(requestVO.Method())
 }
 
-// CanWrite checks if the cache is active and if the Cache-Control header in the response allows caching.
-// It also checks if the request method and response status code are allowed for caching.
+// CanWrite checks if the cache is active and if the Cache-Control header in the response allows caching, without the
+// "no-store" and "private" directives, as the cache is shared by the clients. The directives are honored if the cache
+// honors the Cache-Control header or derives the duration from it, see HasCacheControl and IsOriginTTL.
+// It also checks if the request method and response status code are allowed for caching, if the response was not
+// obtained from the cache and if its TTL is greater than 0.
 // It returns true if caching is allowed, false otherwise.
 func (e EndpointCache) CanWrite(requestVO *Request, responseVO *Response) bool {
 	// verificamos se ta ativo
@@ -330,24 +581,35 @@ func (e EndpointCache) CanWrite(requestVO *Request, responseVO *Response) bool {
 		return false
 	}
 
-	// obtemos o cache control enum do ctx de requisição
-	cacheControl := e.CacheControlEnum(responseVO.Header())
+	// verificamos se a resposta foi obtida do cache
+	if helper.Equals(responseVO.Header().Get(consts.XGopenCache), string(enum.CacheStatusStale)) ||
+		helper.Equals(responseVO.Header().Get(consts.XGopenCache), string(enum.CacheStatusHit)) {
+		return false
+	}
+
+	// verificamos se no Cache-Control enviado veio como "no-store" ou "private"
+	if e.HasCacheControl(responseVO.Header(), enum.CacheControlNoStore) ||
+		e.HasCacheControl(responseVO.Header(), enum.CacheControlPrivate) {
+		return false
+	} else if e.IsOriginTTL() && hasCacheControlDirective(responseVO.Header(), enum.CacheControlNoStore,
+		enum.CacheControlPrivate) {
+		return false
+	}
 
-	// verificamos se no Cache-Control enviado veio como "no-store" e se o método da requisição contains no campo
-	// de permissão, também verificamos o código de
-	return helper.IsNotEqualTo(enum.CacheControlNoStore, cacheControl) && e.AllowMethod(requestVO.Method()) &&
-		e.AllowStatusCode(responseVO.StatusCode())
+	// verificamos se o método da requisição contains no campo de permissão, o código de status e a duração
+	return e.AllowMethod(requestVO.Method()) && e.AllowStatusCode(responseVO.StatusCode()) &&
+		helper.IsGreaterThan(e.TTL(responseVO), 0)
 }
 
-// CacheControlEnum takes a Header and returns the CacheControl enum value.
-// If caching is allowed and the Cache-Control header is present, it tries to parse the value and convert it
-func (e EndpointCache) CacheControlEnum(header Header) (cacheControl enum.CacheControl) {
-	// caso esteja permitido o cache control obtemos do header
-	if helper.IsNotNil(e.allowCacheControl) && *e.allowCacheControl {
-		cacheControl = enum.CacheControl(header.Get("Cache-Control"))
+// HasCacheControl returns whether the Cache-Control header has the directive, if the cache honors the Cache-Control
+// header, see AllowCacheControl. The directives of all the Cache-Control values are considered, as the responses of
+// the backends are aggregated.
+func (e EndpointCache) HasCacheControl(header Header, cacheControl enum.CacheControl) bool {
+	// caso não esteja permitido o cache control, ignoramos o header
+	if helper.IsNil(e.allowCacheControl) || !*e.allowCacheControl {
+		return false
 	}
-	// retornamos a enum do cache control vazia ou não, dependendo da configuração
-	return cacheControl
+	return hasCacheControlDirective(header, cacheControl)
 }
 
 // StrategyKey generates a cache key based on the request information and strategy headers.
@@ -355,8 +617,15 @@ func (e EndpointCache) CacheControlEnum(header Header) (cacheControl enum.CacheC
 // The generated key follows the pattern: "{HTTP Method}:{Request URL}:{Strategy Value 1}:{Strategy Value 2}:..."
 // The Strategy Value is obtained from the request headers specified in the strategyHeaders field of the Cache struct.
 // If no Strategy Value is found, the key will be generated without it.
+// If the strategy key expressions are configured, the key is generated by them followed by the strategy headers
+// values instead, see expressionStrategyKey.
 // The final key is returned as a string.
 func (e EndpointCache) StrategyKey(requestVO *Request) string {
+	// caso tenha as expressões configuradas, a chave é construída por elas
+	if e.HasStrategyKeyExpressions() {
+		return e.expressionStrategyKey(requestVO)
+	}
+
 	// inicializamos a url da requisição completa
 	url := requestVO.Url()
 	// caso o cache queira ignorar as queries, ele ignora
@@ -387,6 +656,30 @@ func (e EndpointCache) StrategyKey(requestVO *Request) string {
 	return key
 }
 
+// CacheLockKey returns the lock key of the cache key, used to coordinate the cache misses across the gateway instances.
+func CacheLockKey(key string) string {
+	return "lock:" + key
+}
+
+// CacheKeyPatternByPrefix returns the glob pattern matching the cache keys starting with the prefix, escaping the
+// special characters of the prefix.
+func CacheKeyPatternByPrefix(prefix string) string {
+	return EscapeGlob(prefix) + "*"
+}
+
+// EscapeGlob escapes the special characters of the glob patterns, `*`, `?`, `[`, `]` and `\`, so the value is matched
+// literally.
+func EscapeGlob(value string) string {
+	var builder strings.Builder
+	for _, char := range value {
+		if strings.ContainsRune(`*?[]\`, char) {
+			builder.WriteRune('\\')
+		}
+		builder.WriteRune(char)
+	}
+	return builder.String()
+}
+
 // AllowMethod checks if the given method is allowed in the EndpointCache.
 // If the onlyIfMethods field is nil, it allows all methods.
 // If onlyIfMethods is empty and the method is GET, it allows the method.
@@ -0,0 +1,232 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/tidwall/gjson"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// cacheKeyMaxSuffixLength represents the maximum length of the part of the cache key after the path, the query and
+// the strategy values, longer suffixes are replaced by their SHA-256 hash.
+const cacheKeyMaxSuffixLength = 256
+
+// cacheKeyTrackingQueryRegex matches the tracking query params, ignored on the cache keys built by expressions.
+var cacheKeyTrackingQueryRegex = regexp.MustCompile(`(?i)^(utm_.*|gclid|fbclid|msclkid|mc_cid|mc_eid|_ga)$`)
+
+// parseStrategyKey parses the eval expressions of the strategy key, keeping nil on the malformed ones, which are
+// reported by EndpointCache.Validate.
+func parseStrategyKey(strategyKey []string) []*Expression {
+	if helper.IsNil(strategyKey) {
+		return nil
+	}
+	expressions := make([]*Expression, len(strategyKey))
+	for i, raw := range strategyKey {
+		expressions[i], _ = newExpression(raw)
+	}
+	return expressions
+}
+
+// expressionStrategyKey generates the cache key with the values of the strategy key expressions.
+// The generated key follows the pattern:
+// "{HTTP Method}:{Request Path}?{Normalized Query}:{Value 1}:{Value 2}:...:{Strategy Header 1}:...", where the
+// normalized query is sorted, with the names in lowercase and without the tracking params, like utm_*, and is omitted
+// if IgnoreQuery is enabled. The values are trimmed, keeping their case, and the malformed expressions and the
+// missing headers result in empty values, so the position of the values is kept. If the part after the path is
+// longer than cacheKeyMaxSuffixLength, it is replaced by its SHA-256 hash.
+func (e EndpointCache) expressionStrategyKey(requestVO *Request) string {
+	// normalizamos a query
+	query := normalizeCacheKeyQuery(requestVO.Query())
+
+	// construímos o sufixo com a query normalizada
+	var suffix string
+	if !e.IgnoreQuery() && helper.IsNotEmpty(query) {
+		suffix = "?" + query.Encode()
+	}
+
+	// avaliamos as expressões com os valores da requisição
+	values := evalStrategyKey(e.strategyKeyExpressions, requestVO, query)
+	// os headers da estratégia também compõem a chave
+	for _, strategyHeader := range e.strategyHeaders {
+		values = append(values, requestVO.Header().Get(strategyHeader))
+	}
+	suffix = fmt.Sprintf("%s:%s", suffix, strings.Join(values, ":"))
+
+	// retornamos a key construída
+	return fmt.Sprintf("%s:%s%s", requestVO.Method(), requestVO.Path(), hashCacheKeySuffix(suffix))
+}
+
+// evalStrategyKey evaluates the strategy key expressions with the values of the request, see cacheKeyEvalFunc,
+// returning the values trimmed, keeping their case, and empty on the malformed expressions. If the claims of the
+// bearer token are evaluated, the SHA-256 hash of the whole token is added after the values, as the signature is not
+// verified, so a forged token carrying the claims of another token never obtains its cache key.
+func evalStrategyKey(expressions []*Expression, requestVO *Request, query Query) []string {
+	var jwtEvaluated bool
+	evalFunc := cacheKeyEvalFunc(requestVO, query, &jwtEvaluated)
+	values := make([]string, len(expressions))
+	for i, expression := range expressions {
+		if helper.IsNil(expression) {
+			continue
+		}
+		values[i] = strings.TrimSpace(expressionString(expression.Evaluate(evalFunc)))
+	}
+	if jwtEvaluated {
+		values = append(values, bearerTokenHash(requestVO.Header().Get("Authorization")))
+	}
+	return values
+}
+
+// hashCacheKeySuffix returns the suffix of the cache key, or its SHA-256 hash if it is longer than
+// cacheKeyMaxSuffixLength.
+func hashCacheKeySuffix(suffix string) string {
+	if helper.IsLessThanOrEqual(len(suffix), cacheKeyMaxSuffixLength) {
+		return suffix
+	}
+	sum := sha256.Sum256([]byte(suffix))
+	return ":" + hex.EncodeToString(sum[:])
+}
+
+// normalizeCacheKeyQuery returns a copy of the query with the names in lowercase and without the tracking params.
+func normalizeCacheKeyQuery(query Query) Query {
+	result := Query{}
+	for key, values := range query {
+		if cacheKeyTrackingQueryRegex.MatchString(key) {
+			continue
+		}
+		lowerKey := strings.ToLower(key)
+		result[lowerKey] = append(result[lowerKey], values...)
+	}
+	return result
+}
+
+// cacheKeyEvalFunc returns the expressionEvalFunc used by the strategy key expressions, where the eval words with the
+// prefix #request obtain the values of the request: header (by the name in any case), params, query (normalized, see
+// normalizeCacheKeyQuery), body, cookie (the cookies by name) and jwt (the claims of the bearer token, not verified,
+// informing jwtEvaluated). Any other eval word results in nil.
+func cacheKeyEvalFunc(requestVO *Request, query Query, jwtEvaluated *bool) expressionEvalFunc {
+	var evalJson string
+	return func(word string) any {
+		// limpamos a # e convertemos os índices no formato [0] para a sintaxe .0
+		eval := evalPath(word)
+		if !strings.HasPrefix(eval, "request.") {
+			return nil
+		}
+
+		// construímos os valores da requisição somente na primeira avaliação
+		if helper.IsEmpty(evalJson) {
+			evalJson = cacheKeyEval(requestVO, query)
+		}
+
+		path := lowerCacheKeyEvalName(strings.TrimPrefix(eval, "request."))
+		if strings.HasPrefix(path, "jwt.") || helper.Equals(path, "jwt") {
+			*jwtEvaluated = true
+		}
+
+		result := gjson.Get(evalJson, path)
+		if !result.Exists() {
+			return nil
+		}
+		return result.Value()
+	}
+}
+
+// cacheKeyEval returns the values of the request evaluated by the strategy key expressions as JSON.
+func cacheKeyEval(requestVO *Request, query Query) string {
+	var evalBody any
+	if helper.IsNotNil(requestVO.Body()) {
+		evalBody = requestVO.Body().Interface()
+	}
+
+	// os nomes dos headers são case-insensitive, os valores mantêm o case
+	header := map[string][]string{}
+	for key, values := range requestVO.Header() {
+		lowerKey := strings.ToLower(key)
+		header[lowerKey] = append(header[lowerKey], values...)
+	}
+
+	cookies := map[string]string{}
+	for _, cookie := range (&http.Request{Header: requestVO.Header().Http()}).Cookies() {
+		cookies[cookie.Name] = cookie.Value
+	}
+
+	mapEval := map[string]any{
+		"header": header,
+		"params": requestVO.Params(),
+		"query":  query,
+		"body":   evalBody,
+		"cookie": cookies,
+		"jwt":    jwtClaims(requestVO.Header().Get("Authorization")),
+	}
+	return helper.SimpleConvertToString(mapEval)
+}
+
+// lowerCacheKeyEvalName returns the eval path with the name of the header or of the query param in lowercase, as
+// they are case-insensitive, keeping the rest of the path.
+func lowerCacheKeyEvalName(path string) string {
+	for _, prefix := range []string{"header.", "query."} {
+		if name, ok := strings.CutPrefix(path, prefix); ok {
+			name, rest, found := strings.Cut(name, ".")
+			if found {
+				rest = "." + rest
+			}
+			return prefix + strings.ToLower(name) + rest
+		}
+	}
+	return path
+}
+
+// jwtClaims returns the claims of the payload of the bearer token in the Authorization header value, or nil if it is
+// not a JWT. The signature is NOT verified, so the claims can be forged by the client, the cache key built with them
+// always carries the hash of the whole token too, see evalStrategyKey.
+func jwtClaims(authorization string) map[string]any {
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok {
+		return nil
+	}
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if helper.IsNotEqualTo(len(parts), 3) {
+		return nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if helper.IsNotNil(err) {
+		return nil
+	}
+	var claims map[string]any
+	if err = json.Unmarshal(payload, &claims); helper.IsNotNil(err) {
+		return nil
+	}
+	return claims
+}
+
+// bearerTokenHash returns the SHA-256 hash of the bearer token in the Authorization header value, or empty if it is
+// not informed.
+func bearerTokenHash(authorization string) string {
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok || helper.IsEmpty(strings.TrimSpace(token)) {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
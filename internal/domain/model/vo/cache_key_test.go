@@ -0,0 +1,77 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"encoding/base64"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestCacheKeyRequest(header map[string]string) *Request {
+	httpRequest := httptest.NewRequest("GET", "/users?Page=1", nil)
+	for key, value := range header {
+		httpRequest.Header.Set(key, value)
+	}
+	return NewRequestByHttp(httpRequest)
+}
+
+func testJwt(payload string) string {
+	return "Bearer e30." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".signature"
+}
+
+func TestEndpointCacheStrategyKeyJwt(t *testing.T) {
+	endpointCacheVO := newEndpointCache(nil, newEndpointCacheFromDTO(&dto.EndpointCache{
+		StrategyKey: []string{"#request.jwt.sub"},
+	}))
+	original := endpointCacheVO.StrategyKey(newTestCacheKeyRequest(map[string]string{
+		"Authorization": testJwt(`{"sub":"alice","exp":1}`),
+	}))
+	// o token forjado com o mesmo sub não pode obter a chave do token original
+	forged := endpointCacheVO.StrategyKey(newTestCacheKeyRequest(map[string]string{
+		"Authorization": testJwt(`{"sub":"alice"}`),
+	}))
+	if original == forged {
+		t.Fatalf("StrategyKey() of forged token = %q, want a key other than the original", forged)
+	}
+}
+
+func TestEndpointCacheStrategyKeyCase(t *testing.T) {
+	endpointCacheVO := newEndpointCache(nil, newEndpointCacheFromDTO(&dto.EndpointCache{
+		StrategyKey: []string{"#request.header.x-api-key[0]", "#request.query.PAGE[0]"},
+	}))
+	lower := endpointCacheVO.StrategyKey(newTestCacheKeyRequest(map[string]string{"X-Api-Key": "abc"}))
+	upper := endpointCacheVO.StrategyKey(newTestCacheKeyRequest(map[string]string{"X-Api-Key": "ABC"}))
+	if lower == upper {
+		t.Fatalf("StrategyKey() = %q for values that differ by case, want distinct keys", lower)
+	}
+	if want := "GET:/users?page=1:abc:1"; lower != want {
+		t.Fatalf("StrategyKey() = %q, want %q", lower, want)
+	}
+}
+
+func TestEndpointCacheStrategyKeyWithHeaders(t *testing.T) {
+	endpointCacheVO := newEndpointCache(nil, newEndpointCacheFromDTO(&dto.EndpointCache{
+		StrategyKey:     []string{"#request.query.page[0]"},
+		StrategyHeaders: []string{"X-Tenant"},
+	}))
+	key := endpointCacheVO.StrategyKey(newTestCacheKeyRequest(map[string]string{"X-Tenant": "Acme"}))
+	if want := "GET:/users?page=1:1:Acme"; key != want {
+		t.Fatalf("StrategyKey() = %q, want %q", key, want)
+	}
+}
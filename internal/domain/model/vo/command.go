@@ -100,6 +100,30 @@ func NewExecuteResponseModifier(backendVO *Backend, requestVO *Request, response
 	}
 }
 
+// NewExecuteEndpointRequestModifier creates a new ExecuteModifier using the modifiers of the provided Endpoint, and the
+// Request and Response objects, in the context of enum.ModifierContextRequest.
+func NewExecuteEndpointRequestModifier(endpointVO *Endpoint, requestVO *Request, responseVO *Response,
+) *ExecuteModifier {
+	return &ExecuteModifier{
+		context:          enum.ModifierContextRequest,
+		backendModifiers: endpointVO.modifiers,
+		request:          requestVO,
+		response:         responseVO,
+	}
+}
+
+// NewExecuteEndpointResponseModifier creates a new ExecuteModifier using the modifiers of the provided Endpoint, and
+// the Request and Response objects, in the context of enum.ModifierContextResponse.
+func NewExecuteEndpointResponseModifier(endpointVO *Endpoint, requestVO *Request, responseVO *Response,
+) *ExecuteModifier {
+	return &ExecuteModifier{
+		context:          enum.ModifierContextResponse,
+		backendModifiers: endpointVO.modifiers,
+		request:          requestVO,
+		response:         responseVO,
+	}
+}
+
 // Gopen returns the Gopen object associated with the ExecuteEndpoint object.
 func (e ExecuteEndpoint) Gopen() *Gopen {
 	return e.gopen
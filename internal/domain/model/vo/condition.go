@@ -0,0 +1,69 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"strings"
+)
+
+// Condition represents a parsed boolean Expression used to decide if a modifier should be applied.
+//
+// Examples:
+//
+//	#response.statusCode == 404
+//	#request.header.X-Client[0] == 'mobile' && exists #request.body.id
+//	#response.body.email =~ '@gmail.com$' || !(#response.statusCode >= 400)
+type Condition struct {
+	// expression represents the parsed expression of the condition.
+	expression *Expression
+}
+
+// newCondition parses the provided raw expression and returns a new instance of Condition.
+// If the raw expression is empty, it returns nil and no error.
+// If the raw expression is malformed, it returns nil and the parse error.
+func newCondition(raw string) (*Condition, error) {
+	if helper.IsEmpty(strings.TrimSpace(raw)) {
+		return nil, nil
+	}
+	expression, err := newExpression(raw)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return &Condition{
+		expression: expression,
+	}, nil
+}
+
+// newFalseCondition returns a Condition with the provided raw expression that is always evaluated as false,
+// used when the configured expression is malformed.
+func newFalseCondition(raw string) *Condition {
+	return &Condition{
+		expression: &Expression{raw: raw, root: expressionLiteral{literal: false}},
+	}
+}
+
+// Expression returns the raw expression of the Condition.
+func (c *Condition) Expression() string {
+	return c.expression.Raw()
+}
+
+// Evaluate evaluates the condition using the provided expressionEvalFunc to obtain the eval values,
+// returning true if the result of the expression is truthy.
+func (c *Condition) Evaluate(evalFunc expressionEvalFunc) bool {
+	return expressionTruthy(c.expression.Evaluate(evalFunc))
+}
@@ -25,9 +25,16 @@ import (
 	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// statusCodeMappingKeyRegex matches the keys of the status code mapping, exact status codes or classes like "5xx".
+var statusCodeMappingKeyRegex = regexp.MustCompile(`^[1-5]([0-9]{2}|[xX]{2})$`)
+
 // Endpoint represents the configuration for an API endpoint in the Gopen application.
 type Endpoint struct {
 	// comment is a string field representing the comment associated with an API endpoint.
@@ -57,9 +64,22 @@ type Endpoint struct {
 	// aggregateResponses represents a boolean indicating whether the API endpoint should aggregate responses
 	// from multiple backends.
 	aggregateResponses bool
+	// aggregation represents the options of the aggregation of the backend responses, nil to collect the keys present
+	// on more than one backend response into an array.
+	aggregation *Aggregation
+	// errorFormat represents the format of the error responses generated by the gateway on the endpoint, filled with
+	// the global format by fillDefaultValues if not configured.
+	errorFormat *ErrorFormat
 	// abortIfStatusCodes represents a slice of integers representing the HTTP status codes
 	// for which the API endpoint should abort. It is a field in the Endpoint struct.
 	abortIfStatusCodes *[]int
+	// statusCodeStrategy represents how the status code is resolved when the endpoint has multiple backend responses.
+	statusCodeStrategy enum.StatusCodeStrategy
+	// statusCodeMapping represents the mapping of the resolved status code, by exact status code or class like "5xx".
+	statusCodeMapping map[string]int
+	// normalizedStatusCodeMapping represents the statusCodeMapping with the keys in lower case, like "5xx", used to
+	// look up the mapped status codes, see MapStatusCode.
+	normalizedStatusCodeMapping map[string]int
 	// beforeware represents a slice of strings containing the names of the beforeware middlewares that should be
 	// applied before processing the API endpoint.
 	beforeware []string
@@ -71,9 +91,24 @@ type Endpoint struct {
 	// transformation of the response or performing any additional actions.
 	// Afterware can be used for logging, error handling, response modification, etc.
 	afterware []string
+	// modifiers represents the modifiers of the endpoint itself, the modifiers with REQUEST context are executed before
+	// the first beforeware, and the modifiers with RESPONSE context are executed on the final aggregated response.
+	modifiers *BackendModifiers
+	// projection represents the fields projection applied to the body of each backend response of the endpoint.
+	projection *Projection
+	// redaction represents the names of the redaction rules applied to the final response of the endpoint.
+	redaction []string
+	// redactionRules represents the redaction rules resolved by name, filled by fillDefaultValues.
+	redactionRules RedactionRules
+	// requestSchema represents the JSON Schema that validates the client request before the modifiers and the
+	// beforeware.
+	requestSchema *RequestSchema
 	// Backends represents the backend configurations for an API endpoint in the Gopen application.
 	// It is a slice of Backend structs.
 	backends []Backend
+	// gatewayVersion represents the version of the Gopen configuration that the endpoint belongs to, filled by
+	// fillDefaultValues to be used in the `#gateway.version` eval syntax.
+	gatewayVersion string
 }
 
 // newEndpoint creates a new instance of Endpoint based on the provided endpointDTO.
@@ -95,18 +130,27 @@ func newEndpoint(endpointDTO dto.Endpoint) Endpoint {
 	}
 
 	return Endpoint{
-		comment:            endpointDTO.Comment,
-		path:               endpointDTO.Path,
-		method:             endpointDTO.Method,
-		timeout:            timeout,
-		limiter:            newEndpointLimiterFromDTO(endpointDTO.Limiter),
-		cache:              newEndpointCacheFromDTO(endpointDTO.Cache),
-		responseEncode:     endpointDTO.ResponseEncode,
-		aggregateResponses: endpointDTO.AggregateResponses,
-		abortIfStatusCodes: endpointDTO.AbortIfStatusCodes,
-		beforeware:         endpointDTO.Beforeware,
-		afterware:          endpointDTO.Afterware,
-		backends:           backends,
+		comment:                     endpointDTO.Comment,
+		path:                        endpointDTO.Path,
+		method:                      endpointDTO.Method,
+		timeout:                     timeout,
+		limiter:                     newEndpointLimiterFromDTO(endpointDTO.Limiter),
+		cache:                       newEndpointCacheFromDTO(endpointDTO.Cache),
+		responseEncode:              endpointDTO.ResponseEncode,
+		aggregateResponses:          endpointDTO.AggregateResponses,
+		aggregation:                 newAggregation(endpointDTO.Aggregation),
+		errorFormat:                 newErrorFormat(endpointDTO.ErrorFormat),
+		abortIfStatusCodes:          endpointDTO.AbortIfStatusCodes,
+		statusCodeStrategy:          endpointDTO.StatusCodeStrategy,
+		statusCodeMapping:           endpointDTO.StatusCodeMapping,
+		normalizedStatusCodeMapping: newNormalizedStatusCodeMapping(endpointDTO.StatusCodeMapping),
+		beforeware:                  endpointDTO.Beforeware,
+		afterware:                   endpointDTO.Afterware,
+		modifiers:                   newBackendModifier(endpointDTO.Modifiers),
+		projection:                  newProjection(endpointDTO.Projection),
+		redaction:                   endpointDTO.Redaction,
+		requestSchema:               newRequestSchema(endpointDTO.RequestSchema),
+		backends:                    backends,
 	}
 }
 
@@ -127,19 +171,36 @@ func (e *Endpoint) fillDefaultValues(gopenVO *Gopen) Endpoint {
 	// construímos o endpoint cache com os valores de configuração global
 	endpointCacheVO := newEndpointCache(gopenVO.Cache(), e.Cache())
 
+	// por padrão utilizamos o formato de erro global, caso o endpoint não tenha o seu
+	errorFormatVO := e.errorFormat
+	if helper.IsNil(errorFormatVO) {
+		errorFormatVO = gopenVO.ErrorFormat()
+	}
+
 	// construímos o VO com os valores padrões construídos a partir do Gopen e o próprio endpoint
 	return Endpoint{
-		path:               e.path,
-		method:             e.method,
-		timeout:            timeoutDuration,
-		limiter:            endpointLimiterVO,
-		cache:              endpointCacheVO,
-		responseEncode:     e.responseEncode,
-		aggregateResponses: e.aggregateResponses,
-		abortIfStatusCodes: e.abortIfStatusCodes,
-		beforeware:         e.beforeware,
-		afterware:          e.afterware,
-		backends:           e.backends,
+		path:                        e.path,
+		method:                      e.method,
+		timeout:                     timeoutDuration,
+		limiter:                     endpointLimiterVO,
+		cache:                       endpointCacheVO,
+		responseEncode:              e.responseEncode,
+		aggregateResponses:          e.aggregateResponses,
+		aggregation:                 e.aggregation,
+		errorFormat:                 errorFormatVO,
+		abortIfStatusCodes:          e.abortIfStatusCodes,
+		statusCodeStrategy:          e.statusCodeStrategy,
+		statusCodeMapping:           e.statusCodeMapping,
+		normalizedStatusCodeMapping: e.normalizedStatusCodeMapping,
+		beforeware:                  e.beforeware,
+		afterware:                   e.afterware,
+		modifiers:                   e.modifiers,
+		projection:                  e.projection,
+		redaction:                   e.redaction,
+		redactionRules:              gopenVO.Redaction().RulesByNames(e.redaction),
+		requestSchema:               e.requestSchema,
+		backends:                    e.backends,
+		gatewayVersion:              gopenVO.Version(),
 	}
 }
 
@@ -158,6 +219,11 @@ func (e *Endpoint) Method() string {
 	return e.method
 }
 
+// GatewayVersion returns the version of the Gopen configuration that the Endpoint belongs to.
+func (e *Endpoint) GatewayVersion() string {
+	return e.gatewayVersion
+}
+
 // Equals checks if the given route is equal to the Endpoint's path and method.
 // If the route is equal, it returns an error indicating a repeat route endpoint.
 func (e *Endpoint) Equals(route gin.RouteInfo) (err error) {
@@ -224,6 +290,31 @@ func (e *Endpoint) Afterware() []string {
 	return e.afterware
 }
 
+// Projection returns the fields projection applied to the body of each backend response, or nil if not configured.
+func (e *Endpoint) Projection() *Projection {
+	return e.projection
+}
+
+// Redaction returns the names of the redaction rules applied to the final response.
+func (e *Endpoint) Redaction() []string {
+	return e.redaction
+}
+
+// RedactionRules returns the redaction rules applied to the final response, resolved from the Gopen configuration.
+func (e *Endpoint) RedactionRules() RedactionRules {
+	return e.redactionRules
+}
+
+// RequestSchema returns the JSON Schema that validates the client request, or nil if not configured.
+func (e *Endpoint) RequestSchema() *RequestSchema {
+	return e.requestSchema
+}
+
+// Modifiers returns the modifiers of the endpoint itself.
+func (e *Endpoint) Modifiers() *BackendModifiers {
+	return e.modifiers
+}
+
 // CountAllBackends calculates the total number of beforeware, backends, and afterware in the Endpoint struct.
 // It returns the sum of the lengths of these slices.
 func (e *Endpoint) CountAllBackends() int {
@@ -257,12 +348,76 @@ func (e *Endpoint) CountBackends() int {
 // CountModifiers counts the total number of modifiers in an Endpoint by summing the count of modifiers in each
 // Backend associated with it.
 func (e *Endpoint) CountModifiers() (count int) {
+	if helper.IsNotNil(e.modifiers) {
+		count += e.modifiers.CountAll()
+	}
 	for _, backendDTO := range e.backends {
 		count += backendDTO.CountModifiers()
 	}
 	return count
 }
 
+// Validate validates the request schema and the modifiers of the Endpoint and of its backends, and the names of the
+// redaction rules against the redactionVO configured, returning the messages of the configurations that can never
+// be applied, prefixed by the location of the configuration.
+func (e *Endpoint) Validate(redactionVO *Redaction) (messages []string) {
+	if helper.IsNotNil(e.requestSchema) {
+		if err := e.requestSchema.Validate(); helper.IsNotNil(err) {
+			messages = append(messages, fmt.Sprintf("request-schema: %s", errors.Details(err).GetMessage()))
+		}
+	}
+	if helper.IsNotNil(e.errorFormat) {
+		if err := e.errorFormat.Validate(); helper.IsNotNil(err) {
+			messages = append(messages, fmt.Sprintf("error-format: %s", errors.Details(err).GetMessage()))
+		}
+	}
+	if helper.IsNotNil(e.cache) {
+		if err := e.cache.Validate(); helper.IsNotNil(err) {
+			messages = append(messages, fmt.Sprintf("cache.%s", errors.Details(err).GetMessage()))
+		}
+	}
+	if helper.IsNotNil(e.aggregation) {
+		if err := e.aggregation.Validate(); helper.IsNotNil(err) {
+			messages = append(messages, fmt.Sprintf("aggregation: %s", errors.Details(err).GetMessage()))
+		}
+	}
+	if err := redactionVO.ValidateNames(e.redaction); helper.IsNotNil(err) {
+		messages = append(messages, fmt.Sprintf("redaction: %s", errors.Details(err).GetMessage()))
+	}
+	if !e.StatusCodeStrategy().IsEnumValid() {
+		messages = append(messages, fmt.Sprintf("status-code-strategy: %s is invalid", e.statusCodeStrategy))
+	}
+	keys := make([]string, 0, len(e.statusCodeMapping))
+	for key := range e.statusCodeMapping {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	normalizedKeys := map[string]string{}
+	for _, key := range keys {
+		mapped := e.statusCodeMapping[key]
+		if !statusCodeMappingKeyRegex.MatchString(key) {
+			messages = append(messages, fmt.Sprintf("status-code-mapping: key %s is invalid", key))
+		} else if helper.IsLessThan(mapped, 100) || helper.IsGreaterThan(mapped, 599) {
+			messages = append(messages, fmt.Sprintf("status-code-mapping: %s value %v is invalid", key, mapped))
+		}
+		// as classes que diferem apenas pela caixa, como 5xx e 5XX, são ambíguas
+		if duplicated, ok := normalizedKeys[strings.ToLower(key)]; ok {
+			messages = append(messages, fmt.Sprintf("status-code-mapping: key %s duplicates the key %s", key,
+				duplicated))
+		}
+		normalizedKeys[strings.ToLower(key)] = key
+	}
+	if helper.IsNotNil(e.modifiers) {
+		messages = append(messages, e.modifiers.Validate()...)
+	}
+	for index, backendVO := range e.backends {
+		for _, message := range backendVO.Validate() {
+			messages = append(messages, fmt.Sprintf("backends[%v].%s", index, message))
+		}
+	}
+	return messages
+}
+
 // Completed checks if the response history size is equal to the count of all backends in the Endpoint struct.
 func (e *Endpoint) Completed(responseHistorySize int) bool {
 	return helper.Equals(responseHistorySize, e.CountAllBackends())
@@ -290,11 +445,94 @@ func (e *Endpoint) AggregateResponses() bool {
 	return e.aggregateResponses
 }
 
+// ErrorFormat returns the format of the error responses generated by the gateway on the endpoint, or nil if not
+// configured on the endpoint nor globally.
+func (e *Endpoint) ErrorFormat() *ErrorFormat {
+	return e.errorFormat
+}
+
+// Aggregation returns the options of the aggregation of the backend responses, or nil if not configured.
+func (e *Endpoint) Aggregation() *Aggregation {
+	return e.aggregation
+}
+
+// StatusCodeStrategy returns how the status code is resolved when the endpoint has multiple backend responses, if not
+// configured it returns enum.StatusCodeStrategyMostFrequent.
+func (e *Endpoint) StatusCodeStrategy() enum.StatusCodeStrategy {
+	if helper.IsNotEmpty(e.statusCodeStrategy) {
+		return e.statusCodeStrategy
+	}
+	return enum.StatusCodeStrategyMostFrequent
+}
+
+// StatusCodeMapping returns the mapping of the resolved status code, by exact status code or class like "5xx".
+func (e *Endpoint) StatusCodeMapping() map[string]int {
+	return e.statusCodeMapping
+}
+
+// MapStatusCode returns the status code mapped by the statusCodeMapping, first by the exact status code and then by
+// the class of the status code, like "5xx", in any case. If there is no mapping, the status code is returned unchanged.
+func (e *Endpoint) MapStatusCode(statusCode int) int {
+	if mapped, ok := e.normalizedStatusCodeMapping[strconv.Itoa(statusCode)]; ok {
+		return mapped
+	} else if mapped, ok = e.normalizedStatusCodeMapping[fmt.Sprintf("%vxx", statusCode/100)]; ok {
+		return mapped
+	}
+	return statusCode
+}
+
 // AbortIfStatusCodes returns the value of the abortIfStatusCodes field in the Endpoint struct.
 func (e *Endpoint) AbortIfStatusCodes() *[]int {
 	return e.abortIfStatusCodes
 }
 
+// CacheKeyPattern returns the glob pattern matching the cache keys of the endpoint, see EndpointCache.StrategyKey,
+// with the path params as wildcards. As the wildcards also match "/", the pattern may match the keys of other
+// endpoints with a longer path, so the keys must be filtered with CacheKeyRegex. The cache keys of the backends of the
+// endpoint are matched by BackendCacheKeyPattern.
+func (e *Endpoint) CacheKeyPattern() string {
+	segments := strings.Split(e.path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "*"
+		} else {
+			segments[i] = EscapeGlob(segment)
+		}
+	}
+	return fmt.Sprintf("%s:%s*", EscapeGlob(e.method), strings.Join(segments, "/"))
+}
+
+// BackendCacheKeyPrefix returns the prefix of the cache keys of the backends of the endpoint, see
+// BackendCache.StrategyKey, built with the method and the configured path of the endpoint.
+func (e *Endpoint) BackendCacheKeyPrefix() string {
+	return fmt.Sprintf("backend:%s:%s:", e.method, e.path)
+}
+
+// BackendCacheKeyPattern returns the glob pattern matching the cache keys of the backends of the endpoint, see
+// BackendCacheKeyPrefix.
+func (e *Endpoint) BackendCacheKeyPattern() string {
+	return CacheKeyPatternByPrefix(e.BackendCacheKeyPrefix())
+}
+
+// CacheKeyRegex returns the regex matching the cache keys built for the requests of the endpoint, see
+// EndpointCache.StrategyKey, with the path params of any value, the query optional and the strategy header values
+// optional, and the cache keys of its backends, see BackendCacheKeyPrefix. The regex is compiled on each call, so it
+// must be obtained once to filter the keys.
+func (e *Endpoint) CacheKeyRegex() *regexp.Regexp {
+	segments := strings.Split(e.path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = `[^/?:]+`
+		} else if strings.HasPrefix(segment, "*") {
+			segments[i] = `[^?:]*`
+		} else {
+			segments[i] = regexp.QuoteMeta(segment)
+		}
+	}
+	return regexp.MustCompile(fmt.Sprintf(`^(%s:%s(\?[^:]*)?(:.*)?|%s.*)$`, regexp.QuoteMeta(e.method),
+		strings.Join(segments, "/"), regexp.QuoteMeta(e.BackendCacheKeyPrefix())))
+}
+
 // Resume returns a string representation of the Endpoint, including information about the method,
 // path, the number of beforewares, afterwares, backends, and modifiers.
 // The format of the string is as follows:
@@ -303,3 +541,16 @@ func (e *Endpoint) Resume() string {
 	return fmt.Sprintf("%s --> \"%s\" [beforeware: %v afterware: %v backends: %v modifiers: %v]", e.method, e.path,
 		e.CountBeforewares(), e.CountAfterwares(), e.CountBackends(), e.CountModifiers())
 }
+
+// newNormalizedStatusCodeMapping returns the status code mapping with the keys in lower case, the keys that differ
+// only by case are rejected by Endpoint.Validate.
+func newNormalizedStatusCodeMapping(statusCodeMapping map[string]int) map[string]int {
+	if helper.IsNil(statusCodeMapping) {
+		return nil
+	}
+	normalized := make(map[string]int, len(statusCodeMapping))
+	for key, mapped := range statusCodeMapping {
+		normalized[strings.ToLower(key)] = mapped
+	}
+	return normalized
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestEndpointMapStatusCode(t *testing.T) {
+	endpointVO := newEndpoint(dto.Endpoint{StatusCodeMapping: map[string]int{"404": 200, "5XX": 502}})
+	tests := []struct {
+		statusCode int
+		want       int
+	}{
+		{http.StatusNotFound, http.StatusOK},
+		{http.StatusInternalServerError, http.StatusBadGateway},
+		{http.StatusServiceUnavailable, http.StatusBadGateway},
+		{http.StatusBadRequest, http.StatusBadRequest},
+		{http.StatusOK, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if got := endpointVO.MapStatusCode(tt.statusCode); got != tt.want {
+			t.Errorf("MapStatusCode(%d) = %d, want %d", tt.statusCode, got, tt.want)
+		}
+	}
+}
+
+func TestEndpointValidateStatusCodeMapping(t *testing.T) {
+	endpointVO := newEndpoint(dto.Endpoint{StatusCodeMapping: map[string]int{"5xx": 502, "5XX": 503, "6xx": 500}})
+	messages := strings.Join(endpointVO.Validate(nil), "\n")
+	if !strings.Contains(messages, "status-code-mapping: key 5xx duplicates the key 5XX") {
+		t.Errorf("Validate() = %q, want the case duplicated key", messages)
+	}
+	if !strings.Contains(messages, "status-code-mapping: key 6xx is invalid") {
+		t.Errorf("Validate() = %q, want the invalid key", messages)
+	}
+}
+
+func TestResponseHistoryStatusCode(t *testing.T) {
+	history := func(statusCodes ...int) responseHistory {
+		var result responseHistory
+		for _, statusCode := range statusCodes {
+			result = append(result, &backendResponse{statusCode: statusCode})
+		}
+		return result
+	}
+	tests := []struct {
+		name     string
+		strategy enum.StatusCodeStrategy
+		history  responseHistory
+		want     int
+	}{
+		{"empty", enum.StatusCodeStrategyPartial, nil, http.StatusNoContent},
+		{"partial with one success", enum.StatusCodeStrategyPartial, history(200, 500), http.StatusOK},
+		{"partial with single failed backend", enum.StatusCodeStrategyPartial, history(503), http.StatusServiceUnavailable},
+		{"partial with all failed", enum.StatusCodeStrategyPartial, history(404, 502), http.StatusBadGateway},
+		{"worst", enum.StatusCodeStrategyWorst, history(200, 500, 404), http.StatusInternalServerError},
+		{"first", enum.StatusCodeStrategyFirst, history(201, 500), http.StatusCreated},
+		{"last", enum.StatusCodeStrategyLast, history(201, 500), http.StatusInternalServerError},
+		{"most frequent tie by worst", enum.StatusCodeStrategyMostFrequent, history(200, 500),
+			http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.history.StatusCode(tt.strategy); got != tt.want {
+				t.Errorf("StatusCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEndpointCacheKeyRegex(t *testing.T) {
+	endpointVO := newEndpoint(dto.Endpoint{Path: "/users/:id", Method: "GET"})
+	cacheKeyRegex := endpointVO.CacheKeyRegex()
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"GET:/users/1", true},
+		{"GET:/users/1?page=1:tenant", true},
+		{endpointVO.BackendCacheKeyPrefix() + "0123456789abcdef:GET:/users/1", true},
+		{"GET:/users/1/orders", false},
+		{"backend:GET:/users/:id/orders:0123456789abcdef:GET:/orders", false},
+		{"POST:/users/1", false},
+	}
+	for _, tt := range tests {
+		if got := cacheKeyRegex.MatchString(tt.key); got != tt.want {
+			t.Errorf("CacheKeyRegex().MatchString(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
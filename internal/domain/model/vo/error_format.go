@@ -0,0 +1,331 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"github.com/GabrielHCataldo/go-errors/errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/consts"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ErrorFormat represents the format of the error responses generated by the gateway. The error body is built
+// internally with all the details, and formatted by this configuration just before it is written to the client.
+type ErrorFormat struct {
+	// format represents the format of the error body.
+	format enum.ErrorFormat
+	// problemType represents the URI of the "type" member of the problem details.
+	problemType string
+	// hideDetails indicates whether the internal details are hidden from the clients.
+	hideDetails bool
+	// sourceLocation indicates whether the source file and line are included in the problem details.
+	sourceLocation bool
+	// template represents a JSON object used as the error body, with `{{ expression }}` templates.
+	template map[string]any
+	// templates represents the parsed expressions of the templates by their raw expression, see parseTemplates.
+	templates map[string]*Expression
+	// templatesErr represents the error of the first malformed template, reported by Validate.
+	templatesErr error
+}
+
+// problemResponseBody represents the RFC 7807 problem details of the error responses generated by the gateway, with
+// the extension members of the gateway.
+type problemResponseBody struct {
+	// Type represents the URI that identifies the problem type.
+	Type string `json:"type"`
+	// Title represents the short summary of the problem type, the text of the status code.
+	Title string `json:"title"`
+	// Status represents the status code of the response.
+	Status int `json:"status"`
+	// Detail represents the explanation of the occurrence of the problem.
+	Detail string `json:"detail,omitempty"`
+	// Instance represents the occurrence of the problem, the trace id of the request.
+	Instance string `json:"instance,omitempty"`
+	// Code represents the stable code of the error, see mapper.GatewayError.
+	Code string `json:"code,omitempty"`
+	// Retryable indicates whether the request may succeed if it is retried.
+	Retryable bool `json:"retryable,omitempty"`
+	// Endpoint represents the endpoint path where the error occurred.
+	Endpoint string `json:"endpoint,omitempty"`
+	// Modifier represents the modifier that failed, only present on modifier errors.
+	Modifier any `json:"modifier,omitempty"`
+	// Violations represents the list of schema violations, only present on schema errors.
+	Violations any `json:"violations,omitempty"`
+	// File represents the file name or path where the error occurred.
+	File string `json:"file,omitempty"`
+	// Line represents the line number where the error occurred.
+	Line int `json:"line,omitempty"`
+	// Timestamp represents the timestamp when the error occurred.
+	Timestamp string `json:"timestamp,omitempty"`
+}
+
+// newErrorFormat creates a new instance of ErrorFormat based on the provided errorFormatDTO.
+// If the errorFormatDTO is nil, it returns nil.
+func newErrorFormat(errorFormatDTO *dto.ErrorFormat) *ErrorFormat {
+	if helper.IsNil(errorFormatDTO) {
+		return nil
+	}
+
+	// analisamos os templates uma única vez, os malformados são reportados pelo Validate
+	templates := map[string]*Expression{}
+	var templatesErr error
+	for _, value := range errorFormatTemplateStrings(errorFormatDTO.Template) {
+		expressions, err := parseTemplates(value)
+		if helper.IsNotNil(err) && helper.IsNil(templatesErr) {
+			templatesErr = errors.New("template", value, "is invalid:", errors.Details(err).GetMessage())
+		}
+		for raw, expression := range expressions {
+			templates[raw] = expression
+		}
+	}
+
+	return &ErrorFormat{
+		format:         errorFormatDTO.Format,
+		problemType:    errorFormatDTO.Type,
+		hideDetails:    errorFormatDTO.HideDetails,
+		sourceLocation: errorFormatDTO.SourceLocation,
+		template:       errorFormatDTO.Template,
+		templates:      templates,
+		templatesErr:   templatesErr,
+	}
+}
+
+// Format returns the format of the error body, if the instance is nil or not configured it returns
+// enum.ErrorFormatProblem.
+func (e *ErrorFormat) Format() enum.ErrorFormat {
+	if helper.IsNotNil(e) && helper.IsNotEmpty(e.format) {
+		return e.format
+	}
+	return enum.ErrorFormatProblem
+}
+
+// Type returns the URI of the "type" member of the problem details, if the instance is nil or not configured it
+// returns "about:blank".
+func (e *ErrorFormat) Type() string {
+	if helper.IsNotNil(e) && helper.IsNotEmpty(e.problemType) {
+		return e.problemType
+	}
+	return "about:blank"
+}
+
+// HideDetails returns whether the internal details are hidden from the clients, false if the instance is nil.
+func (e *ErrorFormat) HideDetails() bool {
+	return helper.IsNotNil(e) && e.hideDetails
+}
+
+// SourceLocation returns whether the source file and line where the error occurred are included in the problem
+// details, false if the instance is nil or the internal details are hidden, see HideDetails.
+func (e *ErrorFormat) SourceLocation() bool {
+	return helper.IsNotNil(e) && e.sourceLocation && !e.hideDetails
+}
+
+// Template returns the JSON object used as the error body, nil if the instance is nil or not configured.
+func (e *ErrorFormat) Template() map[string]any {
+	if helper.IsNil(e) {
+		return nil
+	}
+	return e.template
+}
+
+// ContentType returns the content type of the formatted error body, enum.ContentTypeProblemJson for the PROBLEM
+// format, and enum.ContentTypeJson otherwise.
+func (e *ErrorFormat) ContentType() enum.ContentType {
+	if helper.Equals(e.Format(), enum.ErrorFormatProblem) {
+		return enum.ContentTypeProblemJson
+	}
+	return enum.ContentTypeJson
+}
+
+// Validate checks the configuration of the error format, returning an error if the format is invalid or any
+// template of the template object can't be parsed.
+func (e *ErrorFormat) Validate() error {
+	if !e.Format().IsEnumValid() {
+		return errors.New("format", e.format, "is invalid")
+	}
+	return e.templatesErr
+}
+
+// Body formats the internal error body of the gateway, returning the new error body. The template, if configured,
+// is evaluated with the #error values, the members of the problem details, and the #request values.
+func (e *ErrorFormat) Body(statusCode int, body *Body, requestVO *Request) *Body {
+	// obtemos os detalhes do corpo de erro interno
+	var errorBody gjson.Result
+	if helper.IsNotNil(body) && body.IsJson() {
+		errorBody = gjson.Parse(body.String())
+	}
+
+	// montamos o corpo de erro no formato configurado
+	var bodyStr string
+	if helper.Equals(e.Format(), enum.ErrorFormatLegacy) && helper.IsEmpty(errorBody.Raw) {
+		return body
+	} else if helper.Equals(e.Format(), enum.ErrorFormatLegacy) {
+		bodyStr = e.legacy(statusCode, errorBody)
+	} else {
+		bodyStr = e.problem(statusCode, errorBody, requestVO)
+	}
+
+	// caso configurado, avaliamos o template com os valores do erro
+	if helper.IsNotEmpty(e.Template()) {
+		errorValue := gjson.Parse(bodyStr)
+		bodyStr = helper.SimpleConvertToString(e.evalTemplate(e.template, errorFormatEvalFunc(errorValue, requestVO)))
+	}
+	return &Body{
+		contentType: enum.ContentTypeJson,
+		value:       helper.SimpleConvertToBuffer(bodyStr),
+	}
+}
+
+// problem returns the RFC 7807 problem details of the internal error body. The source file and line are only
+// included if configured, see SourceLocation.
+func (e *ErrorFormat) problem(statusCode int, errorBody gjson.Result, requestVO *Request) string {
+	problem := problemResponseBody{
+		Type:       e.Type(),
+		Title:      http.StatusText(statusCode),
+		Status:     statusCode,
+		Detail:     errorBody.Get("message").String(),
+		Code:       errorBody.Get("code").String(),
+		Retryable:  errorBody.Get("retryable").Bool(),
+		Endpoint:   errorBody.Get("endpoint").String(),
+		Modifier:   errorBody.Get("modifier").Value(),
+		Violations: errorBody.Get("violations").Value(),
+		Timestamp:  errorBody.Get("timestamp").String(),
+	}
+	if helper.IsNotNil(requestVO) {
+		problem.Instance = requestVO.Header().Get(consts.XTraceId)
+	}
+	// caso configurado, incluímos a localização do erro no código fonte
+	if e.SourceLocation() {
+		problem.File = errorBody.Get("file").String()
+		problem.Line = int(errorBody.Get("line").Int())
+	}
+	// caso configurado, escondemos os detalhes internos
+	if e.HideDetails() {
+		problem.Modifier = nil
+		if helper.IsGreaterThanOrEqual(statusCode, http.StatusInternalServerError) {
+			problem.Detail = problem.Title
+		}
+	}
+	return helper.SimpleConvertToString(problem)
+}
+
+// legacy returns the internal error body, without the internal details if configured.
+func (e *ErrorFormat) legacy(statusCode int, errorBody gjson.Result) string {
+	bodyStr := errorBody.Raw
+	if !e.HideDetails() {
+		return bodyStr
+	}
+	for _, key := range []string{"file", "line", "modifier"} {
+		bodyStr, _ = sjson.Delete(bodyStr, key)
+	}
+	if helper.IsGreaterThanOrEqual(statusCode, http.StatusInternalServerError) {
+		bodyStr, _ = sjson.Set(bodyStr, "message", http.StatusText(statusCode))
+	}
+	return bodyStr
+}
+
+// errorFormatEvalFunc returns the expressionEvalFunc used by the error template, where the eval words with the prefix
+// #error obtain the values of the formatted error, and with the prefix #request the values of the request, any other
+// eval word results in nil.
+func errorFormatEvalFunc(errorValue gjson.Result, requestVO *Request) expressionEvalFunc {
+	return func(word string) any {
+		// limpamos a # e convertemos os índices no formato [0] para a sintaxe .0
+		eval := evalPath(word)
+		var result gjson.Result
+		if helper.Equals(eval, "error") {
+			return errorValue.Value()
+		} else if strings.HasPrefix(eval, "error.") {
+			result = errorValue.Get(strings.TrimPrefix(eval, "error."))
+		} else if strings.HasPrefix(eval, "request.") && helper.IsNotNil(requestVO) {
+			result = gjson.Get(requestVO.Eval(), strings.TrimPrefix(eval, "request."))
+		}
+		if !result.Exists() {
+			return nil
+		}
+		return result.Value()
+	}
+}
+
+// evalTemplate returns the value with the `{{ expression }}` templates of the strings evaluated, walking the nested
+// objects and arrays. A string composed by only one template results in the typed value of the expression.
+func (e *ErrorFormat) evalTemplate(value any, evalFunc expressionEvalFunc) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(typed))
+		for key, item := range typed {
+			result[key] = e.evalTemplate(item, evalFunc)
+		}
+		return result
+	case []any:
+		result := make([]any, len(typed))
+		for i, item := range typed {
+			result[i] = e.evalTemplate(item, evalFunc)
+		}
+		return result
+	case string:
+		templates := findAllTemplates(typed)
+		if helper.IsEmpty(templates) {
+			return typed
+		} else if isSingleTemplate(typed, templates) {
+			return e.evalExpression(typed[templates[0][2]:templates[0][3]], evalFunc)
+		}
+		var builder strings.Builder
+		last := 0
+		for _, template := range templates {
+			builder.WriteString(typed[last:template[0]])
+			builder.WriteString(expressionString(e.evalExpression(typed[template[2]:template[3]], evalFunc)))
+			last = template[1]
+		}
+		builder.WriteString(typed[last:])
+		return builder.String()
+	}
+	return value
+}
+
+// evalExpression evaluates the expression parsed from the raw expression, returning nil if it is malformed.
+func (e *ErrorFormat) evalExpression(raw string, evalFunc expressionEvalFunc) any {
+	expression, ok := e.templates[raw]
+	if !ok {
+		return nil
+	}
+	return expression.Evaluate(evalFunc)
+}
+
+// errorFormatTemplateStrings returns all the strings of the template, walking the nested objects and arrays, sorted
+// to validate always in the same order.
+func errorFormatTemplateStrings(value any) []string {
+	var values []string
+	switch typed := value.(type) {
+	case map[string]any:
+		for _, item := range typed {
+			values = append(values, errorFormatTemplateStrings(item)...)
+		}
+	case []any:
+		for _, item := range typed {
+			values = append(values, errorFormatTemplateStrings(item)...)
+		}
+	case string:
+		values = append(values, typed)
+	}
+	sort.Strings(values)
+	return values
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
+	"github.com/tidwall/gjson"
+	"net/http"
+	"testing"
+)
+
+func TestErrorFormatProblemSourceLocation(t *testing.T) {
+	errorBody := `{"file":"backend.go","line":42,"message":"backend unavailable","endpoint":"/users"}`
+	tests := []struct {
+		name        string
+		errorFormat *ErrorFormat
+		withSource  bool
+	}{
+		{name: "default", errorFormat: nil},
+		{name: "not configured", errorFormat: newErrorFormat(&dto.ErrorFormat{Format: enum.ErrorFormatProblem})},
+		{name: "enabled", errorFormat: newErrorFormat(&dto.ErrorFormat{SourceLocation: true}), withSource: true},
+		{name: "hide details", errorFormat: newErrorFormat(&dto.ErrorFormat{SourceLocation: true, HideDetails: true})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := gjson.Parse(tt.errorFormat.problem(http.StatusBadGateway, gjson.Parse(errorBody), nil))
+			if tt.withSource != result.Get("file").Exists() || tt.withSource != result.Get("line").Exists() {
+				t.Fatalf("problem() = %s, want source location %v", result.Raw, tt.withSource)
+			}
+			if result.Get("endpoint").String() != "/users" {
+				t.Errorf("problem() endpoint = %q, want %q", result.Get("endpoint").String(), "/users")
+			}
+		})
+	}
+}
+
+func TestErrorFormatTemplate(t *testing.T) {
+	errorFormatVO := newErrorFormat(&dto.ErrorFormat{Template: map[string]any{
+		"error":  "{{ #error.code }}",
+		"detail": "{{ upper(#error.detail) }} at {{ #error.endpoint }}",
+		"status": []any{"{{ #error.status }}"},
+	}})
+	if err := errorFormatVO.Validate(); err != nil {
+		t.Fatalf("Validate() err = %v", err)
+	}
+
+	errorBody := `{"code":"BAD_GATEWAY","message":"backend unavailable","endpoint":"/users"}`
+	body := errorFormatVO.Body(http.StatusBadGateway, &Body{contentType: enum.ContentTypeJson,
+		value: helper.SimpleConvertToBuffer(errorBody)}, nil)
+	result := gjson.Parse(body.String())
+	if result.Get("error").String() != "BAD_GATEWAY" || result.Get("status.0").Int() != http.StatusBadGateway ||
+		result.Get("detail").String() != "BACKEND UNAVAILABLE at /users" {
+		t.Fatalf("Body() = %s", result.Raw)
+	}
+}
+
+func TestErrorFormatValidateMalformedTemplate(t *testing.T) {
+	errorFormatVO := newErrorFormat(&dto.ErrorFormat{Template: map[string]any{"error": "{{ upper( }}"}})
+	if err := errorFormatVO.Validate(); err == nil {
+		t.Fatal("Validate() err = nil, want the malformed template")
+	}
+	// o template malformado resulta em nil, sem analisar novamente
+	body := errorFormatVO.Body(http.StatusBadGateway, &Body{contentType: enum.ContentTypeJson,
+		value: helper.SimpleConvertToBuffer(`{"message":"error"}`)}, nil)
+	if result := gjson.Parse(body.String()); result.Get("error").Exists() && result.Get("error").Type != gjson.Null {
+		t.Fatalf("Body() = %s, want error null", result.Raw)
+	}
+}
@@ -0,0 +1,606 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"fmt"
+	"github.com/GabrielHCataldo/go-errors/errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expression represents a parsed expression of the gateway expression language, used by the modifier `if`
+// conditions and by the `{{ }}` templates of the modifier values.
+// The expression supports eval values (#request... and #response...), string, number, boolean and null literals,
+// the arithmetic operators +, -, *, /, %, the comparison operators ==, !=, <, <=, >, >=, the regex operators
+// =~ and !~, the boolean operators &&, || and !, the unary operator exists, parenthesis to group expressions and
+// calls to the functions registered in expressionFunctions.
+//
+// The expression is sandboxed, it can only read the eval values provided and call the registered functions.
+//
+// Examples:
+//
+//	#response.statusCode == 404
+//	#request.header.X-Client[0] == 'mobile' && exists #request.body.id
+//	upper(default(#request.body.name, 'anonymous'))
+//	#response.body.price * 1.1
+type Expression struct {
+	// raw represents the raw expression configured.
+	raw string
+	// root represents the root node of the parsed expression.
+	root expressionNode
+}
+
+// expressionEvalFunc represents a function that obtains the value of an eval word, for example #response.statusCode.
+type expressionEvalFunc func(word string) any
+
+// expressionNode represents a node of the parsed expression tree.
+type expressionNode interface {
+	// value evaluates the node using the provided expressionEvalFunc and returns its typed result.
+	value(evalFunc expressionEvalFunc) any
+}
+
+// expressionLiteral represents a literal value (string, number, boolean or null) in the expression.
+type expressionLiteral struct {
+	literal any
+}
+
+// expressionEval represents an eval word in the expression, for example #request.body.id.
+type expressionEval struct {
+	word string
+}
+
+// expressionUnary represents a unary operation (!, - or exists) in the expression.
+type expressionUnary struct {
+	operator string
+	operand  expressionNode
+}
+
+// expressionBinary represents a binary operation (arithmetic, comparison, regex or boolean) in the expression.
+type expressionBinary struct {
+	operator string
+	left     expressionNode
+	right    expressionNode
+	// regex represents the pre-compiled regex when the operator is =~ or !~ and the right operand is a literal.
+	regex *regexp.Regexp
+}
+
+// expressionCall represents a call to a registered function in the expression.
+type expressionCall struct {
+	name      string
+	function  expressionFunction
+	arguments []expressionNode
+}
+
+// expressionToken represents a lexical token of the expression.
+type expressionToken struct {
+	kind  string
+	value string
+}
+
+// expressionParser represents the recursive descent parser of the expression.
+type expressionParser struct {
+	tokens   []expressionToken
+	position int
+}
+
+const (
+	expressionTokenEval     = "eval"
+	expressionTokenString   = "string"
+	expressionTokenNumber   = "number"
+	expressionTokenIdent    = "ident"
+	expressionTokenOperator = "operator"
+	expressionTokenEnd      = "end"
+)
+
+// newExpression parses the provided raw expression and returns a new instance of Expression.
+// If the raw expression is malformed or calls a function not registered, it returns nil and the parse error.
+func newExpression(raw string) (*Expression, error) {
+	// separamos a expressão em tokens
+	tokens, err := tokenizeExpression(raw)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+
+	// construímos a árvore da expressão
+	parser := &expressionParser{tokens: tokens}
+	root, err := parser.parseOr()
+	if helper.IsNotNil(err) {
+		return nil, errors.New(err, "on expression:", raw)
+	} else if parser.peek().kind != expressionTokenEnd {
+		return nil, errors.New("Unexpected token", parser.peek().value, "on expression:", raw)
+	}
+
+	// retornamos a expressão pronta para ser avaliada
+	return &Expression{
+		raw:  raw,
+		root: root,
+	}, nil
+}
+
+// Raw returns the raw expression configured.
+func (e *Expression) Raw() string {
+	return e.raw
+}
+
+// Evaluate evaluates the expression using the provided expressionEvalFunc to obtain the eval values,
+// returning the typed result (nil, bool, float64, string, []any or map[string]any).
+func (e *Expression) Evaluate(evalFunc expressionEvalFunc) any {
+	return e.root.value(evalFunc)
+}
+
+func (e expressionLiteral) value(_ expressionEvalFunc) any {
+	return e.literal
+}
+
+func (e expressionEval) value(evalFunc expressionEvalFunc) any {
+	return evalFunc(e.word)
+}
+
+func (e expressionUnary) value(evalFunc expressionEvalFunc) any {
+	switch e.operator {
+	case "exists":
+		return helper.IsNotNil(e.operand.value(evalFunc))
+	case "-":
+		number, ok := expressionNumber(e.operand.value(evalFunc))
+		if !ok {
+			return nil
+		}
+		return -number
+	default:
+		return !expressionTruthy(e.operand.value(evalFunc))
+	}
+}
+
+func (e expressionBinary) value(evalFunc expressionEvalFunc) any {
+	// os operadores booleanos avaliam em curto-circuito
+	switch e.operator {
+	case "&&":
+		return expressionTruthy(e.left.value(evalFunc)) && expressionTruthy(e.right.value(evalFunc))
+	case "||":
+		return expressionTruthy(e.left.value(evalFunc)) || expressionTruthy(e.right.value(evalFunc))
+	}
+
+	left := e.left.value(evalFunc)
+	right := e.right.value(evalFunc)
+
+	switch e.operator {
+	case "==":
+		return expressionEquals(left, right)
+	case "!=":
+		return !expressionEquals(left, right)
+	case "=~", "!~":
+		matched := e.match(left, right)
+		if helper.Equals(e.operator, "!~") {
+			return !matched
+		}
+		return matched
+	case "+", "-", "*", "/", "%":
+		return expressionArithmetic(e.operator, left, right)
+	default:
+		return expressionCompare(e.operator, left, right)
+	}
+}
+
+func (e expressionCall) value(evalFunc expressionEvalFunc) any {
+	// avaliamos os argumentos antes de chamar a função
+	var arguments []any
+	for _, argument := range e.arguments {
+		arguments = append(arguments, argument.value(evalFunc))
+	}
+	// chamamos a função, caso ocorra erro o resultado é nulo
+	result, err := e.function(arguments...)
+	if helper.IsNotNil(err) {
+		return nil
+	}
+	return result
+}
+
+// match checks if the left value matches the regex of the right value.
+// If the regex was not pre-compiled, it is compiled from the right value, if it is invalid it returns false.
+func (e expressionBinary) match(left, right any) bool {
+	if helper.IsNil(left) {
+		return false
+	}
+	regex := e.regex
+	if helper.IsNil(regex) {
+		var err error
+		regex, err = regexp.Compile(expressionString(right))
+		if helper.IsNotNil(err) {
+			return false
+		}
+	}
+	return regex.MatchString(expressionString(left))
+}
+
+// tokenizeExpression splits the raw expression into expressionToken, returning an error if an unexpected character
+// is found.
+func tokenizeExpression(raw string) ([]expressionToken, error) {
+	var tokens []expressionToken
+	runes := []rune(raw)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '#':
+			start := i
+			i++
+			for i < len(runes) && isExpressionEvalRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, expressionToken{kind: expressionTokenEval, value: string(runes[start:i])})
+		case r == '\'' || r == '"':
+			quote := r
+			i++
+			var builder strings.Builder
+			for i < len(runes) && runes[i] != quote {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				builder.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, errors.New("Unterminated string on expression:", raw)
+			}
+			i++
+			tokens = append(tokens, expressionToken{kind: expressionTokenString, value: builder.String()})
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, expressionToken{kind: expressionTokenNumber, value: string(runes[start:i])})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, expressionToken{kind: expressionTokenIdent, value: string(runes[start:i])})
+		default:
+			operator := ""
+			if i+1 < len(runes) {
+				switch string(runes[i : i+2]) {
+				case "==", "!=", "<=", ">=", "=~", "!~", "&&", "||":
+					operator = string(runes[i : i+2])
+				}
+			}
+			if helper.IsEmpty(operator) {
+				switch r {
+				case '<', '>', '!', '(', ')', ',', '+', '-', '*', '/', '%':
+					operator = string(r)
+				default:
+					return nil, errors.New("Unexpected character", string(r), "on expression:", raw)
+				}
+			}
+			i += len(operator)
+			tokens = append(tokens, expressionToken{kind: expressionTokenOperator, value: operator})
+		}
+	}
+	return append(tokens, expressionToken{kind: expressionTokenEnd}), nil
+}
+
+// isExpressionEvalRune checks if the rune can be part of an eval word, the hyphen is accepted for header keys.
+func isExpressionEvalRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.-[]", r)
+}
+
+// peek returns the current token without consuming it.
+func (p *expressionParser) peek() expressionToken {
+	return p.tokens[p.position]
+}
+
+// next returns the current token and moves to the next one.
+func (p *expressionParser) next() expressionToken {
+	token := p.tokens[p.position]
+	if token.kind != expressionTokenEnd {
+		p.position++
+	}
+	return token
+}
+
+// acceptOperator consumes the current token if it is one of the provided operators, returning the operator consumed.
+func (p *expressionParser) acceptOperator(operators ...string) (string, bool) {
+	token := p.peek()
+	if token.kind != expressionTokenOperator || !helper.Contains(operators, token.value) {
+		return "", false
+	}
+	p.next()
+	return token.value, true
+}
+
+// parseBinary parses a left associative binary operation with the provided operators, using the parseOperand
+// function to parse each side.
+func (p *expressionParser) parseBinary(parseOperand func() (expressionNode, error), operators ...string) (
+	expressionNode, error) {
+	left, err := parseOperand()
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	for {
+		operator, ok := p.acceptOperator(operators...)
+		if !ok {
+			return left, nil
+		}
+		right, err := parseOperand()
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		left = expressionBinary{operator: operator, left: left, right: right}
+	}
+}
+
+func (p *expressionParser) parseOr() (expressionNode, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *expressionParser) parseAnd() (expressionNode, error) {
+	return p.parseBinary(p.parseComparison, "&&")
+}
+
+func (p *expressionParser) parseComparison() (expressionNode, error) {
+	left, err := p.parseAdditive()
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	operator, ok := p.acceptOperator("==", "!=", "<", "<=", ">", ">=", "=~", "!~")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseAdditive()
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+
+	binary := expressionBinary{operator: operator, left: left, right: right}
+	// caso seja um regex literal, compilamos uma única vez e validamos a sintaxe
+	if literal, isLiteral := right.(expressionLiteral); isLiteral && (operator == "=~" || operator == "!~") {
+		binary.regex, err = regexp.Compile(expressionString(literal.literal))
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+	}
+	return binary, nil
+}
+
+func (p *expressionParser) parseAdditive() (expressionNode, error) {
+	return p.parseBinary(p.parseMultiplicative, "+", "-")
+}
+
+func (p *expressionParser) parseMultiplicative() (expressionNode, error) {
+	return p.parseBinary(p.parseUnary, "*", "/", "%")
+}
+
+func (p *expressionParser) parseUnary() (expressionNode, error) {
+	if operator, ok := p.acceptOperator("!", "-"); ok {
+		operand, err := p.parseUnary()
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		return expressionUnary{operator: operator, operand: operand}, nil
+	}
+	if token := p.peek(); token.kind == expressionTokenIdent && helper.Equals(token.value, "exists") {
+		p.next()
+		operand, err := p.parseUnary()
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		return expressionUnary{operator: "exists", operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *expressionParser) parsePrimary() (expressionNode, error) {
+	token := p.next()
+	switch token.kind {
+	case expressionTokenEval:
+		return expressionEval{word: token.value}, nil
+	case expressionTokenString:
+		return expressionLiteral{literal: token.value}, nil
+	case expressionTokenNumber:
+		number, err := strconv.ParseFloat(token.value, 64)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		return expressionLiteral{literal: number}, nil
+	case expressionTokenIdent:
+		switch token.value {
+		case "true":
+			return expressionLiteral{literal: true}, nil
+		case "false":
+			return expressionLiteral{literal: false}, nil
+		case "null", "nil":
+			return expressionLiteral{literal: nil}, nil
+		}
+		if _, ok := p.acceptOperator("("); ok {
+			return p.parseCall(token.value)
+		}
+	case expressionTokenOperator:
+		if helper.Equals(token.value, "(") {
+			node, err := p.parseOr()
+			if helper.IsNotNil(err) {
+				return nil, err
+			}
+			if _, ok := p.acceptOperator(")"); !ok {
+				return nil, errors.New("Expected )")
+			}
+			return node, nil
+		}
+	case expressionTokenEnd:
+		return nil, errors.New("Unexpected end")
+	}
+	return nil, errors.New("Unexpected token", token.value)
+}
+
+// parseCall parses the arguments of a function call, the function must be registered in expressionFunctions.
+func (p *expressionParser) parseCall(name string) (expressionNode, error) {
+	function, ok := expressionFunctions[name]
+	if !ok {
+		return nil, errors.New("Function", name, "not found")
+	}
+
+	call := expressionCall{name: name, function: function}
+	if _, ok = p.acceptOperator(")"); ok {
+		return call, nil
+	}
+	for {
+		argument, err := p.parseOr()
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		call.arguments = append(call.arguments, argument)
+		if _, ok = p.acceptOperator(")"); ok {
+			return call, nil
+		} else if _, ok = p.acceptOperator(","); !ok {
+			return nil, errors.New("Expected , or ) on function", name)
+		}
+	}
+}
+
+// expressionTruthy checks if the value is considered true, nil, false, zero, empty string and empty collections
+// are considered false.
+func expressionTruthy(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	default:
+		return helper.IsNotEmpty(v)
+	}
+}
+
+// expressionEquals checks if both values are equal, numbers are compared by value and the other types by their
+// string representation.
+func expressionEquals(left, right any) bool {
+	if helper.IsNil(left) || helper.IsNil(right) {
+		return helper.IsNil(left) && helper.IsNil(right)
+	}
+	leftNumber, leftOk := expressionNumber(left)
+	rightNumber, rightOk := expressionNumber(right)
+	if leftOk && rightOk {
+		return leftNumber == rightNumber
+	}
+	return helper.Equals(expressionString(left), expressionString(right))
+}
+
+// expressionCompare compares both values with the provided operator, numbers are compared by value and the other
+// types by their string representation. If any value is nil, it returns false.
+func expressionCompare(operator string, left, right any) bool {
+	if helper.IsNil(left) || helper.IsNil(right) {
+		return false
+	}
+
+	var comparison int
+	leftNumber, leftOk := expressionNumber(left)
+	rightNumber, rightOk := expressionNumber(right)
+	if leftOk && rightOk {
+		if leftNumber < rightNumber {
+			comparison = -1
+		} else if leftNumber > rightNumber {
+			comparison = 1
+		}
+	} else {
+		comparison = strings.Compare(expressionString(left), expressionString(right))
+	}
+
+	switch operator {
+	case "<":
+		return comparison < 0
+	case "<=":
+		return comparison <= 0
+	case ">":
+		return comparison > 0
+	case ">=":
+		return comparison >= 0
+	default:
+		return false
+	}
+}
+
+// expressionArithmetic applies the arithmetic operator to both values. If both values are numbers, the operation is
+// numeric, if the operator is + and any value is not a number, the values are concatenated as strings.
+// Otherwise, or on division by zero, it returns nil.
+func expressionArithmetic(operator string, left, right any) any {
+	leftNumber, leftOk := expressionNumber(left)
+	rightNumber, rightOk := expressionNumber(right)
+	if !leftOk || !rightOk {
+		if helper.Equals(operator, "+") && helper.IsNotNil(left) && helper.IsNotNil(right) {
+			return expressionString(left) + expressionString(right)
+		}
+		return nil
+	}
+
+	switch operator {
+	case "+":
+		return leftNumber + rightNumber
+	case "-":
+		return leftNumber - rightNumber
+	case "*":
+		return leftNumber * rightNumber
+	case "/":
+		if rightNumber == 0 {
+			return nil
+		}
+		return leftNumber / rightNumber
+	case "%":
+		if rightNumber == 0 {
+			return nil
+		}
+		return math.Mod(leftNumber, rightNumber)
+	default:
+		return nil
+	}
+}
+
+// expressionNumber converts the value to float64 if it is a number or a numeric string.
+func expressionNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case string:
+		number, err := strconv.ParseFloat(v, 64)
+		return number, helper.IsNil(err)
+	default:
+		return 0, false
+	}
+}
+
+// expressionString converts the value to its string representation, nil is converted to an empty string.
+func expressionString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return fmt.Sprint(v)
+	default:
+		return helper.SimpleConvertToString(v)
+	}
+}
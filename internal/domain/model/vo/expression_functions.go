@@ -0,0 +1,302 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/GabrielHCataldo/go-errors/errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/google/uuid"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// expressionFunction represents a function that can be called inside an Expression.
+// It receives the typed arguments already evaluated and returns a typed result or an error,
+// on error the result of the call is nil.
+type expressionFunction func(args ...any) (any, error)
+
+// expressionFunctions represents the registry of functions that can be called inside an Expression, only the
+// functions registered here are accessible, keeping the expression sandboxed.
+var expressionFunctions = map[string]expressionFunction{
+	"upper":         expressionUpper,
+	"lower":         expressionLower,
+	"trim":          expressionTrim,
+	"concat":        expressionConcat,
+	"default":       expressionDefault,
+	"base64Encode":  expressionBase64Encode,
+	"base64Decode":  expressionBase64Decode,
+	"urlEncode":     expressionUrlEncode,
+	"urlDecode":     expressionUrlDecode,
+	"sha256":        expressionSha256,
+	"hmac":          expressionHmac,
+	"uuid":          expressionUuid,
+	"now":           expressionNow,
+	"jsonStringify": expressionJsonStringify,
+	"jsonParse":     expressionJsonParse,
+	"number":        expressionToNumber,
+	"string":        expressionToString,
+	"round":         expressionRound,
+	"floor":         expressionFloor,
+	"ceil":          expressionCeil,
+	"abs":           expressionAbs,
+}
+
+// expressionUpper returns the string argument in upper case. Usage: upper(value)
+func expressionUpper(args ...any) (any, error) {
+	value, err := expressionStringArg("upper", args, 0)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return strings.ToUpper(value), nil
+}
+
+// expressionLower returns the string argument in lower case. Usage: lower(value)
+func expressionLower(args ...any) (any, error) {
+	value, err := expressionStringArg("lower", args, 0)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return strings.ToLower(value), nil
+}
+
+// expressionTrim returns the string argument without leading and trailing spaces, or the cutset provided.
+// Usage: trim(value) or trim(value, cutset)
+func expressionTrim(args ...any) (any, error) {
+	value, err := expressionStringArg("trim", args, 0)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	if helper.IsGreaterThan(len(args), 1) {
+		return strings.Trim(value, expressionString(args[1])), nil
+	}
+	return strings.TrimSpace(value), nil
+}
+
+// expressionConcat returns the concatenation of all arguments as string. Usage: concat(value, value, ...)
+func expressionConcat(args ...any) (any, error) {
+	var builder strings.Builder
+	for _, arg := range args {
+		builder.WriteString(expressionString(arg))
+	}
+	return builder.String(), nil
+}
+
+// expressionDefault returns the first argument that is not nil or empty string. Usage: default(value, fallback, ...)
+func expressionDefault(args ...any) (any, error) {
+	for _, arg := range args {
+		if helper.IsNotNil(arg) && helper.IsNotEmpty(expressionString(arg)) {
+			return arg, nil
+		}
+	}
+	return nil, nil
+}
+
+// expressionBase64Encode returns the standard base64 encoding of the string argument. Usage: base64Encode(value)
+func expressionBase64Encode(args ...any) (any, error) {
+	value, err := expressionStringArg("base64Encode", args, 0)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return base64.StdEncoding.EncodeToString([]byte(value)), nil
+}
+
+// expressionBase64Decode returns the decoded value of the standard base64 string argument.
+// Usage: base64Decode(value)
+func expressionBase64Decode(args ...any) (any, error) {
+	value, err := expressionStringArg("base64Decode", args, 0)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return string(decoded), nil
+}
+
+// expressionUrlEncode returns the query escaped value of the string argument. Usage: urlEncode(value)
+func expressionUrlEncode(args ...any) (any, error) {
+	value, err := expressionStringArg("urlEncode", args, 0)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return url.QueryEscape(value), nil
+}
+
+// expressionUrlDecode returns the query unescaped value of the string argument. Usage: urlDecode(value)
+func expressionUrlDecode(args ...any) (any, error) {
+	value, err := expressionStringArg("urlDecode", args, 0)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return url.QueryUnescape(value)
+}
+
+// expressionSha256 returns the hex encoded SHA-256 hash of the string argument. Usage: sha256(value)
+func expressionSha256(args ...any) (any, error) {
+	value, err := expressionStringArg("sha256", args, 0)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// expressionHmac returns the hex encoded HMAC-SHA256 of the value using the provided secret.
+// Usage: hmac(secret, value)
+func expressionHmac(args ...any) (any, error) {
+	secret, err := expressionStringArg("hmac", args, 0)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	value, err := expressionStringArg("hmac", args, 1)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// expressionUuid returns a new random UUID. Usage: uuid()
+func expressionUuid(_ ...any) (any, error) {
+	return uuid.NewString(), nil
+}
+
+// expressionNow returns the current UTC time formatted by the layout provided, the layout can be a Go time layout,
+// or one of the values "unix" and "unixMilli" to obtain a number. The default layout is RFC3339.
+// Usage: now() or now(layout)
+func expressionNow(args ...any) (any, error) {
+	now := time.Now().UTC()
+	if helper.IsEmpty(args) {
+		return now.Format(time.RFC3339), nil
+	}
+	switch layout := expressionString(args[0]); layout {
+	case "unix":
+		return float64(now.Unix()), nil
+	case "unixMilli":
+		return float64(now.UnixMilli()), nil
+	default:
+		return now.Format(layout), nil
+	}
+}
+
+// expressionJsonStringify returns the JSON representation of the argument as string. Usage: jsonStringify(value)
+func expressionJsonStringify(args ...any) (any, error) {
+	if helper.IsEmpty(args) {
+		return nil, errors.New("jsonStringify expects 1 argument")
+	}
+	marshaled, err := json.Marshal(args[0])
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return string(marshaled), nil
+}
+
+// expressionJsonParse returns the typed value of the JSON string argument. Usage: jsonParse(value)
+func expressionJsonParse(args ...any) (any, error) {
+	value, err := expressionStringArg("jsonParse", args, 0)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	var parsed any
+	err = json.Unmarshal([]byte(value), &parsed)
+	return parsed, err
+}
+
+// expressionToNumber converts the argument to a number. Usage: number(value)
+func expressionToNumber(args ...any) (any, error) {
+	return expressionNumberArg("number", args, 0)
+}
+
+// expressionToString converts the argument to a string. Usage: string(value)
+func expressionToString(args ...any) (any, error) {
+	return expressionStringArg("string", args, 0)
+}
+
+// expressionRound rounds the number argument to the provided decimal places, default is 0.
+// Usage: round(value) or round(value, places)
+func expressionRound(args ...any) (any, error) {
+	value, err := expressionNumberArg("round", args, 0)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	var places float64
+	if helper.IsGreaterThan(len(args), 1) {
+		places, err = expressionNumberArg("round", args, 1)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+	}
+	pow := math.Pow(10, places)
+	return math.Round(value*pow) / pow, nil
+}
+
+// expressionFloor returns the greatest integer value less than or equal to the number argument. Usage: floor(value)
+func expressionFloor(args ...any) (any, error) {
+	value, err := expressionNumberArg("floor", args, 0)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return math.Floor(value), nil
+}
+
+// expressionCeil returns the least integer value greater than or equal to the number argument. Usage: ceil(value)
+func expressionCeil(args ...any) (any, error) {
+	value, err := expressionNumberArg("ceil", args, 0)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return math.Ceil(value), nil
+}
+
+// expressionAbs returns the absolute value of the number argument. Usage: abs(value)
+func expressionAbs(args ...any) (any, error) {
+	value, err := expressionNumberArg("abs", args, 0)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return math.Abs(value), nil
+}
+
+// expressionStringArg returns the argument at the index as string, if it is missing or nil it returns an error.
+func expressionStringArg(name string, args []any, index int) (string, error) {
+	if helper.IsLessThanOrEqual(len(args), index) || helper.IsNil(args[index]) {
+		return "", errors.New(name, "expects a non null argument at position", index)
+	}
+	return expressionString(args[index]), nil
+}
+
+// expressionNumberArg returns the argument at the index as number, if it is missing or not a number it returns an
+// error.
+func expressionNumberArg(name string, args []any, index int) (float64, error) {
+	if helper.IsLessThanOrEqual(len(args), index) {
+		return 0, errors.New(name, "expects a number argument at position", index)
+	}
+	number, ok := expressionNumber(args[index])
+	if !ok {
+		return 0, errors.New(name, "expects a number argument at position", index)
+	}
+	return number, nil
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpressionFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		function string
+		args     []any
+		want     any
+		wantErr  bool
+	}{
+		{"upper", "upper", []any{"gopen"}, "GOPEN", false},
+		{"upper number", "upper", []any{float64(1.5)}, "1.5", false},
+		{"upper without argument", "upper", nil, nil, true},
+		{"upper nil", "upper", []any{nil}, nil, true},
+		{"lower", "lower", []any{"GOPEN"}, "gopen", false},
+		{"lower nil", "lower", []any{nil}, nil, true},
+		{"trim", "trim", []any{"  gopen "}, "gopen", false},
+		{"trim cutset", "trim", []any{"--gopen--", "-"}, "gopen", false},
+		{"trim nil", "trim", []any{nil}, nil, true},
+		{"concat", "concat", []any{"a", float64(1), true}, "a1true", false},
+		{"concat nil", "concat", []any{"a", nil}, "a", false},
+		{"concat without arguments", "concat", nil, "", false},
+		{"default first", "default", []any{"a", "b"}, "a", false},
+		{"default skips nil and empty", "default", []any{nil, "", "b"}, "b", false},
+		{"default keeps type", "default", []any{nil, float64(0)}, float64(0), false},
+		{"default all nil", "default", []any{nil, nil}, nil, false},
+		{"base64Encode", "base64Encode", []any{"gopen"}, "Z29wZW4=", false},
+		{"base64Encode nil", "base64Encode", []any{nil}, nil, true},
+		{"base64Decode", "base64Decode", []any{"Z29wZW4="}, "gopen", false},
+		{"base64Decode invalid", "base64Decode", []any{"%%%"}, nil, true},
+		{"base64Decode nil", "base64Decode", []any{nil}, nil, true},
+		{"urlEncode", "urlEncode", []any{"a b&c"}, "a+b%26c", false},
+		{"urlEncode nil", "urlEncode", []any{nil}, nil, true},
+		{"urlDecode", "urlDecode", []any{"a+b%26c"}, "a b&c", false},
+		{"urlDecode invalid", "urlDecode", []any{"%zz"}, "", true},
+		{"sha256", "sha256", []any{"gopen"},
+			"1192fce2a9d9061d83419f94049ec021d03af1bc717af9a4d39c3e04acc25f83", false},
+		{"sha256 nil", "sha256", []any{nil}, nil, true},
+		{"hmac", "hmac", []any{"key", "The quick brown fox jumps over the lazy dog"},
+			"f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8", false},
+		{"hmac without value", "hmac", []any{"key"}, nil, true},
+		{"hmac nil secret", "hmac", []any{nil, "value"}, nil, true},
+		{"now unix", "now", []any{"unix"}, nil, false},
+		{"jsonStringify", "jsonStringify", []any{map[string]any{"a": float64(1)}}, `{"a":1}`, false},
+		{"jsonStringify nil", "jsonStringify", []any{nil}, "null", false},
+		{"jsonStringify without argument", "jsonStringify", nil, nil, true},
+		{"jsonParse", "jsonParse", []any{`{"a":[1,"b"]}`}, map[string]any{"a": []any{float64(1), "b"}}, false},
+		{"jsonParse invalid", "jsonParse", []any{`{`}, nil, true},
+		{"jsonParse nil", "jsonParse", []any{nil}, nil, true},
+		{"number", "number", []any{"1.5"}, 1.5, false},
+		{"number invalid", "number", []any{"a"}, float64(0), true},
+		{"number nil", "number", []any{nil}, float64(0), true},
+		{"number without argument", "number", nil, float64(0), true},
+		{"string", "string", []any{float64(10)}, "10", false},
+		{"string bool", "string", []any{true}, "true", false},
+		{"string nil", "string", []any{nil}, "", true},
+		{"round", "round", []any{2.5}, float64(3), false},
+		{"round places", "round", []any{1.2345, float64(2)}, 1.23, false},
+		{"round string number", "round", []any{"1.6"}, float64(2), false},
+		{"round invalid places", "round", []any{1.5, "a"}, nil, true},
+		{"round nil", "round", []any{nil}, nil, true},
+		{"floor", "floor", []any{1.9}, float64(1), false},
+		{"floor negative", "floor", []any{-1.1}, float64(-2), false},
+		{"floor invalid", "floor", []any{"a"}, nil, true},
+		{"ceil", "ceil", []any{1.1}, float64(2), false},
+		{"ceil nil", "ceil", []any{nil}, nil, true},
+		{"abs", "abs", []any{float64(-3)}, float64(3), false},
+		{"abs without argument", "abs", nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			function, ok := expressionFunctions[tt.function]
+			if !ok {
+				t.Fatalf("function %s not registered", tt.function)
+			}
+			got, err := function(tt.args...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%s(%v) err = %v, wantErr %v", tt.function, tt.args, err, tt.wantErr)
+			}
+			if tt.want != nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s(%v) = %#v, want %#v", tt.function, tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpressionFunctionsNow(t *testing.T) {
+	got, err := expressionNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = time.Parse(time.RFC3339, got.(string)); err != nil {
+		t.Errorf("now() = %v, want RFC3339: %v", got, err)
+	}
+	got, _ = expressionNow("2006")
+	if got != time.Now().UTC().Format("2006") {
+		t.Errorf("now(2006) = %v", got)
+	}
+	got, _ = expressionNow("unixMilli")
+	if millis, ok := got.(float64); !ok || millis < float64(time.Now().Add(-time.Minute).UnixMilli()) {
+		t.Errorf("now(unixMilli) = %v", got)
+	}
+}
+
+func TestExpressionFunctionsUuid(t *testing.T) {
+	first, _ := expressionUuid()
+	second, _ := expressionUuid()
+	if len(first.(string)) != 36 || strings.Count(first.(string), "-") != 4 || first == second {
+		t.Errorf("uuid() = %v, %v", first, second)
+	}
+}
+
+func TestExpressionCallNilOnError(t *testing.T) {
+	expression, err := newExpression("upper(#request.body.absent)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := expression.Evaluate(testEvalFunc); got != nil {
+		t.Errorf("Evaluate() = %v, want nil", got)
+	}
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
+	"reflect"
+	"testing"
+)
+
+// testEvalFunc returns the eval values used by the expression tests.
+func testEvalFunc(word string) any {
+	values := map[string]any{
+		"#response.statusCode":     float64(404),
+		"#request.body.name":       "gopen",
+		"#request.header.X-Client": "mobile",
+		"#request.body.empty":      "",
+	}
+	return values[word]
+}
+
+func TestNewExpression(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want any
+	}{
+		{"multiplication before addition", "1 + 2 * 3", float64(7)},
+		{"parenthesis before multiplication", "(1 + 2) * 3", float64(9)},
+		{"left associative subtraction", "10 - 4 - 3", float64(3)},
+		{"left associative division", "8 / 4 / 2", float64(1)},
+		{"modulo", "7 % 4", float64(3)},
+		{"unary minus", "-2 * 3", float64(-6)},
+		{"and before or", "true || false && false", true},
+		{"comparison before and", "1 < 2 && 3 > 4", false},
+		{"arithmetic before comparison", "1 + 1 == 2", true},
+		{"not", "!true", false},
+		{"double not", "!!1", true},
+		{"not with parenthesis", "!(1 == 2)", true},
+		{"exists", "exists #request.body.name", true},
+		{"exists absent", "exists #request.body.absent", false},
+		{"eval comparison", "#response.statusCode == 404", true},
+		{"eval hyphen", "#request.header.X-Client == 'mobile'", true},
+		{"numeric string comparison", "'10' == 10", true},
+		{"string comparison", "'b' > 'a'", true},
+		{"nil comparison", "#request.body.absent == null", true},
+		{"nil ordering", "#request.body.absent < 1", false},
+		{"string concatenation", "'a' + 1", "a1"},
+		{"nil concatenation", "'a' + #request.body.absent", nil},
+		{"division by zero", "1 / 0", nil},
+		{"modulo by zero", "1 % 0", nil},
+		{"single quote string", "'gopen'", "gopen"},
+		{"double quote string", `"gopen"`, "gopen"},
+		{"escaped quote", `'it\'s'`, "it's"},
+		{"escaped backslash", `'a\\b'`, `a\b`},
+		{"other quote inside string", `"it's"`, "it's"},
+		{"regex match", "#request.body.name =~ '^go'", true},
+		{"regex not match", "#request.body.name !~ '^go'", false},
+		{"regex nil", "#request.body.absent =~ '.*'", false},
+		{"empty string falsy", "!#request.body.empty", true},
+		{"function call", "upper(#request.body.name)", "GOPEN"},
+		{"nested function call", "upper(default(#request.body.absent, 'anonymous'))", "ANONYMOUS"},
+		{"null literal", "nil", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expression, err := newExpression(tt.raw)
+			if err != nil {
+				t.Fatalf("newExpression(%q) err = %v", tt.raw, err)
+			}
+			if got := expression.Evaluate(testEvalFunc); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate(%q) = %#v, want %#v", tt.raw, got, tt.want)
+			}
+			if expression.Raw() != tt.raw {
+				t.Errorf("Raw() = %q, want %q", expression.Raw(), tt.raw)
+			}
+		})
+	}
+}
+
+func TestNewExpressionError(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{"empty", ""},
+		{"unterminated string", "'gopen"},
+		{"unexpected character", "1 & 2"},
+		{"missing right operand", "1 +"},
+		{"missing closing parenthesis", "(1 + 2"},
+		{"extra closing parenthesis", "1 + 2)"},
+		{"trailing token", "1 2"},
+		{"chained comparison", "1 < 2 < 3"},
+		{"unknown function", "unknown(1)"},
+		{"missing comma", "default(1 2)"},
+		{"unclosed call", "default(1,"},
+		{"identifier without call", "gopen"},
+		{"invalid regex", "#request.body.name =~ '('"},
+		{"invalid number", "1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if expression, err := newExpression(tt.raw); err == nil {
+				t.Errorf("newExpression(%q) = %v, want error", tt.raw, expression)
+			}
+		})
+	}
+}
+
+func TestModifierValidateParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		dto     dto.Modifier
+		wantErr bool
+	}{
+		{"valid", dto.Modifier{Action: enum.ModifierActionSet, Key: "id", Value: "{{ #request.body.name }}",
+			If: "#response.statusCode == 404"}, false},
+		{"malformed if", dto.Modifier{Action: enum.ModifierActionSet, Key: "id", Value: "1",
+			If: "#response.statusCode =="}, true},
+		{"malformed template", dto.Modifier{Action: enum.ModifierActionSet, Key: "id", Value: "{{ upper( }}"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modifierVO := newModifier(&tt.dto)
+			if err := modifierVO.Validate(enum.ModifierActionSet); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvalPath(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"#request.body.id", "request.body.id"},
+		{"#request.header.X-Id[0]", "request.header.X-Id.0"},
+		{"#response.body.items[10].tags[2]", "response.body.items.10.tags.2"},
+		{"#item", "item"},
+		{"#request.body.items[a]", "request.body.items[a]"},
+	}
+	for _, tt := range tests {
+		if got := evalPath(tt.word); got != tt.want {
+			t.Errorf("evalPath(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
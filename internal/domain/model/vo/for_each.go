@@ -0,0 +1,139 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"github.com/GabrielHCataldo/go-errors/errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"github.com/tidwall/gjson"
+	"strconv"
+)
+
+// ForEach represents the fan-out of a backend over the elements of an array of a previous backend response.
+// The backend is called once per element, with the path params and query values filled from the element, and the
+// body of each successful response is merged back into its element under the configured key.
+type ForEach struct {
+	// backend represents the name of the previous backend whose response is iterated.
+	backend string
+	// path represents the gjson path of the array on the body of the previous backend response.
+	path string
+	// params represents the path params of each call, by name, filled with the value of the gjson path on the element.
+	params map[string]string
+	// query represents the query values of each call, by key, filled with the value of the gjson path on the element.
+	query map[string]string
+	// concurrency represents the maximum number of calls running at the same time.
+	concurrency int
+	// key represents the key of the element where the body of the response of each call is merged.
+	key string
+}
+
+// newForEach creates a new instance of ForEach based on the provided forEachDTO.
+// If the forEachDTO is nil, it returns nil.
+func newForEach(forEachDTO *dto.ForEach) *ForEach {
+	if helper.IsNil(forEachDTO) {
+		return nil
+	}
+	return &ForEach{
+		backend:     forEachDTO.Backend,
+		path:        forEachDTO.Path,
+		params:      forEachDTO.Params,
+		query:       forEachDTO.Query,
+		concurrency: forEachDTO.Concurrency,
+		key:         forEachDTO.Key,
+	}
+}
+
+// Backend returns the name of the previous backend whose response is iterated, empty for the last backend response.
+func (f *ForEach) Backend() string {
+	return f.backend
+}
+
+// Path returns the gjson path of the array on the body of the previous backend response, empty for the body itself.
+func (f *ForEach) Path() string {
+	return f.path
+}
+
+// Params returns the path params of each call, by name, filled with the value of the gjson path on the element.
+func (f *ForEach) Params() map[string]string {
+	return f.params
+}
+
+// Query returns the query values of each call, by key, filled with the value of the gjson path on the element.
+func (f *ForEach) Query() map[string]string {
+	return f.query
+}
+
+// Concurrency returns the maximum number of calls running at the same time, if not configured it returns 5.
+func (f *ForEach) Concurrency() int {
+	if helper.IsGreaterThan(f.concurrency, 0) {
+		return f.concurrency
+	}
+	return 5
+}
+
+// Key returns the key of the element where the body of the response of each call is merged.
+func (f *ForEach) Key() string {
+	return f.key
+}
+
+// Validate checks the configuration of the fan-out, returning an error if the key is not informed, the concurrency
+// is negative or any param or query has an empty path.
+func (f *ForEach) Validate() error {
+	if helper.IsEmpty(f.key) {
+		return errors.New("key must be informed")
+	} else if helper.IsLessThan(f.concurrency, 0) {
+		return errors.New("concurrency must be greater than or equal to 0")
+	}
+	for name, path := range f.params {
+		if helper.IsEmpty(path) {
+			return errors.New("params", name, "path must be informed")
+		}
+	}
+	for key, path := range f.query {
+		if helper.IsEmpty(path) {
+			return errors.New("query", key, "path must be informed")
+		}
+	}
+	return nil
+}
+
+// Request returns a new Request with the path params and the query values filled from the element. The values not
+// found on the element are not filled.
+func (f *ForEach) Request(requestVO *Request, element gjson.Result) *Request {
+	params := requestVO.Params()
+	for name, path := range f.params {
+		if result := element.Get(path); result.Exists() {
+			params = params.Set(name, result.String())
+		}
+	}
+	query := requestVO.Query()
+	for key, path := range f.query {
+		if result := element.Get(path); result.Exists() {
+			query = query.Set(key, []string{result.String()})
+		}
+	}
+	return requestVO.SetParams(params).SetQuery(query)
+}
+
+// elementPath returns the sjson path of the key on the element of the index.
+func (f *ForEach) elementPath(index int) string {
+	if helper.IsEmpty(f.path) {
+		return strconv.Itoa(index) + "." + f.key
+	}
+	return f.path + "." + strconv.Itoa(index) + "." + f.key
+}
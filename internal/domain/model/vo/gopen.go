@@ -17,9 +17,13 @@
 package vo
 
 import (
+	"fmt"
+	"github.com/GabrielHCataldo/go-errors/errors"
 	"github.com/GabrielHCataldo/go-helper/helper"
 	"github.com/GabrielHCataldo/go-logger/logger"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -67,6 +71,14 @@ type Gopen struct {
 	// forwardQueries, modifiers, and extraConfig, which specify the behavior
 	// and settings of the middleware.
 	middlewares Middlewares
+	// redaction represents the named redaction rules, applied to the endpoint responses that reference them and to
+	// the logged request and response bodies and headers.
+	redaction *Redaction
+	// errorFormat represents the format of the error responses generated by the gateway, used by the endpoints that
+	// don't configure their own.
+	errorFormat *ErrorFormat
+	// admin represents the configuration of the admin routes, nil if the admin routes are not registered.
+	admin *Admin
 	// endpoints is a field in the Gopen struct that represents a slice of Endpoint objects.
 	// Each Endpoint object defines a specific API endpoint with its corresponding settings such as path, method,
 	// timeout, limiter, cache, etc.
@@ -102,6 +114,9 @@ func NewGopen(env string, gopenDTO *dto.Gopen) *Gopen {
 		cache:        newCacheFromDTO(gopenDTO.Cache),
 		securityCors: newSecurityCors(gopenDTO.SecurityCors),
 		middlewares:  newMiddlewares(gopenDTO.Middlewares),
+		redaction:    newRedaction(gopenDTO.Redaction),
+		errorFormat:  newErrorFormat(gopenDTO.ErrorFormat),
+		admin:        newAdmin(gopenDTO.Admin),
 		endpoints:    endpoints,
 	}
 }
@@ -157,6 +172,21 @@ func (g Gopen) Middlewares() Middlewares {
 	return g.middlewares
 }
 
+// Redaction returns the value of the redaction field in the Gopen struct.
+func (g Gopen) Redaction() *Redaction {
+	return g.redaction
+}
+
+// ErrorFormat returns the global format of the error responses generated by the gateway, or nil if not configured.
+func (g Gopen) ErrorFormat() *ErrorFormat {
+	return g.errorFormat
+}
+
+// Admin returns the configuration of the admin routes, or nil if the admin routes are not registered.
+func (g Gopen) Admin() *Admin {
+	return g.admin
+}
+
 // Endpoints returns a slice containing all the endpoints configured in the Gopen struct.
 // It iterates over each EndpointVO in the endpoints slice and fills in default values by calling the
 // fillDefaultValues method on each EndpointVO, passing the Gopen instance as a parameter.
@@ -198,6 +228,50 @@ func (g Gopen) CountBackends() (count int) {
 	return count
 }
 
+// Validate validates the redaction rules, the modifiers and schemas configured on the middlewares and endpoints of
+// the Gopen struct, rejecting the configurations that can never be applied. It returns an error listing all the
+// problems found, or nil if everything is valid.
+func (g Gopen) Validate() error {
+	// ordenamos as chaves dos middlewares para ter sempre a mesma mensagem
+	keys := make([]string, 0, len(g.middlewares))
+	for key := range g.middlewares {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var messages []string
+	if helper.IsNotNil(g.errorFormat) {
+		if err := g.errorFormat.Validate(); helper.IsNotNil(err) {
+			messages = append(messages, fmt.Sprintf("error-format: %s", errors.Details(err).GetMessage()))
+		}
+	}
+	if helper.IsNotNil(g.admin) {
+		if err := g.admin.Validate(); helper.IsNotNil(err) {
+			messages = append(messages, fmt.Sprintf("admin: %s", errors.Details(err).GetMessage()))
+		}
+	}
+	if helper.IsNotNil(g.redaction) {
+		for _, message := range g.redaction.Validate() {
+			messages = append(messages, fmt.Sprintf("redaction.%s", message))
+		}
+	}
+	for _, key := range keys {
+		middlewareBackend := g.middlewares[key]
+		for _, message := range middlewareBackend.Validate() {
+			messages = append(messages, fmt.Sprintf("middlewares.%s.%s", key, message))
+		}
+	}
+	for _, endpointVO := range g.endpoints {
+		for _, message := range endpointVO.Validate(g.redaction) {
+			messages = append(messages, fmt.Sprintf("%s \"%s\" %s", endpointVO.Method(), endpointVO.Path(), message))
+		}
+	}
+	if helper.IsEmpty(messages) {
+		return nil
+	}
+	return errors.New("Invalid Gopen configuration!\n- " + strings.Join(messages, "\n- "))
+}
+
 // CountModifiers counts the total number of modifiers in the Gopen struct.
 // It iterates through all the middleware backends and endpoint VOs,
 // and calls the CountModifiers method on each of them to calculate the count.
@@ -19,6 +19,7 @@ package vo
 import (
 	"github.com/GabrielHCataldo/go-helper/helper"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/consts"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
 	"net/http"
 	"strings"
 )
@@ -35,7 +36,7 @@ func NewHeader(httpHeader http.Header) Header {
 // and consts.XGopenSuccess.
 func newHeaderFailed() Header {
 	return Header{
-		consts.XGopenCache:    {"false"},
+		consts.XGopenCache:    {string(enum.CacheStatusMiss)},
 		consts.XGopenComplete: {"false"},
 		consts.XGopenSuccess:  {"false"},
 	}
@@ -47,7 +48,7 @@ func newHeaderFailed() Header {
 // The returned Header object contains the updated values for consts.XGopenCache, consts.XGopenComplete, consts.XGopenSuccess modifyHeaders.
 func newResponseHeader(complete, success bool) Header {
 	return Header{
-		consts.XGopenCache:    {"false"},
+		consts.XGopenCache:    {string(enum.CacheStatusMiss)},
 		consts.XGopenComplete: {helper.SimpleConvertToString(complete)},
 		consts.XGopenSuccess:  {helper.SimpleConvertToString(success)},
 	}
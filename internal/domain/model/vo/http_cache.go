@@ -0,0 +1,125 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// etagHeader represents the name of the ETag header in its canonical form, as the header keys are compared as is.
+const etagHeader = "Etag"
+
+// cacheControlDirectives returns the directives of all the Cache-Control values of the header, with the names in
+// lowercase and the values without quotes. If a directive is repeated, the first value is kept.
+func cacheControlDirectives(header Header) map[string]string {
+	directives := map[string]string{}
+	for _, value := range header.Http().Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, directiveValue, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			name = strings.ToLower(strings.TrimSpace(name))
+			if _, ok := directives[name]; helper.IsEmpty(name) || ok {
+				continue
+			}
+			directives[name] = strings.Trim(strings.TrimSpace(directiveValue), `"`)
+		}
+	}
+	return directives
+}
+
+// hasCacheControlDirective returns whether the Cache-Control header has any of the directives.
+func hasCacheControlDirective(header Header, cacheControls ...enum.CacheControl) bool {
+	directives := cacheControlDirectives(header)
+	for _, cacheControl := range cacheControls {
+		if _, ok := directives[string(cacheControl)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// originTTL returns the duration in which the response is fresh according to its headers, and whether it was
+// informed. The Cache-Control s-maxage directive has priority over max-age, as the gateway is a shared cache, and
+// the Age header is subtracted from them. If they are absent, the Expires header is used, relative to the Date
+// header, and an invalid Expires means the response is already expired.
+func originTTL(header Header) (time.Duration, bool) {
+	// obtemos a duração das diretivas do Cache-Control, descontando a idade da resposta
+	directives := cacheControlDirectives(header)
+	for _, name := range []string{"s-maxage", "max-age"} {
+		value, ok := directives[name]
+		if !ok {
+			continue
+		}
+		seconds, err := strconv.Atoi(value)
+		if helper.IsNotNil(err) {
+			continue
+		}
+		return max(time.Duration(seconds)*time.Second-originAge(header), 0), true
+	}
+
+	// caso não tenha as diretivas, obtemos a duração do Expires
+	expires := header.Get("Expires")
+	if helper.IsEmpty(expires) {
+		return 0, false
+	}
+	expiresAt, err := http.ParseTime(expires)
+	if helper.IsNotNil(err) {
+		return 0, true
+	}
+	date, err := http.ParseTime(header.Get("Date"))
+	if helper.IsNotNil(err) {
+		date = time.Now()
+	}
+	return max(expiresAt.Sub(date), 0), true
+}
+
+// originAge returns the duration of the Age header, 0 if absent or invalid.
+func originAge(header Header) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(header.Get("Age")))
+	if helper.IsNotNil(err) || helper.IsLessThan(seconds, 0) {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// newETag generates the weak ETag of the body, with the first 16 bytes of its SHA-256 hash.
+func newETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return fmt.Sprintf(`W/"%s"`, hex.EncodeToString(sum[:16]))
+}
+
+// matchETag returns whether the If-None-Match header value matches the ETag, using the weak comparison, where "*"
+// matches any ETag.
+func matchETag(ifNoneMatch, etag string) bool {
+	if helper.IsEmpty(etag) {
+		return false
+	}
+	for _, value := range strings.Split(ifNoneMatch, ",") {
+		value = strings.TrimSpace(value)
+		if helper.Equals(value, "*") || helper.Equals(strings.TrimPrefix(value, "W/"), strings.TrimPrefix(etag, "W/")) {
+			return true
+		}
+	}
+	return false
+}
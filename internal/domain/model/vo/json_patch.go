@@ -0,0 +1,387 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"encoding/json"
+	"github.com/GabrielHCataldo/go-errors/errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// applyJsonPatch applies the JSON Patch document (RFC 6902) to the JSON document, returning the patched document.
+// The operations are applied in order over a copy of the document, so if any operation fails, the error is returned
+// and the original document remains unchanged. The key order of the document is preserved.
+func applyJsonPatch(document, patch string) (string, error) {
+	if err := validateJsonPatch(patch); helper.IsNotNil(err) {
+		return "", err
+	}
+
+	var err error
+	for index, operation := range gjson.Parse(patch).Array() {
+		document, err = applyJsonPatchOperation(document, operation)
+		if helper.IsNotNil(err) {
+			return "", errors.New("JSON Patch operation", index, "failed:", err)
+		}
+	}
+	return document, nil
+}
+
+// validateJsonPatch checks the structure of the JSON Patch document (RFC 6902), returning an error if it is not a
+// valid JSON array of operations, or if any operation is not supported or misses a member required by it.
+func validateJsonPatch(patch string) error {
+	if !gjson.Valid(patch) || !gjson.Parse(patch).IsArray() {
+		return errors.New("JSON Patch document must be an array of operations")
+	}
+	for index, operation := range gjson.Parse(patch).Array() {
+		op := operation.Get("op").String()
+		if !operation.IsObject() {
+			return errors.New("JSON Patch operation", index, "must be an object")
+		} else if !operation.Get("path").Exists() {
+			return errors.New("JSON Patch operation", index, "without path")
+		}
+		switch op {
+		case "add", "replace", "test":
+			if !operation.Get("value").Exists() {
+				return errors.New("JSON Patch operation", index, "without value")
+			}
+		case "move", "copy":
+			if !operation.Get("from").Exists() {
+				return errors.New("JSON Patch operation", index, "without from")
+			}
+		case "remove":
+		default:
+			return errors.New("JSON Patch operation", index, "with op", op, "not supported")
+		}
+	}
+	return nil
+}
+
+// applyJsonPatchOperation applies a single JSON Patch operation (add, remove, replace, move, copy or test) to the
+// document, returning the modified document or an error.
+func applyJsonPatchOperation(document string, operation gjson.Result) (string, error) {
+	if !operation.IsObject() {
+		return "", errors.New("operation must be an object")
+	}
+
+	op := operation.Get("op").String()
+	path := operation.Get("path")
+	if !path.Exists() {
+		return "", errors.New("operation", op, "without path")
+	}
+
+	switch op {
+	case "add", "replace", "test":
+		value := operation.Get("value")
+		if !value.Exists() {
+			return "", errors.New("operation", op, "without value")
+		}
+		switch op {
+		case "add":
+			return jsonPointerAdd(document, path.String(), value.Raw)
+		case "replace":
+			return jsonPointerReplace(document, path.String(), value.Raw)
+		default:
+			return jsonPointerTest(document, path.String(), value.Raw)
+		}
+	case "remove":
+		return jsonPointerRemove(document, path.String())
+	case "move", "copy":
+		from := operation.Get("from")
+		if !from.Exists() {
+			return "", errors.New("operation", op, "without from")
+		}
+		value, err := jsonPointerGet(document, from.String())
+		if helper.IsNotNil(err) {
+			return "", err
+		}
+		if helper.Equals(op, "move") {
+			if strings.HasPrefix(path.String(), from.String()+"/") {
+				return "", errors.New("operation move cannot move", from.String(), "into one of its children")
+			}
+			document, err = jsonPointerRemove(document, from.String())
+			if helper.IsNotNil(err) {
+				return "", err
+			}
+		}
+		return jsonPointerAdd(document, path.String(), value)
+	default:
+		return "", errors.New("operation", op, "not supported")
+	}
+}
+
+// applyJsonMergePatch applies the JSON Merge Patch document (RFC 7396) to the JSON document, returning the merged
+// document. If the patch is not valid JSON, it returns an error and the original document remains unchanged.
+func applyJsonMergePatch(document, patch string) (string, error) {
+	if !gjson.Valid(patch) {
+		return "", errors.New("JSON Merge Patch document is not a valid JSON")
+	}
+	return mergeJsonPatch(document, gjson.Parse(patch)), nil
+}
+
+// mergeJsonPatch merges the patch into the target recursively following the RFC 7396 rules, null values remove
+// the key, objects are merged and any other value replaces the target value.
+func mergeJsonPatch(target string, patch gjson.Result) string {
+	if !patch.IsObject() {
+		return patch.Raw
+	}
+	if !gjson.Parse(target).IsObject() {
+		target = "{}"
+	}
+	patch.ForEach(func(key, value gjson.Result) bool {
+		path := escapeJsonPathKey(key.String())
+		if helper.Equals(value.Type, gjson.Null) {
+			target, _ = sjson.Delete(target, path)
+		} else {
+			target, _ = sjson.SetRaw(target, path, mergeJsonPatch(gjson.Get(target, path).Raw, value))
+		}
+		return true
+	})
+	return target
+}
+
+// jsonPointerGet returns the raw JSON value referenced by the JSON pointer, or an error if it doesn't exist.
+func jsonPointerGet(document, pointer string) (string, error) {
+	if helper.IsEmpty(pointer) {
+		return document, nil
+	}
+	path, err := jsonPointerToPath(pointer)
+	if helper.IsNotNil(err) {
+		return "", err
+	}
+	result := gjson.Get(document, path)
+	if !result.Exists() {
+		return "", errors.New("path", pointer, "not found")
+	}
+	return result.Raw, nil
+}
+
+// jsonPointerAdd adds the raw value at the location referenced by the JSON pointer. If the parent is an array, the
+// value is inserted at the index (or appended if the index is "-"), otherwise the member is added or replaced.
+func jsonPointerAdd(document, pointer, value string) (string, error) {
+	if helper.IsEmpty(pointer) {
+		return value, nil
+	}
+
+	parentPointer, lastToken := splitJsonPointer(pointer)
+	parent, err := jsonPointerGet(document, parentPointer)
+	if helper.IsNotNil(err) {
+		return "", err
+	}
+
+	parsedParent := gjson.Parse(parent)
+	if parsedParent.IsArray() {
+		elements := jsonArrayElements(parsedParent)
+		index := len(elements)
+		if helper.IsNotEqualTo(lastToken, "-") {
+			index, err = strconv.Atoi(lastToken)
+			if helper.IsNotNil(err) || index < 0 || index > len(elements) {
+				return "", errors.New("index", lastToken, "out of bounds on path", pointer)
+			}
+		}
+		elements = append(elements[:index], append([]string{value}, elements[index:]...)...)
+		return jsonPointerSetRaw(document, parentPointer, "["+strings.Join(elements, ",")+"]")
+	} else if !parsedParent.IsObject() {
+		return "", errors.New("parent of path", pointer, "is not an object or array")
+	}
+	return jsonPointerSetRaw(document, pointer, value)
+}
+
+// jsonPointerRemove removes the value referenced by the JSON pointer, or returns an error if it doesn't exist.
+func jsonPointerRemove(document, pointer string) (string, error) {
+	if _, err := jsonPointerGet(document, pointer); helper.IsNotNil(err) {
+		return "", err
+	} else if helper.IsEmpty(pointer) {
+		return "null", nil
+	}
+	path, _ := jsonPointerToPath(pointer)
+	return sjson.Delete(document, path)
+}
+
+// jsonPointerReplace replaces the value referenced by the JSON pointer, or returns an error if it doesn't exist.
+func jsonPointerReplace(document, pointer, value string) (string, error) {
+	if _, err := jsonPointerGet(document, pointer); helper.IsNotNil(err) {
+		return "", err
+	}
+	return jsonPointerSetRaw(document, pointer, value)
+}
+
+// jsonPointerTest checks if the value referenced by the JSON pointer is equal to the raw value, returning the
+// document unchanged if it is, or an error otherwise.
+func jsonPointerTest(document, pointer, value string) (string, error) {
+	current, err := jsonPointerGet(document, pointer)
+	if helper.IsNotNil(err) {
+		return "", err
+	}
+	var currentValue, expectedValue any
+	_ = json.Unmarshal([]byte(current), &currentValue)
+	_ = json.Unmarshal([]byte(value), &expectedValue)
+	if !reflect.DeepEqual(currentValue, expectedValue) {
+		return "", errors.New("test failed on path", pointer)
+	}
+	return document, nil
+}
+
+// jsonPointerSetRaw sets the raw value at the location referenced by the JSON pointer.
+func jsonPointerSetRaw(document, pointer, value string) (string, error) {
+	if helper.IsEmpty(pointer) {
+		return value, nil
+	}
+	path, err := jsonPointerToPath(pointer)
+	if helper.IsNotNil(err) {
+		return "", err
+	}
+	return sjson.SetRaw(document, path, value)
+}
+
+// jsonArrayElements returns the raw elements of the JSON array.
+func jsonArrayElements(array gjson.Result) []string {
+	var elements []string
+	for _, element := range array.Array() {
+		elements = append(elements, element.Raw)
+	}
+	return elements
+}
+
+// splitJsonPointer splits the JSON pointer into the parent pointer and the unescaped last reference token.
+func splitJsonPointer(pointer string) (string, string) {
+	index := strings.LastIndex(pointer, "/")
+	return pointer[:index], unescapeJsonPointerToken(pointer[index+1:])
+}
+
+// jsonPointerToPath converts the JSON pointer (RFC 6901) to a gjson/sjson path, escaping the special characters.
+func jsonPointerToPath(pointer string) (string, error) {
+	if !strings.HasPrefix(pointer, "/") {
+		return "", errors.New("JSON pointer", pointer, "must start with /")
+	}
+	var keys []string
+	for _, token := range strings.Split(pointer[1:], "/") {
+		keys = append(keys, escapeJsonPathKey(unescapeJsonPointerToken(token)))
+	}
+	return strings.Join(keys, "."), nil
+}
+
+// unescapeJsonPointerToken unescapes the ~1 and ~0 sequences of a JSON pointer reference token.
+func unescapeJsonPointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+// escapeJsonPathKey escapes the characters of the key that have special meaning in the gjson/sjson path syntax.
+func escapeJsonPathKey(key string) string {
+	var builder strings.Builder
+	for _, r := range key {
+		if strings.ContainsRune(`.*?|#@\!=<>%`, r) {
+			builder.WriteRune('\\')
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+// jsonDocumentToken represents an eval word or a `{{ }}` template found in a JSON document value.
+type jsonDocumentToken struct {
+	// start and end represent the position of the token in the document.
+	start, end int
+	// raw represents the eval word, or the expression inside the template.
+	raw string
+	// template represents a boolean indicating whether the token is a template.
+	template bool
+}
+
+// jsonDocumentTokenFunc represents a function that obtains the value of a token of a JSON document, and whether the
+// token should be replaced by it.
+type jsonDocumentTokenFunc func(token jsonDocumentToken) (any, bool)
+
+// replaceJsonDocumentTokens replaces the `{{ }}` templates and the eval words outside them in the JSON document by
+// the values obtained by the valueFunc, encoding each value as JSON at its position, see encodeJsonDocumentValue, so
+// the values can't change the structure of the document.
+func replaceJsonDocumentTokens(document string, valueFunc jsonDocumentTokenFunc) string {
+	// juntamos os templates e as palavras eval fora deles, ordenados pela posição
+	var tokens []jsonDocumentToken
+	templates := findAllTemplates(document)
+	for _, template := range templates {
+		tokens = append(tokens, jsonDocumentToken{
+			start:    template[0],
+			end:      template[1],
+			raw:      document[template[2]:template[3]],
+			template: true,
+		})
+	}
+	for _, word := range evalSyntaxRegex.FindAllStringIndex(document, -1) {
+		insideTemplate := false
+		for _, template := range templates {
+			if helper.IsGreaterThanOrEqual(word[0], template[0]) && helper.IsLessThanOrEqual(word[1], template[1]) {
+				insideTemplate = true
+				break
+			}
+		}
+		if !insideTemplate {
+			tokens = append(tokens, jsonDocumentToken{start: word[0], end: word[1], raw: document[word[0]:word[1]]})
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].start < tokens[j].start
+	})
+
+	// substituímos cada token pelo valor codificado conforme a posição, dentro ou fora de uma string JSON
+	var builder strings.Builder
+	inString := false
+	last := 0
+	for _, token := range tokens {
+		inString = jsonInString(document[last:token.start], inString)
+		builder.WriteString(document[last:token.start])
+		if value, ok := valueFunc(token); ok {
+			builder.WriteString(encodeJsonDocumentValue(value, inString))
+		} else {
+			builder.WriteString(document[token.start:token.end])
+		}
+		last = token.end
+	}
+	builder.WriteString(document[last:])
+	return builder.String()
+}
+
+// jsonInString returns whether the end of the JSON text is inside a string, starting from the inString state.
+func jsonInString(text string, inString bool) bool {
+	for i := 0; i < len(text); i++ {
+		if inString && text[i] == '\\' {
+			i++
+		} else if text[i] == '"' {
+			inString = !inString
+		}
+	}
+	return inString
+}
+
+// encodeJsonDocumentValue encodes the value as JSON. Inside a JSON string, the string representation of the value
+// is escaped without the quotes, otherwise the value is encoded as a JSON value, null if it can't be encoded.
+func encodeJsonDocumentValue(value any, inString bool) string {
+	if inString {
+		encoded, _ := json.Marshal(expressionString(value))
+		return string(encoded[1 : len(encoded)-1])
+	}
+	encoded, err := json.Marshal(value)
+	if helper.IsNotNil(err) {
+		return "null"
+	}
+	return string(encoded)
+}
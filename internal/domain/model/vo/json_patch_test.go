@@ -0,0 +1,112 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
+	"testing"
+)
+
+func TestReplaceJsonDocumentTokens(t *testing.T) {
+	values := map[string]any{
+		"#request.body.name":   `x"},{"op":"remove","path":"/secret`,
+		"#request.body.user":   map[string]any{"id": float64(1)},
+		"#request.body.count":  float64(2),
+		" upper('a\"b') ":      `A"B`,
+		"#request.body.absent": nil,
+	}
+	valueFunc := func(token jsonDocumentToken) (any, bool) {
+		value, ok := values[token.raw]
+		return value, ok || token.template
+	}
+	tests := []struct {
+		name     string
+		document string
+		want     string
+	}{
+		{"inside string", `[{"op":"add","path":"/name","value":"#request.body.name"}]`,
+			`[{"op":"add","path":"/name","value":"x\"},{\"op\":\"remove\",\"path\":\"/secret"}]`},
+		{"outside string", `[{"op":"add","path":"/name","value":#request.body.name}]`,
+			`[{"op":"add","path":"/name","value":"x\"},{\"op\":\"remove\",\"path\":\"/secret"}]`},
+		{"object value", `{"user":#request.body.user,"count":#request.body.count}`,
+			`{"user":{"id":1},"count":2}`},
+		{"template inside string", `{"name":"hi {{ upper('a"b') }}"}`, `{"name":"hi A\"B"}`},
+		{"template outside string", `{"name":{{ upper('a"b') }}}`, `{"name":"A\"B"}`},
+		{"nil template", `{"name":{{ #request.body.absent }},"text":"{{ #request.body.absent }}"}`,
+			`{"name":null,"text":""}`},
+		{"word not found", `{"name":"#request.body.unknown"}`, `{"name":"#request.body.unknown"}`},
+		{"escaped quote before word", `{"name":"a\"#request.body.count"}`, `{"name":"a\"2"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := replaceJsonDocumentTokens(tt.document, valueFunc); got != tt.want {
+				t.Errorf("replaceJsonDocumentTokens() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateJsonPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   string
+		wantErr bool
+	}{
+		{"valid", `[{"op":"add","path":"/a","value":1},{"op":"remove","path":"/b"},{"op":"move","from":"/a","path":"/c"}]`, false},
+		{"empty", `[]`, false},
+		{"not array", `{"op":"add"}`, true},
+		{"invalid json", `[{"op":"add","path":"/a","value":1}`, true},
+		{"operation not object", `[1]`, true},
+		{"without path", `[{"op":"remove"}]`, true},
+		{"without value", `[{"op":"replace","path":"/a"}]`, true},
+		{"without from", `[{"op":"copy","path":"/a"}]`, true},
+		{"unknown op", `[{"op":"drop","path":"/a"}]`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateJsonPatch(tt.patch); (err != nil) != tt.wantErr {
+				t.Errorf("validateJsonPatch() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestModifierValidateDocument(t *testing.T) {
+	tests := []struct {
+		name    string
+		action  enum.ModifierAction
+		value   string
+		wantErr bool
+	}{
+		{"patch with eval", enum.ModifierActionPatch, `[{"op":"add","path":"/name","value":#request.body.name}]`, false},
+		{"patch with template", enum.ModifierActionPatch, `[{"op":"add","path":"/{{ #request.body.key }}","value":1}]`, false},
+		{"patch single template", enum.ModifierActionPatch, `{{ jsonParse(#request.body.patch) }}`, false},
+		{"patch with eval without value", enum.ModifierActionPatch, `[{"op":"add","path":"#request.body.path"}]`, true},
+		{"patch not array", enum.ModifierActionPatch, `{"name":#request.body.name}`, true},
+		{"merge with eval", enum.ModifierActionMerge, `{"name":#request.body.name}`, false},
+		{"merge malformed", enum.ModifierActionMerge, `{"name":#request.body.name`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modifierVO := newModifier(&dto.Modifier{Action: tt.action, Value: tt.value})
+			if err := modifierVO.Validate(tt.action); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"encoding/json"
+	"github.com/GabrielHCataldo/go-errors/errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/xeipuuv/gojsonschema"
+	"os"
+	"path/filepath"
+)
+
+// JsonSchema represents a JSON Schema, inline or loaded from a file, compiled once when the instance is created so
+// the documents can be validated without parsing the schema again. On hot reload the value objects are built again,
+// so the schema is compiled again with the changes.
+type JsonSchema struct {
+	// file represents the path of the JSON Schema file.
+	file string
+	// schema represents the inline JSON Schema.
+	schema any
+	// document represents the JSON Schema document, the inline schema or the content of the file, used to walk the
+	// schema declarations.
+	document any
+	// compiled represents the compiled JSON Schema, nil if the compilation failed.
+	compiled *gojsonschema.Schema
+	// err represents the error of the compilation, nil if compiled successfully.
+	err error
+}
+
+// SchemaViolation represents a violation of a document validated by a JsonSchema.
+type SchemaViolation struct {
+	// Field represents the path of the field that violated the schema.
+	Field string `json:"field"`
+	// Type represents the type of the violation, for example "required" or "invalid_type".
+	Type string `json:"type"`
+	// Description represents the human-readable description of the violation.
+	Description string `json:"description"`
+}
+
+// newJsonSchema creates a new instance of JsonSchema compiling the inline schema, or the schema file if the inline
+// schema is not informed. If both are empty, it returns nil. If the compilation fails, a warning is logged and the
+// error is kept to be returned by Err.
+func newJsonSchema(file string, schema any) *JsonSchema {
+	if helper.IsEmpty(file) && helper.IsNil(schema) {
+		return nil
+	}
+
+	// montamos o loader a partir do schema inline, ou do arquivo
+	var loader gojsonschema.JSONLoader
+	var err error
+	document := schema
+	if helper.IsNotNil(schema) {
+		loader = gojsonschema.NewGoLoader(schema)
+	} else {
+		var absFile string
+		absFile, err = filepath.Abs(file)
+		loader = gojsonschema.NewReferenceLoader("file://" + filepath.ToSlash(absFile))
+		// lemos o documento do arquivo, caso falhe a compilação abaixo retorna o erro
+		if fileBytes, readErr := os.ReadFile(absFile); helper.IsNil(readErr) {
+			_ = json.Unmarshal(fileBytes, &document)
+		}
+	}
+
+	// compilamos o schema
+	var compiled *gojsonschema.Schema
+	if helper.IsNil(err) {
+		compiled, err = gojsonschema.NewSchema(loader)
+	}
+	if helper.IsNotNil(err) {
+		logger.Warning("Compile json schema err:", err)
+		err = errors.New("Error compile json schema:", err)
+	}
+
+	return &JsonSchema{
+		file:     file,
+		schema:   schema,
+		document: document,
+		compiled: compiled,
+		err:      err,
+	}
+}
+
+// File returns the path of the JSON Schema file.
+func (j *JsonSchema) File() string {
+	return j.file
+}
+
+// Schema returns the inline JSON Schema.
+func (j *JsonSchema) Schema() any {
+	return j.schema
+}
+
+// Document returns the JSON Schema document, the inline schema or the content of the file.
+func (j *JsonSchema) Document() any {
+	return j.document
+}
+
+// Err returns the error of the compilation of the schema, or nil if compiled successfully.
+func (j *JsonSchema) Err() error {
+	return j.err
+}
+
+// Validate validates the document against the compiled schema, returning the list of violations. If the instance
+// is nil or the schema was not compiled, the document is considered valid.
+func (j *JsonSchema) Validate(document any) []SchemaViolation {
+	if helper.IsNil(j) || helper.IsNil(j.compiled) {
+		return nil
+	}
+
+	result, err := j.compiled.Validate(gojsonschema.NewGoLoader(document))
+	if helper.IsNotNil(err) {
+		return []SchemaViolation{{
+			Field:       "(root)",
+			Type:        "invalid_document",
+			Description: err.Error(),
+		}}
+	} else if result.Valid() {
+		return nil
+	}
+
+	violations := make([]SchemaViolation, len(result.Errors()))
+	for i, resultErr := range result.Errors() {
+		violations[i] = SchemaViolation{
+			Field:       resultErr.Field(),
+			Type:        resultErr.Type(),
+			Description: resultErr.Description(),
+		}
+	}
+	return violations
+}
@@ -17,9 +17,11 @@
 package vo
 
 import (
+	"github.com/GabrielHCataldo/go-errors/errors"
 	"github.com/GabrielHCataldo/go-helper/helper"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
+	"github.com/tidwall/gjson"
 )
 
 // Modifier represents a modification that can be applied to a request or response in the Gopen application.
@@ -49,23 +51,58 @@ type Modifier struct {
 	// value represents a string value in the Modifier struct.
 	// It is used as a field to store the value of a modification.
 	value string
+	// condition represents the parsed optional `if` expression, when it is present the modification is only applied
+	// if the expression is evaluated as true.
+	condition *Condition
+	// conditionErr represents the error of parsing the `if` expression, reported by Validate.
+	conditionErr error
+	// templates represents the parsed `{{ }}` templates of the value by their raw expression, so they are parsed
+	// only once.
+	templates map[string]*Expression
+	// templatesErr represents the first error of parsing the templates of the value, reported by Validate.
+	templatesErr error
+	// onError represents the policy applied when the modification fails, the default value is
+	// enum.ModifierOnErrorWarn.
+	onError enum.ModifierOnError
 }
 
 // newModifier creates a new instance of Modifier based on the provided modifierDTO.
 // If the modifierDTO is nil, it returns nil.
 // Otherwise, it initializes a new Modifier with the values from the modifierDTO and returns a pointer to it.
 // The context, scope, action, propagate, key, and value fields of the Modifier struct are populated from the modifierDTO.
+// The `if` expression and the `{{ }}` templates of the value are parsed once, if the `if` expression is malformed
+// the modifier is never applied, and the parse errors are reported by Validate.
 func newModifier(modifierDTO *dto.Modifier) *Modifier {
 	if helper.IsNil(modifierDTO) {
 		return nil
 	}
+
+	// fazemos o parse da condição caso informada
+	condition, conditionErr := newCondition(modifierDTO.If)
+	if helper.IsNotNil(conditionErr) {
+		condition = newFalseCondition(modifierDTO.If)
+	}
+	// fazemos o parse dos templates do valor
+	templates, templatesErr := parseTemplates(modifierDTO.Value)
+
+	// caso não informado a política de erro padrão é o aviso
+	onError := modifierDTO.OnError
+	if helper.IsEmpty(onError) {
+		onError = enum.ModifierOnErrorWarn
+	}
+
 	return &Modifier{
-		context:   modifierDTO.Context,
-		scope:     modifierDTO.Scope,
-		action:    modifierDTO.Action,
-		propagate: modifierDTO.Propagate,
-		key:       modifierDTO.Key,
-		value:     modifierDTO.Value,
+		context:      modifierDTO.Context,
+		scope:        modifierDTO.Scope,
+		action:       modifierDTO.Action,
+		propagate:    modifierDTO.Propagate,
+		key:          modifierDTO.Key,
+		value:        modifierDTO.Value,
+		condition:    condition,
+		conditionErr: conditionErr,
+		templates:    templates,
+		templatesErr: templatesErr,
+		onError:      onError,
 	}
 }
 
@@ -73,11 +110,15 @@ func newModifier(modifierDTO *dto.Modifier) *Modifier {
 // It initializes a new Modifier with the given context and value, and returns a pointer to it.
 // The context field of the Modifier struct is populated with the provided context value,
 // and the value field is populated with the provided value.
-// Other fields of the Modifier struct are not populated and will have their zero values.
+// The templates of the value are also parsed, the other fields of the Modifier struct are not populated and will
+// have their zero values.
 func newModifierFromValue(context enum.ModifierContext, value string) *Modifier {
+	templates, templatesErr := parseTemplates(value)
 	return &Modifier{
-		context: context,
-		value:   value,
+		context:      context,
+		value:        value,
+		templates:    templates,
+		templatesErr: templatesErr,
 	}
 }
 
@@ -123,6 +164,85 @@ func (m Modifier) Value() string {
 	return m.value
 }
 
+// If returns the raw `if` expression of the Modifier, if not configured it returns an empty string.
+func (m Modifier) If() string {
+	if helper.IsNil(m.condition) {
+		return ""
+	}
+	return m.condition.Expression()
+}
+
+// OnError returns the policy applied when the modification fails.
+func (m Modifier) OnError() enum.ModifierOnError {
+	return m.onError
+}
+
+// Satisfied checks if the `if` expression of the Modifier is evaluated as true against the provided request and
+// response value objects, using the same eval syntax of the modifier values. If there is no expression configured,
+// it returns true.
+func (m Modifier) Satisfied(requestVO *Request, responseVO *Response) bool {
+	if helper.IsNil(m.condition) {
+		return true
+	}
+	// usamos o mesmo avaliador eval dos valores do modificador
+	modifyVO := newModify(&m, requestVO, responseVO)
+	return m.condition.Evaluate(modifyVO.evalValueByWord)
+}
+
+// NotSatisfied returns true if the `if` expression of the Modifier is evaluated as false.
+// It uses the Satisfied method to check.
+func (m Modifier) NotSatisfied(requestVO *Request, responseVO *Response) bool {
+	return !m.Satisfied(requestVO, responseVO)
+}
+
+// Validate checks if the Modifier can ever be applied, accepting only the actions supported by the group of
+// modifiers where it is configured. It returns an error describing the first problem found, or nil if the Modifier
+// is valid. The parse errors of the `if` expression and of the `{{ }}` templates of the value are also reported.
+func (m Modifier) Validate(supportedActions ...enum.ModifierAction) error {
+	if helper.IsNotEmpty(m.context) && !m.context.IsEnumValid() {
+		return errors.New("context", m.context, "is invalid")
+	} else if helper.IsNotEmpty(m.scope) && !m.scope.IsEnumValid() {
+		return errors.New("scope", m.scope, "is invalid")
+	} else if helper.Equals(m.context, enum.ModifierContextRequest) &&
+		helper.Equals(m.scope, enum.ModifierScopeResponse) {
+		return errors.New("scope RESPONSE can never be applied on context REQUEST")
+	} else if !m.action.IsEnumValid() || !helper.Contains(supportedActions, m.action) {
+		return errors.New("action", m.action, "is not supported")
+	} else if !m.action.IsDocumentAction() && helper.IsEmpty(m.key) {
+		return errors.New("key is required for action", m.action)
+	} else if helper.Equals(m.action, enum.ModifierActionRen) && helper.IsEmpty(m.value) {
+		return errors.New("value is required for action", m.action)
+	} else if !m.onError.IsEnumValid() {
+		return errors.New("on-error", m.onError, "is invalid")
+	}
+
+	// reportamos os erros do parse da expressão condicional e dos templates do valor
+	if helper.IsNotNil(m.conditionErr) {
+		return errors.New("if is malformed:", errors.Details(m.conditionErr).GetMessage())
+	} else if helper.IsNotNil(m.templatesErr) {
+		return errors.New("value is malformed:", errors.Details(m.templatesErr).GetMessage())
+	}
+
+	// validamos a estrutura dos documentos das ações de documento, substituindo os templates e a sintaxe eval por
+	// valores nulos, exceto quando o documento é um único template, pois o seu valor é obtido somente na execução
+	templates := findAllTemplates(m.value)
+	if !m.action.IsDocumentAction() || (helper.IsNotEmpty(templates) && isSingleTemplate(m.value, templates)) {
+		return nil
+	}
+	document := replaceJsonDocumentTokens(m.value, func(_ jsonDocumentToken) (any, bool) {
+		return nil, true
+	})
+	if helper.Equals(m.action, enum.ModifierActionPatch) {
+		if err := validateJsonPatch(document); helper.IsNotNil(err) {
+			return errors.New("value must be a valid JSON Patch document for action", m.action+":",
+				errors.Details(err).GetMessage())
+		}
+	} else if !gjson.Valid(document) {
+		return errors.New("value must be a valid JSON for action", m.action)
+	}
+	return nil
+}
+
 // Valid checks if a Modifier is valid.
 // A Modifier is considered valid if both the Modifier and its value are not empty.
 func (m Modifier) Valid() bool {
@@ -17,17 +17,29 @@
 package vo
 
 import (
+	"github.com/GabrielHCataldo/go-errors/errors"
 	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/consts"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
 	"github.com/tidwall/gjson"
+	"os"
 	"regexp"
 	"strings"
 )
 
+// evalSyntaxRegex represents the regex of the eval words in a modifier value, for example #request.body.id.
+var evalSyntaxRegex = regexp.MustCompile(`\B#[a-zA-Z0-9_.\[\]]+`)
+
+// evalIndexRegex represents the regex of the [0] index syntax of the eval words, converted to the .0 syntax.
+var evalIndexRegex = regexp.MustCompile(`\[(\d+)]`)
+
 // modify represents a modification operation to be performed on a request or response.
 // It contains fields such as action, scope, propagate, key, value, request, and response,
 // which define the details of the modification operation.
 type modify struct {
+	// context represents the context of the modifier.
+	context enum.ModifierContext
 	// action represents the action to be performed.
 	action enum.ModifierAction
 	// scope represents the scope of a modifier.
@@ -38,6 +50,10 @@ type modify struct {
 	key string
 	// value represents the value to be inserted to modify the object
 	value string
+	// templates represents the parsed `{{ }}` templates of the value by their raw expression.
+	templates map[string]*Expression
+	// onError represents the policy applied when the modification fails.
+	onError enum.ModifierOnError
 	// request represents an HTTP `request` object.
 	request *Request
 	// response represents an HTTP `response` object.
@@ -68,6 +84,8 @@ type NewModifyVOFunc func(modifierVO *Modifier, requestVO *Request, responseVO *
 // - propagate: the propagate flag from modifierVO
 // - key: the key from modifierVO
 // - value: the value from modifierVO
+// - templates: the parsed templates of the value from modifierVO
+// - onError: the on-error policy from modifierVO
 // - request: the requestVO
 // - response: the responseVO
 // The modify object is then returned.
@@ -87,11 +105,14 @@ func newModify(modifierVO *Modifier, requestVO *Request, responseVO *Response) m
 
 	// construímos o objeto de valor para modificar
 	return modify{
+		context:   modifierVO.Context(),
 		action:    modifierVO.Action(),
 		scope:     scope,
 		propagate: modifierVO.Propagate(),
 		key:       modifierVO.Key(),
 		value:     modifierVO.Value(),
+		templates: modifierVO.templates,
+		onError:   modifierVO.OnError(),
 		request:   requestVO,
 		response:  responseVO,
 	}
@@ -293,18 +314,24 @@ func (m modify) query(query Query, modifierValue []string) Query {
 // It obtains the value to be used for modification using the m.evalValue() method.
 // If m.propagate is true, it calls the m.body method on globalBody and assigns the modified result back to globalBody.
 // The method then calls m.body method on localBody and assigns the modified result back to localBody.
-// The modified globalBody and localBody are then returned.
-func (m modify) bodies(globalBody, localBody *Body) (*Body, *Body) {
+// The modified globalBody and localBody are then returned, with an error if the modification failed and the
+// on-error policy is enum.ModifierOnErrorFail.
+func (m modify) bodies(globalBody, localBody *Body) (*Body, *Body, error) {
 	// obtemos o valor a ser usado para modificar
 	modifierValue := m.evalValue()
 
 	// caso seja em um escopo propagate, modificamos pelo tipo de dado também
+	var err error
 	if m.propagate {
-		globalBody = m.body(globalBody, modifierValue)
+		globalBody, err = m.body(globalBody, modifierValue)
+		if helper.IsNotNil(err) {
+			return nil, nil, err
+		}
 	}
 
 	// retornamos o body global e local possivelmente alterados
-	return globalBody, m.body(localBody, modifierValue)
+	localBody, err = m.body(localBody, modifierValue)
+	return globalBody, localBody, err
 }
 
 // body modifies the body based on the receiver 'm' of the type modify.
@@ -322,12 +349,15 @@ func (m modify) bodies(globalBody, localBody *Body) (*Body, *Body) {
 // and error to modifiedBody and err, respectively.
 // If the action is enum.ModifierActionDel, it calls the Delete method of the body object and assigns the modifiedBody
 // and error to modifiedBody and err, respectively.
-// If an error occurs during the modification, it is handled but not logged.
+// If the action is enum.ModifierActionPatch or enum.ModifierActionMerge, it calls the Patch or Merge method of the
+// body object with the document value.
+// If an error occurs during the modification, it is handled by the on-error policy, see handleError, and the original
+// body is returned.
 // It returns the modifiedBody, which is the body object after the modification.
-func (m modify) body(body *Body, modifierValue any) *Body {
+func (m modify) body(body *Body, modifierValue any) (*Body, error) {
 	// se for nil ja retornamo
 	if helper.IsNil(body) {
-		return nil
+		return nil, nil
 	}
 
 	// instânciamos o body a ser modificado
@@ -348,17 +378,44 @@ func (m modify) body(body *Body, modifierValue any) *Body {
 		modifiedBody, err = body.Rename(m.key, modifierValue)
 	case enum.ModifierActionDel:
 		modifiedBody, err = body.Delete(m.key)
+	case enum.ModifierActionPatch:
+		modifiedBody, err = body.Patch(helper.SimpleConvertToString(modifierValue))
+	case enum.ModifierActionMerge:
+		modifiedBody, err = body.Merge(helper.SimpleConvertToString(modifierValue))
 	default:
-		return body
+		return body, nil
 	}
 
-	// tratamos o erro e retornamos o próprio body
+	// caso ocorra erro, tratamos pela política configurada e retornamos o próprio body sem alterações
 	if helper.IsNotNil(err) {
-		// todo: imprimir log?
+		return body, m.handleError(err)
 	}
 
 	// caso tenha dado tudo certo retornamos o body modificado
-	return modifiedBody
+	return modifiedBody, nil
+}
+
+// handleError handles the error of a modification based on the on-error policy of the modifier.
+// If the policy is enum.ModifierOnErrorIgnore, the error is discarded.
+// If the policy is enum.ModifierOnErrorFail, the error is returned so the strategy can abort the response.
+// Otherwise, the error is logged as a warning with the trace id of the request and discarded.
+func (m modify) handleError(err error) error {
+	switch m.onError {
+	case enum.ModifierOnErrorIgnore:
+		return nil
+	case enum.ModifierOnErrorFail:
+		return err
+	default:
+		logger.Warning("Error modify body with action", m.action, "key:", m.key, "trace id:",
+			m.traceValueByEval("trace.id"), "err:", err)
+		return nil
+	}
+}
+
+// fail constructs the gateway error response of the modifier that failed, naming the modifier in the body.
+func (m modify) fail(err error) *Response {
+	return m.response.ModifierError(newErrorModifier(m.context, m.scope, m.action, m.key),
+		errors.New("Error modify with action", m.action, "key:", m.key, "err:", err))
 }
 
 // intEvalValue method in the modify struct initializes the modifier value by calling
@@ -404,32 +461,86 @@ func (m modify) sliceOfStrEvalValue() []string {
 
 // evalValue evaluates the modifierValue based on the receiver 'm' of the type modify.
 // If the action is "DEL", it returns nil.
-// Otherwise, it iterates through the values and processes them based on eval syntax.
-// Finally, it parses the modifierValue to the appropriate data type and returns it.
+// If the value is composed by only one `{{ expression }}` template, it returns the typed result of the expression.
+// If the action receives a document, the eval words and templates are replaced by their values encoded as JSON, see
+// evalDocument.
+// If the value contains templates mixed with text, each template is replaced by the string representation of its
+// result and the eval syntax is processed in the remaining text, returning a string.
+// Otherwise, it iterates through the values and processes them based on eval syntax, and
+// finally parses the modifierValue to the appropriate data type and returns it.
 func (m modify) evalValue() any {
 	// caso a action seja DEL retornamos nil
 	if helper.Equals(m.action, enum.ModifierActionDel) {
 		return nil
 	}
-	// inicializamos o valor a ser modificado ou não
-	modifierValue := m.value
+
+	// buscamos os templates no valor
+	templates := findAllTemplates(m.value)
+	if helper.IsNotEmpty(templates) && isSingleTemplate(m.value, templates) {
+		// caso seja um único template retornamos o valor tipado
+		return m.evalTemplate(m.value[templates[0][2]:templates[0][3]])
+	} else if m.action.IsDocumentAction() {
+		// caso a action receba um documento, mantemos em string para preservar a ordem das chaves
+		return m.evalDocument(m.value)
+	} else if helper.IsEmpty(templates) {
+		// damos o parse do valor em string caso tenha um valor do tipo não string
+		return m.parseModifierValueToRealType(m.processEvalWords(m.value))
+	}
+
+	// substituímos cada template pelo seu resultado, e processamos a sintaxe eval apenas no texto fora deles
+	var builder strings.Builder
+	last := 0
+	for _, template := range templates {
+		builder.WriteString(m.processEvalWords(m.value[last:template[0]]))
+		builder.WriteString(expressionString(m.evalTemplate(m.value[template[2]:template[3]])))
+		last = template[1]
+	}
+	builder.WriteString(m.processEvalWords(m.value[last:]))
+
+	// retornamos o valor montado
+	return builder.String()
+}
+
+// processEvalWords iterates through the values found by the eval syntax in the provided value,
+// replacing them by the values obtained, and returns the value processed.
+func (m modify) processEvalWords(value string) string {
 	// iteramos os valores com base na sintaxe eval
-	for _, word := range m.findAllByEvalSintaxe(modifierValue) {
+	for _, word := range m.findAllByEvalSintaxe(value) {
 		// processamos o palavra para converter em um valor eval
-		modifierValue = m.processEvalWord(modifierValue, word)
+		value = m.processEvalWord(value, word)
 	}
-	// damos o parse do valor em string caso tenha um valor do tipo não string
-	return m.parseModifierValueToRealType(modifierValue)
+	return value
+}
+
+// evalDocument replaces the eval words and the `{{ }}` templates of the document value of the PATCH and MERGE actions
+// by their values encoded as JSON at their positions, so a value obtained from the request or response can't add
+// members or operations to the document. The eval words not found are kept as is, and the templates evaluated as
+// nil are replaced by null, or by an empty string inside a JSON string.
+func (m modify) evalDocument(document string) string {
+	return replaceJsonDocumentTokens(document, func(token jsonDocumentToken) (any, bool) {
+		if token.template {
+			return m.evalTemplate(token.raw), true
+		}
+		evalValue := m.evalValueByWord(token.raw)
+		return evalValue, helper.IsNotNil(evalValue)
+	})
+}
+
+// evalTemplate evaluates the parsed expression of a template using the eval syntax, returning its typed result.
+// If the expression is malformed, it returns nil.
+func (m modify) evalTemplate(raw string) any {
+	expression, ok := m.templates[raw]
+	if !ok {
+		return nil
+	}
+	return expression.Evaluate(m.evalValueByWord)
 }
 
 // findAllByEvalSintaxe searches for all values in 'value' that match the expected evaluation regex.
-// It creates the regex pattern for the expected evaluation syntax to obtain the value.
-// It returns an array with all values found in 'value' that match the evaluation syntax.
+// It returns an array with all values found in 'value' that match the evaluation syntax, see evalSyntaxRegex.
 func (m modify) findAllByEvalSintaxe(value string) []string {
-	// criamos o regex de evaluation esperado para obter o valor
-	regex := regexp.MustCompile(`\B#[a-zA-Z0-9_.\[\]]+`)
 	// buscamos todos os valores no modifierValue com esse valor eval
-	return regex.FindAllString(value, -1)
+	return evalSyntaxRegex.FindAllString(value, -1)
 }
 
 // processEvalWord takes a modifierValue and a word as input.
@@ -451,17 +562,24 @@ func (m modify) processEvalWord(modifierValue, word string) string {
 }
 
 // evalValueByWord evaluates the value associated with the given word.
-// It removes all instances of "#" from the word.
+// It removes the "#" from the word and converts the [0] index syntax to .0, see evalPath.
 // It then splits the modified word by "." to obtain individual components.
 // If the split result is empty, it returns nil.
-// It extracts the value from either the request or response based on the first component of split.
-// If the first component contains "request", it calls m.requestValueByEval() with m.request and eval as arguments.
-// If the first component contains "response", it calls m.responseValueByEval() with m.response and eval as arguments.
+// It extracts the value based on the first component of split:
+//   - "request": calls m.requestValueByEval() with m.request and eval as arguments.
+//   - "response": calls m.responseValueByEval() with m.response and eval as arguments.
+//   - "env": obtains the environment variable by the name informed, for example #env.API_KEY.
+//   - "endpoint": obtains the path or method of the current endpoint, for example #endpoint.path.
+//   - "gateway": obtains the version of the gateway configuration, for example #gateway.version.
+//   - "trace": obtains the trace id of the current request, for example #trace.id.
+//   - "backends": obtains the last response of the backend with the name informed, for example
+//     #backends.user.body.id.
+//
 // Otherwise, it sets evalValue to nil.
 // It returns the obtained evalValue.
 func (m modify) evalValueByWord(word string) any {
-	// limpamos a #
-	eval := strings.ReplaceAll(word, "#", "")
+	// limpamos a # e convertemos os índices no formato [0] para a sintaxe .0
+	eval := evalPath(word)
 	// damos o split pela pontuação
 	split := strings.Split(eval, ".")
 	// caso esteja vazio vamos para o próximo
@@ -469,19 +587,104 @@ func (m modify) evalValueByWord(word string) any {
 		return nil
 	}
 
-	// obtemos o valor da eval vindo pela requests or responses
+	// obtemos o valor da eval vindo pela fonte indicada
 	var evalValue any
-	if helper.Contains(split[0], "request") {
-		evalValue = m.requestValueByEval(m.request, eval)
-	} else if helper.Contains(split[0], "response") {
-		evalValue = m.responseValueByEval(m.response, eval)
-	} else {
-		evalValue = nil
+	switch split[0] {
+	case "env":
+		evalValue = m.envValueByEval(eval)
+	case "endpoint":
+		evalValue = m.endpointValueByEval(eval)
+	case "gateway":
+		evalValue = m.gatewayValueByEval(eval)
+	case "trace":
+		evalValue = m.traceValueByEval(eval)
+	case "backends":
+		evalValue = m.backendsValueByEval(split)
+	default:
+		if helper.Contains(split[0], "request") {
+			evalValue = m.requestValueByEval(m.request, eval)
+		} else if helper.Contains(split[0], "response") {
+			evalValue = m.responseValueByEval(m.response, eval)
+		}
 	}
 	// retornamos o valor obtido
 	return evalValue
 }
 
+// evalPath returns the path of the eval word, without the # prefix and with the indexes in the format [0] converted
+// to the gjson syntax .0, for example #request.header.X-Id[0] results in request.header.X-Id.0.
+func evalPath(word string) string {
+	return evalIndexRegex.ReplaceAllString(strings.TrimPrefix(word, "#"), ".$1")
+}
+
+// envValueByEval obtains the value of the environment variable named after "env." in the evaluation string.
+// If the variable is not set, it returns nil.
+func (m modify) envValueByEval(eval string) any {
+	value, ok := os.LookupEnv(strings.Replace(eval, "env.", "", 1))
+	if !ok {
+		return nil
+	}
+	return value
+}
+
+// endpointValueByEval obtains the path or method of the endpoint of the response, based on the evaluation string
+// "endpoint.path" or "endpoint.method". Otherwise, it returns nil.
+func (m modify) endpointValueByEval(eval string) any {
+	if helper.IsNil(m.response) || helper.IsNil(m.response.Endpoint()) {
+		return nil
+	}
+	switch eval {
+	case "endpoint.path":
+		return m.response.Endpoint().Path()
+	case "endpoint.method":
+		return m.response.Endpoint().Method()
+	default:
+		return nil
+	}
+}
+
+// gatewayValueByEval obtains the version of the gateway configuration if the evaluation string is "gateway.version".
+// Otherwise, or if the version is empty, it returns nil.
+func (m modify) gatewayValueByEval(eval string) any {
+	if helper.IsNil(m.response) || helper.IsNil(m.response.Endpoint()) ||
+		helper.IsNotEqualTo(eval, "gateway.version") || helper.IsEmpty(m.response.Endpoint().GatewayVersion()) {
+		return nil
+	}
+	return m.response.Endpoint().GatewayVersion()
+}
+
+// traceValueByEval obtains the trace id of the request header if the evaluation string is "trace.id".
+// Otherwise, or if the trace id is empty, it returns nil.
+func (m modify) traceValueByEval(eval string) any {
+	if helper.IsNil(m.request) || helper.IsNotEqualTo(eval, "trace.id") ||
+		helper.IsEmpty(m.request.Header().Get(consts.XTraceId)) {
+		return nil
+	}
+	return m.request.Header().Get(consts.XTraceId)
+}
+
+// backendsValueByEval obtains the value from the last response of the backend named by the second component of
+// split, using the remaining components as expression on the evaluated backend response (statusCode, header and
+// body). If the backend response is not found, or the value not exists, it returns nil.
+func (m modify) backendsValueByEval(split []string) any {
+	if helper.IsLessThan(len(split), 2) || helper.IsNil(m.response) {
+		return nil
+	}
+	backendResponseVO := m.response.BackendResponseByName(split[1])
+	if helper.IsNil(backendResponseVO) {
+		return nil
+	}
+	evalJson := helper.SimpleConvertToString(backendResponseVO.Eval())
+	if helper.Equals(len(split), 2) {
+		return gjson.Parse(evalJson).Value()
+	}
+	result := gjson.Get(evalJson, strings.Join(split[2:], "."))
+	if result.Exists() {
+		return result.Value()
+	}
+	return nil
+}
+
 // requestValueByEval obtains the value from the Request object based on the evaluation string 'eval'.
 // It replaces the "request." substring with an empty string in the evaluation string to form the expression.
 // Using the gjson.Get method, it retrieves the value from the evaluation string in the Request object.
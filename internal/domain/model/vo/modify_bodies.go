@@ -16,7 +16,10 @@
 
 package vo
 
-import "github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
+)
 
 // modifyBodies is a type that represents the combination of a modify struct
 // and additional methods or properties defined within it. This type allows for
@@ -64,7 +67,11 @@ func (m modifyBodies) Execute() (*Request, *Response) {
 
 func (m modifyBodies) executeRequestScope() (*Request, *Response) {
 	// chamamos o modify de bodies passando os bodies a ser modificado e o mesmo retorna modificados
-	globalBody, localBody := m.bodies(m.globalRequestBody(), m.localRequestBody())
+	globalBody, localBody, err := m.bodies(m.globalRequestBody(), m.localRequestBody())
+	// caso a modificação falhe com a política de falha, abortamos com a resposta de erro
+	if helper.IsNotNil(err) {
+		return m.request, m.fail(err)
+	}
 
 	// modificamos o body local
 	backendRequestVO := m.modifyLocalRequest(localBody)
@@ -85,7 +92,11 @@ func (m modifyBodies) executeRequestScope() (*Request, *Response) {
 // Response: The modified response.
 func (m modifyBodies) executeResponseScope() (*Request, *Response) {
 	// chamamos o modify de bodies passando os bodies a ser modificado e o mesmo retorna modificados
-	_, localBody := m.bodies(m.globalResponseBody(), m.localResponseBody())
+	_, localBody, err := m.bodies(m.globalResponseBody(), m.localResponseBody())
+	// caso a modificação falhe com a política de falha, abortamos com a resposta de erro
+	if helper.IsNotNil(err) {
+		return m.request, m.fail(err)
+	}
 
 	// modificamos o header local
 	backendResponseVO := m.modifyLocalResponse(localBody)
@@ -0,0 +1,153 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
+	"strconv"
+)
+
+// modifyEndpointStatusCodes represents a modification strategy of the status code of the final endpoint response.
+type modifyEndpointStatusCodes struct {
+	modify
+}
+
+// modifyEndpointHeaders represents a modification strategy of the headers of the endpoint request, before the first
+// beforeware, or of the final endpoint response.
+type modifyEndpointHeaders struct {
+	modify
+}
+
+// modifyEndpointParams represents a modification strategy of the params of the endpoint request, executed before the
+// first beforeware.
+type modifyEndpointParams struct {
+	modify
+}
+
+// modifyEndpointQueries represents a modification strategy of the queries of the endpoint request, executed before
+// the first beforeware.
+type modifyEndpointQueries struct {
+	modify
+}
+
+// modifyEndpointBodies represents a modification strategy of the body of the endpoint request, before the first
+// beforeware, or of the final endpoint response.
+type modifyEndpointBodies struct {
+	modify
+}
+
+// NewModifyEndpointStatusCodes creates a new instance of modifyEndpointStatusCodes struct
+// with the provided status code, Request, and Response.
+func NewModifyEndpointStatusCodes(statusCodeValue int, requestVO *Request, responseVO *Response) ModifierStrategy {
+	statusCodeStr := strconv.Itoa(statusCodeValue)
+	return modifyEndpointStatusCodes{
+		modify: newModify(newModifierFromValue(enum.ModifierContextResponse, statusCodeStr), requestVO, responseVO),
+	}
+}
+
+// NewModifyEndpointHeaders creates a new instance of modifyEndpointHeaders struct
+// with the provided Modifier, Request, and Response.
+func NewModifyEndpointHeaders(modifierVO *Modifier, requestVO *Request, responseVO *Response) ModifierStrategy {
+	return modifyEndpointHeaders{
+		modify: newModify(modifierVO, requestVO, responseVO),
+	}
+}
+
+// NewModifyEndpointParams creates a new instance of modifyEndpointParams struct
+// with the provided Modifier, Request, and Response.
+func NewModifyEndpointParams(modifierVO *Modifier, requestVO *Request, responseVO *Response) ModifierStrategy {
+	return modifyEndpointParams{
+		modify: newModify(modifierVO, requestVO, responseVO),
+	}
+}
+
+// NewModifyEndpointQueries creates a new instance of modifyEndpointQueries struct
+// with the provided Modifier, Request, and Response.
+func NewModifyEndpointQueries(modifierVO *Modifier, requestVO *Request, responseVO *Response) ModifierStrategy {
+	return modifyEndpointQueries{
+		modify: newModify(modifierVO, requestVO, responseVO),
+	}
+}
+
+// NewModifyEndpointBodies creates a new instance of modifyEndpointBodies struct
+// with the provided Modifier, Request, and Response.
+func NewModifyEndpointBodies(modifierVO *Modifier, requestVO *Request, responseVO *Response) ModifierStrategy {
+	return modifyEndpointBodies{
+		modify: newModify(modifierVO, requestVO, responseVO),
+	}
+}
+
+// Execute modifies the status code of the final response, returning the original request and the modified response.
+func (m modifyEndpointStatusCodes) Execute() (*Request, *Response) {
+	return m.request, m.response.SetStatusCode(m.statusCode(m.response.StatusCode()))
+}
+
+// Execute modifies the header of the request or of the final response based on the configured scope.
+// If the scope is neither enum.ModifierScopeRequest nor enum.ModifierScopeResponse, it returns the original request
+// and response.
+func (m modifyEndpointHeaders) Execute() (*Request, *Response) {
+	// obtemos o valor a ser usado para modificar
+	modifierValue := m.sliceOfStrEvalValue()
+
+	// executamos a partir do escopo configurado
+	switch m.scope {
+	case enum.ModifierScopeRequest:
+		return m.request.SetHeader(m.header(m.request.Header(), modifierValue)), m.response
+	case enum.ModifierScopeResponse:
+		return m.request, m.response.SetHeader(m.header(m.response.Header(), modifierValue))
+	default:
+		return m.request, m.response
+	}
+}
+
+// Execute modifies the params of the request, returning the modified request and the original response.
+func (m modifyEndpointParams) Execute() (*Request, *Response) {
+	return m.request.SetParams(m.param(m.request.Params(), m.strEvalValue())), m.response
+}
+
+// Execute modifies the query of the request, returning the modified request and the original response.
+func (m modifyEndpointQueries) Execute() (*Request, *Response) {
+	return m.request.SetQuery(m.query(m.request.Query(), m.sliceOfStrEvalValue())), m.response
+}
+
+// Execute modifies the body of the request or of the final response based on the configured scope.
+// If the modification fails with the on-error policy enum.ModifierOnErrorFail, it returns the error response.
+// If the scope is neither enum.ModifierScopeRequest nor enum.ModifierScopeResponse, it returns the original request
+// and response.
+func (m modifyEndpointBodies) Execute() (*Request, *Response) {
+	// obtemos o valor a ser usado para modificar
+	modifierValue := m.evalValue()
+
+	// executamos a partir do escopo configurado
+	switch m.scope {
+	case enum.ModifierScopeRequest:
+		body, err := m.body(m.request.Body(), modifierValue)
+		if helper.IsNotNil(err) {
+			return m.request, m.fail(err)
+		}
+		return m.request.SetBody(body), m.response
+	case enum.ModifierScopeResponse:
+		body, err := m.body(m.response.Body(), modifierValue)
+		if helper.IsNotNil(err) {
+			return m.request, m.fail(err)
+		}
+		return m.request, m.response.SetBody(body)
+	default:
+		return m.request, m.response
+	}
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"github.com/GabrielHCataldo/go-errors/errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// linkHeaderNextRegex matches the URL of the link with rel="next" of the Link header.
+var linkHeaderNextRegex = regexp.MustCompile(`<([^>]*)>[^,]*;\s*rel="?next"?`)
+
+// Paginate represents the following of the next pages of a paginated backend. The next page is obtained from a
+// cursor or URL on the body of the page, or from the Link header, and the items of all pages are concatenated into
+// the body of the first page.
+type Paginate struct {
+	// nextPath represents the gjson path of the next cursor, or of the next page URL, on the body of each page.
+	nextPath string
+	// nextQuery represents the query key where the next cursor is sent.
+	nextQuery string
+	// linkHeader indicates whether the next page URL is obtained from the Link header.
+	linkHeader bool
+	// itemsPath represents the gjson path of the array of items on the body of each page.
+	itemsPath string
+	// maxPages represents the maximum number of pages requested, including the first.
+	maxPages int
+	// maxItems represents the maximum number of items concatenated.
+	maxItems int
+}
+
+// newPaginate creates a new instance of Paginate based on the provided paginateDTO.
+// If the paginateDTO is nil, it returns nil.
+func newPaginate(paginateDTO *dto.Paginate) *Paginate {
+	if helper.IsNil(paginateDTO) {
+		return nil
+	}
+	return &Paginate{
+		nextPath:   paginateDTO.NextPath,
+		nextQuery:  paginateDTO.NextQuery,
+		linkHeader: paginateDTO.LinkHeader,
+		itemsPath:  paginateDTO.ItemsPath,
+		maxPages:   paginateDTO.MaxPages,
+		maxItems:   paginateDTO.MaxItems,
+	}
+}
+
+// NextPath returns the gjson path of the next cursor, or of the next page URL, on the body of each page.
+func (p *Paginate) NextPath() string {
+	return p.nextPath
+}
+
+// NextQuery returns the query key where the next cursor is sent, empty if the next path is the next page URL.
+func (p *Paginate) NextQuery() string {
+	return p.nextQuery
+}
+
+// LinkHeader returns whether the next page URL is obtained from the Link header.
+func (p *Paginate) LinkHeader() bool {
+	return p.linkHeader
+}
+
+// ItemsPath returns the gjson path of the array of items on the body of each page, empty for the body itself.
+func (p *Paginate) ItemsPath() string {
+	return p.itemsPath
+}
+
+// MaxPages returns the maximum number of pages requested, including the first, if not configured it returns 10.
+func (p *Paginate) MaxPages() int {
+	if helper.IsGreaterThan(p.maxPages, 0) {
+		return p.maxPages
+	}
+	return 10
+}
+
+// MaxItems returns the maximum number of items concatenated, 0 if the items are not limited.
+func (p *Paginate) MaxItems() int {
+	return p.maxItems
+}
+
+// Validate checks the configuration of the pagination, returning an error if both or none of next-path and
+// link-header are informed, next-query is informed without next-path, or the limits are negative.
+func (p *Paginate) Validate() error {
+	if helper.IsEmpty(p.nextPath) && !p.linkHeader {
+		return errors.New("next-path or link-header must be informed")
+	} else if helper.IsNotEmpty(p.nextPath) && p.linkHeader {
+		return errors.New("next-path and link-header cannot be informed together")
+	} else if helper.IsNotEmpty(p.nextQuery) && helper.IsEmpty(p.nextPath) {
+		return errors.New("next-query must be informed with next-path")
+	} else if helper.IsLessThan(p.maxPages, 0) {
+		return errors.New("max-pages must be greater than or equal to 0")
+	} else if helper.IsLessThan(p.maxItems, 0) {
+		return errors.New("max-items must be greater than or equal to 0")
+	}
+	return nil
+}
+
+// NextUrl returns the URL of the page after the current page, obtained from the Link header or from the next path
+// on the body, resolved against the URL of the current page. If there is no next page, the next page URL is the
+// same as the current one, or it has a scheme or host different from the current one, it returns nil, so the
+// forwarded headers, like Authorization, are never sent to a host named by the backend response.
+func (p *Paginate) NextUrl(currentUrl *url.URL, header Header, body *Body) *url.URL {
+	var next string
+	if p.linkHeader {
+		if match := linkHeaderNextRegex.FindStringSubmatch(header.Get("Link")); helper.IsNotEmpty(match) {
+			next = match[1]
+		}
+	} else if helper.IsNotNil(body) && body.IsJson() {
+		next = gjson.Get(body.String(), p.nextPath).String()
+	}
+	if helper.IsEmpty(next) {
+		return nil
+	}
+
+	// caso configurado, enviamos o cursor na query da página atual, caso contrário o valor é a url da próxima página
+	var nextUrl *url.URL
+	if helper.IsNotEmpty(p.nextQuery) {
+		copyUrl := *currentUrl
+		query := copyUrl.Query()
+		query.Set(p.nextQuery, next)
+		copyUrl.RawQuery = query.Encode()
+		nextUrl = &copyUrl
+	} else if reference, err := url.Parse(next); helper.IsNil(err) {
+		nextUrl = currentUrl.ResolveReference(reference)
+	}
+
+	// evitamos chamar a mesma página novamente, ou outro host
+	if helper.IsNil(nextUrl) || helper.Equals(nextUrl.String(), currentUrl.String()) ||
+		!strings.EqualFold(nextUrl.Scheme, currentUrl.Scheme) || !strings.EqualFold(nextUrl.Host, currentUrl.Host) {
+		return nil
+	}
+	return nextUrl
+}
+
+// CountItems returns the number of items of the page.
+func (p *Paginate) CountItems(body *Body) int {
+	return len(p.items(body))
+}
+
+// ReachedMaxItems returns whether the number of items reached the maximum number of items, if configured.
+func (p *Paginate) ReachedMaxItems(count int) bool {
+	return helper.IsGreaterThan(p.maxItems, 0) && helper.IsGreaterThanOrEqual(count, p.maxItems)
+}
+
+// Concat returns the body of the first page with the items of all pages concatenated on the items path, limited to
+// the maximum number of items, and without the next cursor of the first page, as there are no more pages to follow.
+// If the body of the first page is not JSON, it is returned unchanged.
+func (p *Paginate) Concat(pages []*Body) *Body {
+	if helper.IsEmpty(pages) {
+		return nil
+	} else if helper.IsNil(pages[0]) || pages[0].IsNotJson() {
+		return pages[0]
+	}
+
+	var items []string
+	for _, page := range pages {
+		for _, item := range p.items(page) {
+			if p.ReachedMaxItems(len(items)) {
+				break
+			}
+			items = append(items, item.Raw)
+		}
+	}
+	array := "[" + strings.Join(items, ",") + "]"
+
+	bodyStr := array
+	if helper.IsNotEmpty(p.itemsPath) {
+		bodyStr, _ = sjson.SetRaw(pages[0].String(), p.itemsPath, array)
+		// removemos o cursor da próxima página, pois as páginas já foram concatenadas
+		if helper.IsNotEmpty(p.nextPath) {
+			bodyStr, _ = sjson.Delete(bodyStr, p.nextPath)
+		}
+	}
+	return &Body{
+		contentType: enum.ContentTypeJson,
+		value:       helper.SimpleConvertToBuffer(bodyStr),
+	}
+}
+
+// items returns the items of the page, obtained from the items path, or nil if the page is not JSON or the items are
+// not an array.
+func (p *Paginate) items(body *Body) []gjson.Result {
+	if helper.IsNil(body) || body.IsNotJson() {
+		return nil
+	}
+	result := gjson.Parse(body.String())
+	if helper.IsNotEmpty(p.itemsPath) {
+		result = result.Get(p.itemsPath)
+	}
+	if !result.IsArray() {
+		return nil
+	}
+	return result.Array()
+}
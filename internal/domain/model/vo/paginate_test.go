@@ -0,0 +1,74 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"bytes"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"net/url"
+	"testing"
+)
+
+func TestPaginateNextUrl(t *testing.T) {
+	currentUrl, _ := url.Parse("https://api.local/users?page=1")
+	tests := []struct {
+		name     string
+		paginate dto.Paginate
+		header   Header
+		body     string
+		want     string
+	}{
+		{"relative path", dto.Paginate{NextPath: "next"}, nil, `{"next":"/users?page=2"}`,
+			"https://api.local/users?page=2"},
+		{"absolute same host", dto.Paginate{NextPath: "next"}, nil, `{"next":"https://api.local/users?page=2"}`,
+			"https://api.local/users?page=2"},
+		{"absolute other host", dto.Paginate{NextPath: "next"}, nil, `{"next":"https://evil.local/users?page=2"}`, ""},
+		{"absolute other scheme", dto.Paginate{NextPath: "next"}, nil, `{"next":"http://api.local/users?page=2"}`, ""},
+		{"absolute other port", dto.Paginate{NextPath: "next"}, nil, `{"next":"https://api.local:8443/users"}`, ""},
+		{"protocol relative other host", dto.Paginate{NextPath: "next"}, nil, `{"next":"//evil.local/users"}`, ""},
+		{"cursor query", dto.Paginate{NextPath: "cursor", NextQuery: "cursor"}, nil, `{"cursor":"abc"}`,
+			"https://api.local/users?cursor=abc&page=1"},
+		{"link header", dto.Paginate{LinkHeader: true},
+			Header{"Link": {`<https://api.local/users?page=2>; rel="next"`}}, `[]`, "https://api.local/users?page=2"},
+		{"link header other host", dto.Paginate{LinkHeader: true},
+			Header{"Link": {`<https://evil.local/users?page=2>; rel="next"`}}, `[]`, ""},
+		{"same page", dto.Paginate{NextPath: "next"}, nil, `{"next":"/users?page=1"}`, ""},
+		{"without next", dto.Paginate{NextPath: "next"}, nil, `{}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paginateVO := newPaginate(&tt.paginate)
+			body := NewBody("application/json", bytes.NewBufferString(tt.body))
+			got := paginateVO.NextUrl(currentUrl, tt.header, body)
+			if (got == nil && tt.want != "") || (got != nil && got.String() != tt.want) {
+				t.Errorf("NextUrl() = %v, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaginateConcat(t *testing.T) {
+	paginateVO := newPaginate(&dto.Paginate{NextPath: "meta.next", ItemsPath: "items"})
+	pages := []*Body{
+		NewBody("application/json", bytes.NewBufferString(`{"items":[1,2],"meta":{"next":"c2","total":3}}`)),
+		NewBody("application/json", bytes.NewBufferString(`{"items":[3],"meta":{"total":3}}`)),
+	}
+	want := `{"items":[1,2,3],"meta":{"total":3}}`
+	if got := paginateVO.Concat(pages).String(); got != want {
+		t.Errorf("Concat() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,230 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"github.com/tidwall/gjson"
+	"strconv"
+	"strings"
+)
+
+// Projection represents the fields projection of a JSON body, containing the allow-list and deny-list of paths.
+// The paths are compiled into trees when the instance is created, so the projection can be applied without parsing
+// the paths again.
+type Projection struct {
+	// allow represents the list of paths that will be kept in the body.
+	allow []string
+	// deny represents the list of paths that will be removed from the body.
+	deny []string
+	// allowTree represents the compiled tree of the allow paths, nil if allow is empty.
+	allowTree *projectionNode
+	// denyTree represents the compiled tree of the deny paths, nil if deny is empty.
+	denyTree *projectionNode
+}
+
+// projectionNode represents a node of the compiled tree of the projection paths, each child is indexed by the path
+// segment, where "*" and "#" match any key of an object or any element of an array.
+type projectionNode struct {
+	// terminal indicates if a path ends on this node.
+	terminal bool
+	// children represents the next segments of the paths.
+	children map[string]*projectionNode
+}
+
+// newProjection creates a new instance of Projection based on the provided projectionDTO.
+// If the projectionDTO is nil or has no paths, it returns nil.
+func newProjection(projectionDTO *dto.Projection) *Projection {
+	if helper.IsNil(projectionDTO) || (helper.IsEmpty(projectionDTO.Allow) && helper.IsEmpty(projectionDTO.Deny)) {
+		return nil
+	}
+	return &Projection{
+		allow:     projectionDTO.Allow,
+		deny:      projectionDTO.Deny,
+		allowTree: newProjectionTree(projectionDTO.Allow),
+		denyTree:  newProjectionTree(projectionDTO.Deny),
+	}
+}
+
+// newProjectionTree compiles the paths into a tree of projectionNode, returning nil if the paths are empty.
+func newProjectionTree(paths []string) *projectionNode {
+	if helper.IsEmpty(paths) {
+		return nil
+	}
+	root := &projectionNode{}
+	for _, path := range paths {
+		if helper.IsEmpty(path) {
+			continue
+		}
+		node := root
+		for _, segment := range splitProjectionPath(path) {
+			if helper.IsNil(node.children) {
+				node.children = map[string]*projectionNode{}
+			}
+			child, ok := node.children[segment]
+			if !ok {
+				child = &projectionNode{}
+				node.children[segment] = child
+			}
+			node = child
+		}
+		node.terminal = true
+	}
+	return root
+}
+
+// splitProjectionPath splits the gjson path by the dot separator, respecting the escaped characters.
+func splitProjectionPath(path string) []string {
+	var segments []string
+	var builder strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '\\':
+			if helper.IsLessThan(i+1, len(path)) {
+				i++
+				builder.WriteByte(path[i])
+			}
+		case '.':
+			segments = append(segments, builder.String())
+			builder.Reset()
+		default:
+			builder.WriteByte(path[i])
+		}
+	}
+	return append(segments, builder.String())
+}
+
+// Allow returns the list of paths that will be kept in the body.
+func (p *Projection) Allow() []string {
+	return p.allow
+}
+
+// Deny returns the list of paths that will be removed from the body.
+func (p *Projection) Deny() []string {
+	return p.deny
+}
+
+// Apply applies the projection to the body, first keeping only the allowed paths and then removing the denied paths,
+// returning a new Body instance. If the projection is nil or the body is nil or not JSON, the body is returned
+// unchanged.
+func (p *Projection) Apply(body *Body) *Body {
+	if helper.IsNil(p) || helper.IsNil(body) || body.IsNotJson() {
+		return body
+	}
+
+	// obtemos o json do body
+	result := gjson.Parse(body.String())
+
+	// aplicamos a lista de permitidos
+	projected := result.Raw
+	if helper.IsNotNil(p.allowTree) {
+		projected, _ = projectAllow(result, []*projectionNode{p.allowTree})
+		result = gjson.Parse(projected)
+	}
+	// aplicamos a lista de negados
+	if helper.IsNotNil(p.denyTree) {
+		projected, _ = projectDeny(result, []*projectionNode{p.denyTree})
+	}
+
+	return &Body{
+		contentType: body.contentType,
+		value:       helper.SimpleConvertToBuffer(projected),
+	}
+}
+
+// projectAllow returns the raw JSON value containing only the paths of the nodes, and false if nothing of the value
+// is allowed.
+func projectAllow(result gjson.Result, nodes []*projectionNode) (string, bool) {
+	if projectionTerminal(nodes) {
+		return result.Raw, true
+	}
+	return projectChildren(result, nodes, projectAllow, false)
+}
+
+// projectDeny returns the raw JSON value without the paths of the nodes, and false if the whole value is denied.
+func projectDeny(result gjson.Result, nodes []*projectionNode) (string, bool) {
+	if projectionTerminal(nodes) {
+		return "", false
+	}
+	return projectChildren(result, nodes, projectDeny, true)
+}
+
+// projectChildren walks the children of the object or array, projecting each child that matches the nodes with the
+// project function. The children that don't match any node are kept if keepUnmatched is true. Scalar values are
+// kept only if keepUnmatched is true.
+func projectChildren(result gjson.Result, nodes []*projectionNode,
+	project func(gjson.Result, []*projectionNode) (string, bool), keepUnmatched bool) (string, bool) {
+	if !result.IsObject() && !result.IsArray() {
+		return result.Raw, keepUnmatched
+	}
+
+	var items []string
+	index := 0
+	result.ForEach(func(key, value gjson.Result) bool {
+		// obtemos o segmento do filho, no array é o índice
+		segment := key.String()
+		if result.IsArray() {
+			segment = strconv.Itoa(index)
+			index++
+		}
+
+		// projetamos o filho se ele corresponder, caso contrário mantemos somente se permitido
+		raw, ok := value.Raw, keepUnmatched
+		if next := projectionNext(nodes, segment); helper.IsNotEmpty(next) {
+			raw, ok = project(value, next)
+		}
+		if !ok {
+			return true
+		}
+
+		if result.IsArray() {
+			items = append(items, raw)
+		} else {
+			items = append(items, key.Raw+":"+raw)
+		}
+		return true
+	})
+
+	if result.IsArray() {
+		return "[" + strings.Join(items, ",") + "]", true
+	}
+	return "{" + strings.Join(items, ",") + "}", true
+}
+
+// projectionTerminal checks if any of the nodes is the end of a path.
+func projectionTerminal(nodes []*projectionNode) bool {
+	for _, node := range nodes {
+		if node.terminal {
+			return true
+		}
+	}
+	return false
+}
+
+// projectionNext returns the child nodes that match the segment, including the wildcards "*" and "#".
+func projectionNext(nodes []*projectionNode, segment string) []*projectionNode {
+	var next []*projectionNode
+	for _, node := range nodes {
+		for _, key := range []string{segment, "*", "#"} {
+			if child, ok := node.children[key]; ok {
+				next = append(next, child)
+			}
+		}
+	}
+	return next
+}
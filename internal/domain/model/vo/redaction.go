@@ -0,0 +1,366 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/GabrielHCataldo/go-errors/errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
+	"github.com/tidwall/gjson"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Redaction represents the redaction configuration of the Gopen application, containing the named rules and the
+// names of the rules applied to the logs.
+type Redaction struct {
+	// rules represents the redaction rules by name.
+	rules map[string]RedactionRule
+	// logs represents the names of the rules applied to the logged request and response bodies and headers.
+	logs []string
+}
+
+// RedactionRule represents a rule to redact sensitive data of the bodies and headers.
+type RedactionRule struct {
+	// path represents the gjson path of the values, or the header name, to be redacted.
+	path string
+	// tree represents the compiled tree of the path, nil if the path is empty.
+	tree *projectionNode
+	// regex represents the regular expression of the parts of the values to be redacted, nil if not configured.
+	regex *regexp.Regexp
+	// regexErr represents the error of compiling the regex, reported by Redaction.Validate.
+	regexErr error
+	// strategy represents how the value is redacted.
+	strategy enum.RedactionStrategy
+	// reveal represents the number of last characters revealed by the PARTIAL strategy.
+	reveal int
+}
+
+// RedactionRules represents an ordered list of redaction rules applied together.
+type RedactionRules []RedactionRule
+
+// newRedaction creates a new instance of Redaction based on the provided redactionDTO.
+// If the redactionDTO is nil, it returns nil.
+func newRedaction(redactionDTO *dto.Redaction) *Redaction {
+	if helper.IsNil(redactionDTO) {
+		return nil
+	}
+	rules := map[string]RedactionRule{}
+	for name, ruleDTO := range redactionDTO.Rules {
+		rules[name] = newRedactionRule(ruleDTO)
+	}
+	return &Redaction{
+		rules: rules,
+		logs:  redactionDTO.Logs,
+	}
+}
+
+// newRedactionRule creates a new instance of RedactionRule based on the provided redactionRuleDTO.
+// If the regex is malformed, the compile error is kept to be reported by Redaction.Validate.
+func newRedactionRule(redactionRuleDTO dto.RedactionRule) RedactionRule {
+	var regex *regexp.Regexp
+	var regexErr error
+	if helper.IsNotEmpty(redactionRuleDTO.Regex) {
+		regex, regexErr = regexp.Compile(redactionRuleDTO.Regex)
+	}
+	var tree *projectionNode
+	if helper.IsNotEmpty(redactionRuleDTO.Path) {
+		tree = newProjectionTree([]string{redactionRuleDTO.Path})
+	}
+	reveal := redactionRuleDTO.Reveal
+	if helper.IsLessThanOrEqual(reveal, 0) {
+		reveal = 4
+	}
+	return RedactionRule{
+		path:     redactionRuleDTO.Path,
+		tree:     tree,
+		regex:    regex,
+		regexErr: regexErr,
+		strategy: redactionRuleDTO.Strategy,
+		reveal:   reveal,
+	}
+}
+
+// Rules returns the redaction rules by name.
+func (r *Redaction) Rules() map[string]RedactionRule {
+	return r.rules
+}
+
+// Logs returns the names of the rules applied to the logs.
+func (r *Redaction) Logs() []string {
+	return r.logs
+}
+
+// RulesByNames returns the rules with the provided names, in the same order. The names not configured are ignored,
+// as they are rejected by Validate and ValidateNames. If the Redaction is nil, it returns nil.
+func (r *Redaction) RulesByNames(names []string) RedactionRules {
+	if helper.IsNil(r) {
+		return nil
+	}
+	var rules RedactionRules
+	for _, name := range names {
+		if rule, ok := r.rules[name]; ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// Validate checks the redaction rules and the names of the rules applied to the logs, returning the messages of
+// the rules with a malformed regex, an invalid strategy or without path and regex, and of the names not configured,
+// so a rule never fails open redacting nothing.
+func (r *Redaction) Validate() (messages []string) {
+	// ordenamos os nomes das regras para ter sempre a mesma mensagem
+	names := make([]string, 0, len(r.rules))
+	for name := range r.rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := r.rules[name].Validate(); helper.IsNotNil(err) {
+			messages = append(messages, fmt.Sprintf("rules.%s: %s", name, errors.Details(err).GetMessage()))
+		}
+	}
+	if err := r.ValidateNames(r.logs); helper.IsNotNil(err) {
+		messages = append(messages, fmt.Sprintf("logs: %s", errors.Details(err).GetMessage()))
+	}
+	return messages
+}
+
+// ValidateNames returns an error if any of the names is not configured on the rules. If the Redaction is nil, no
+// name is configured.
+func (r *Redaction) ValidateNames(names []string) error {
+	var rules map[string]RedactionRule
+	if helper.IsNotNil(r) {
+		rules = r.rules
+	}
+	for _, name := range names {
+		if _, ok := rules[name]; !ok {
+			return errors.New("rule", name, "not configured on redaction.rules")
+		}
+	}
+	return nil
+}
+
+// LogRules returns the rules applied to the logs.
+func (r *Redaction) LogRules() RedactionRules {
+	if helper.IsNil(r) {
+		return nil
+	}
+	return r.RulesByNames(r.logs)
+}
+
+// Path returns the gjson path of the values, or the header name, to be redacted.
+func (r RedactionRule) Path() string {
+	return r.path
+}
+
+// Validate checks the rule, returning an error if the regex is malformed, the strategy is invalid or the rule has
+// neither path nor regex.
+func (r RedactionRule) Validate() error {
+	if helper.IsNotNil(r.regexErr) {
+		return errors.New("regex is malformed:", r.regexErr.Error())
+	} else if helper.IsNotEmpty(r.strategy) && !r.strategy.IsEnumValid() {
+		return errors.New("strategy", r.strategy, "is invalid")
+	} else if helper.IsEmpty(r.path) && helper.IsNil(r.regex) {
+		return errors.New("path or regex is required")
+	}
+	return nil
+}
+
+// Regex returns the raw regular expression of the rule, or empty if not configured.
+func (r RedactionRule) Regex() string {
+	if helper.IsNil(r.regex) {
+		return ""
+	}
+	return r.regex.String()
+}
+
+// Strategy returns how the value is redacted.
+func (r RedactionRule) Strategy() enum.RedactionStrategy {
+	return r.strategy
+}
+
+// Reveal returns the number of last characters revealed by the PARTIAL strategy.
+func (r RedactionRule) Reveal() int {
+	return r.reveal
+}
+
+// Body applies the rules to the body, returning a new Body instance. The JSON bodies are redacted by path and regex,
+// the other textual bodies, like XML and form bodies, only by regex, see Text. The binary bodies, that are not valid
+// UTF-8, are returned unchanged, as they can't be redacted without being corrupted.
+func (r RedactionRules) Body(body *Body) *Body {
+	if helper.IsEmpty(r) || helper.IsNil(body) || (body.IsNotJson() && !utf8.Valid(body.Bytes())) {
+		return body
+	}
+	return &Body{
+		contentType: body.contentType,
+		value:       helper.SimpleConvertToBuffer(r.Text(body.String())),
+	}
+}
+
+// Header applies the rules to the header, returning a new Header instance. The rules with path are applied only to
+// the header with the same name, and the rules with only regex are applied to all header values.
+func (r RedactionRules) Header(header Header) Header {
+	if helper.IsEmpty(r) || helper.IsEmpty(header) {
+		return header
+	}
+	redacted := header.copy()
+	for _, rule := range r {
+		for key, values := range redacted {
+			if helper.IsNotEmpty(rule.path) && !strings.EqualFold(key, rule.path) {
+				continue
+			} else if helper.IsEmpty(rule.path) && helper.IsNil(rule.regex) {
+				continue
+			}
+			// caso seja para remover o header inteiro, removemos
+			if helper.Equals(rule.strategy, enum.RedactionStrategyRemove) && helper.IsNil(rule.regex) {
+				delete(redacted, key)
+				continue
+			}
+			redactedValues := make([]string, len(values))
+			for i, value := range values {
+				redactedValues[i] = rule.redactString(value)
+			}
+			redacted[key] = redactedValues
+		}
+	}
+	return redacted
+}
+
+// Text applies the rules to the text, if it is a valid JSON it is redacted by path and regex, otherwise only the
+// rules with regex are applied to the whole text.
+func (r RedactionRules) Text(text string) string {
+	if helper.IsEmpty(r) || helper.IsEmpty(text) {
+		return text
+	}
+	isJson := gjson.Valid(text)
+	for _, rule := range r {
+		if isJson {
+			text, _ = rule.redactJson(gjson.Parse(text), []*projectionNode{rule.tree})
+		} else if helper.IsNotNil(rule.regex) {
+			text = rule.redactString(text)
+		}
+	}
+	return text
+}
+
+// redactJson walks the JSON value redacting the values that match the path nodes, or all string values if the
+// rule has no path. It returns the raw JSON redacted, and false if the value must be removed.
+func (r RedactionRule) redactJson(result gjson.Result, nodes []*projectionNode) (string, bool) {
+	if helper.IsNil(r.tree) {
+		if helper.IsNil(r.regex) {
+			return result.Raw, true
+		} else if helper.Equals(result.Type, gjson.String) {
+			return r.redactResult(result)
+		}
+	} else if projectionTerminal(nodes) {
+		return r.redactResult(result)
+	}
+	if !result.IsObject() && !result.IsArray() {
+		return result.Raw, true
+	}
+
+	var items []string
+	index := 0
+	result.ForEach(func(key, value gjson.Result) bool {
+		// obtemos o segmento do filho, no array é o índice
+		segment := key.String()
+		if result.IsArray() {
+			segment = helper.SimpleConvertToString(index)
+			index++
+		}
+
+		// redigimos o filho caso corresponda ao caminho, ou todos os filhos caso a regra não tenha caminho
+		raw, ok := value.Raw, true
+		if helper.IsNil(r.tree) {
+			raw, ok = r.redactJson(value, nil)
+		} else if next := projectionNext(nodes, segment); helper.IsNotEmpty(next) {
+			raw, ok = r.redactJson(value, next)
+		}
+		if !ok {
+			return true
+		}
+
+		if result.IsArray() {
+			items = append(items, raw)
+		} else {
+			items = append(items, key.Raw+":"+raw)
+		}
+		return true
+	})
+
+	if result.IsArray() {
+		return "[" + strings.Join(items, ",") + "]", true
+	}
+	return "{" + strings.Join(items, ",") + "}", true
+}
+
+// redactResult redacts the JSON value matched, returning the redacted value as a JSON string, and false if the
+// value must be removed. If the rule has a regex and the value doesn't match, it is returned unchanged.
+func (r RedactionRule) redactResult(result gjson.Result) (string, bool) {
+	if helper.Equals(r.strategy, enum.RedactionStrategyRemove) && helper.IsNil(r.regex) {
+		return "", false
+	}
+	value := result.Raw
+	if helper.Equals(result.Type, gjson.String) {
+		value = result.String()
+	}
+	if helper.IsNotNil(r.regex) && !r.regex.MatchString(value) {
+		return result.Raw, true
+	}
+	redacted, _ := json.Marshal(r.redactString(value))
+	return string(redacted), true
+}
+
+// redactString redacts the parts of the value that match the regex, or the whole value if the rule has no regex.
+func (r RedactionRule) redactString(value string) string {
+	if helper.IsNotNil(r.regex) {
+		return r.regex.ReplaceAllStringFunc(value, r.apply)
+	}
+	return r.apply(value)
+}
+
+// apply applies the strategy of the rule to the value. The MASK strategy replaces all characters by "*", the HASH
+// strategy returns the hex encoded SHA-256 of the value, the REMOVE strategy returns an empty string, and the
+// PARTIAL strategy masks all characters except the last revealed ones.
+func (r RedactionRule) apply(value string) string {
+	length := utf8.RuneCountInString(value)
+	switch r.strategy {
+	case enum.RedactionStrategyHash:
+		sum := sha256.Sum256([]byte(value))
+		return hex.EncodeToString(sum[:])
+	case enum.RedactionStrategyRemove:
+		return ""
+	case enum.RedactionStrategyPartial:
+		if helper.IsLessThanOrEqual(length, r.reveal) {
+			return strings.Repeat("*", length)
+		}
+		runes := []rune(value)
+		return strings.Repeat("*", length-r.reveal) + string(runes[length-r.reveal:])
+	default:
+		return strings.Repeat("*", length)
+	}
+}
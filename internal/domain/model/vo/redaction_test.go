@@ -0,0 +1,105 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"bytes"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
+	"strings"
+	"testing"
+)
+
+func TestRedactionValidate(t *testing.T) {
+	redactionVO := newRedaction(&dto.Redaction{
+		Rules: map[string]dto.RedactionRule{
+			"card":      {Path: "card.number", Strategy: enum.RedactionStrategyPartial},
+			"email":     {Regex: `[a-z]+@[a-z.]+`},
+			"bad-regex": {Regex: `(`},
+			"bad-type":  {Path: "password", Strategy: "ENCRYPT"},
+			"empty":     {Strategy: enum.RedactionStrategyMask},
+		},
+		Logs: []string{"card", "unknown"},
+	})
+	want := []string{
+		"rules.bad-regex: regex is malformed:",
+		"rules.bad-type: strategy ENCRYPT is invalid",
+		"rules.empty: path or regex is required",
+		"logs: rule unknown not configured on redaction.rules",
+	}
+	messages := redactionVO.Validate()
+	if len(messages) != len(want) {
+		t.Fatalf("Validate() = %v, want %v", messages, want)
+	}
+	for i, message := range messages {
+		if !strings.HasPrefix(message, want[i]) {
+			t.Errorf("Validate()[%d] = %q, want prefix %q", i, message, want[i])
+		}
+	}
+}
+
+func TestRedactionValidateNames(t *testing.T) {
+	redactionVO := newRedaction(&dto.Redaction{Rules: map[string]dto.RedactionRule{"email": {Regex: `@`}}})
+	tests := []struct {
+		name        string
+		redactionVO *Redaction
+		names       []string
+		wantErr     bool
+	}{
+		{"configured", redactionVO, []string{"email"}, false},
+		{"empty", redactionVO, nil, false},
+		{"not configured", redactionVO, []string{"email", "card"}, true},
+		{"nil redaction", nil, []string{"email"}, true},
+		{"nil redaction without names", nil, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.redactionVO.ValidateNames(tt.names); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateNames() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRedactionRulesBody(t *testing.T) {
+	rules := RedactionRules{
+		newRedactionRule(dto.RedactionRule{Path: "email"}),
+		newRedactionRule(dto.RedactionRule{Regex: `\d{4}-\d{4}`}),
+	}
+	tests := []struct {
+		name        string
+		contentType string
+		value       []byte
+		want        string
+	}{
+		{"json", "application/json", []byte(`{"email":"a@b.c","phone":"1234-5678"}`),
+			`{"email":"*****","phone":"*********"}`},
+		{"xml", "application/xml", []byte(`<user><email>a@b.c</email><phone>1234-5678</phone></user>`),
+			`<user><email>a@b.c</email><phone>*********</phone></user>`},
+		{"text", "text/plain", []byte(`phone 1234-5678`), `phone *********`},
+		{"binary", "application/octet-stream", []byte{0xff, 0xfe, '1', '2', '3', '4', '-', '5', '6', '7', '8'},
+			string([]byte{0xff, 0xfe, '1', '2', '3', '4', '-', '5', '6', '7', '8'})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := rules.Body(NewBody(tt.contentType, bytes.NewBuffer(tt.value)))
+			if got := body.String(); got != tt.want {
+				t.Errorf("Body() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/GabrielHCataldo/go-helper/helper"
 	"github.com/gin-gonic/gin"
 	"io"
+	"net/http"
 )
 
 // Request represents an HTTP request and contains information such as the request path, URL, method, header,
@@ -61,29 +62,43 @@ type Request struct {
 // Returns:
 // Request - A new Request object with the extracted request information.
 func NewRequest(gin *gin.Context) *Request {
+	return newRequest(gin.Request, gin.Params)
+}
+
+// NewRequestByHttp creates a new Request object from the http.Request, without path params, like NewRequest.
+// It is used to rebuild the request of an endpoint outside the route, for example to obtain its cache key.
+func NewRequestByHttp(httpRequest *http.Request) *Request {
+	return newRequest(httpRequest, nil)
+}
+
+// newRequest creates a new Request object from the http.Request and the path params of the route.
+func newRequest(httpRequest *http.Request, params gin.Params) *Request {
 	// instanciamos o query VO para obter funções de montagem da url por ele
-	query := NewQuery(gin.Request.URL.Query())
+	query := NewQuery(httpRequest.URL.Query())
 
 	// preparamos a url ordenando as chaves de busca
-	url := gin.Request.URL.Path
-	if helper.IsNotEmpty(gin.Request.URL.RawQuery) {
+	url := httpRequest.URL.Path
+	if helper.IsNotEmpty(httpRequest.URL.RawQuery) {
 		url += "?" + query.Encode()
 	}
 
 	// obtemos os bytes da requisição
-	bodyBytes, _ := io.ReadAll(gin.Request.Body)
+	var bodyBytes []byte
+	if helper.IsNotNil(httpRequest.Body) {
+		bodyBytes, _ = io.ReadAll(httpRequest.Body)
+	}
 	bodyBuffer := bytes.NewBuffer(bodyBytes)
-	gin.Request.Body = io.NopCloser(bodyBuffer)
+	httpRequest.Body = io.NopCloser(bodyBuffer)
 
 	// montamos o VO de requisição
 	return &Request{
-		path:   gin.Request.URL.Path,
+		path:   httpRequest.URL.Path,
 		url:    url,
-		method: gin.Request.Method,
-		header: NewHeader(gin.Request.Header),
-		params: NewParams(gin.Params),
+		method: httpRequest.Method,
+		header: NewHeader(httpRequest.Header),
+		params: NewParams(params),
 		query:  query,
-		body:   NewBody(gin.GetHeader("Content-Type"), bodyBuffer),
+		body:   NewBody(httpRequest.Header.Get("Content-Type"), bodyBuffer),
 	}
 }
 
@@ -108,6 +123,48 @@ func (r *Request) SetHeader(header Header) *Request {
 	}
 }
 
+// SetParams returns a new Request with the provided params, the other fields remain unchanged.
+func (r *Request) SetParams(params Params) *Request {
+	return &Request{
+		path:    r.path,
+		url:     r.url,
+		method:  r.method,
+		header:  r.header,
+		params:  params,
+		query:   r.query,
+		body:    r.body,
+		history: r.history,
+	}
+}
+
+// SetQuery returns a new Request with the provided query, the other fields remain unchanged.
+func (r *Request) SetQuery(query Query) *Request {
+	return &Request{
+		path:    r.path,
+		url:     r.url,
+		method:  r.method,
+		header:  r.header,
+		params:  r.params,
+		query:   query,
+		body:    r.body,
+		history: r.history,
+	}
+}
+
+// SetBody returns a new Request with the provided body, the other fields remain unchanged.
+func (r *Request) SetBody(body *Body) *Request {
+	return &Request{
+		path:    r.path,
+		url:     r.url,
+		method:  r.method,
+		header:  r.header,
+		params:  r.params,
+		query:   r.query,
+		body:    body,
+		history: r.history,
+	}
+}
+
 // ModifyHeader creates a new Request from an existing one with modifications to the request header.
 // Also modifies the request history by adding a new backendRequestVO at the end.
 // 'header' which is an instance of Header will replace the existing request header.
@@ -229,7 +286,7 @@ func (r *Request) Append(backendRequest *backendRequest) *Request {
 		params:  r.params,
 		query:   r.query,
 		body:    r.body,
-		history: append(r.history, backendRequest),
+		history: append(r.history[:len(r.history):len(r.history)], backendRequest),
 	}
 }
 
@@ -0,0 +1,121 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"github.com/GabrielHCataldo/go-errors/errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"net/http"
+)
+
+// RequestSchema represents the JSON Schema validation of the client request of an endpoint.
+type RequestSchema struct {
+	// jsonSchema represents the compiled JSON Schema.
+	jsonSchema *JsonSchema
+	// statusCode represents the status code of the response when the request doesn't match the schema.
+	statusCode int
+}
+
+// newRequestSchema creates a new instance of RequestSchema based on the provided requestSchemaDTO.
+// If the requestSchemaDTO is nil, it returns nil.
+func newRequestSchema(requestSchemaDTO *dto.RequestSchema) *RequestSchema {
+	if helper.IsNil(requestSchemaDTO) {
+		return nil
+	}
+	return &RequestSchema{
+		jsonSchema: newJsonSchema(requestSchemaDTO.File, requestSchemaDTO.Schema),
+		statusCode: requestSchemaDTO.StatusCode,
+	}
+}
+
+// File returns the path of the JSON Schema file.
+func (r *RequestSchema) File() string {
+	if helper.IsNil(r.jsonSchema) {
+		return ""
+	}
+	return r.jsonSchema.File()
+}
+
+// Schema returns the inline JSON Schema.
+func (r *RequestSchema) Schema() any {
+	if helper.IsNil(r.jsonSchema) {
+		return nil
+	}
+	return r.jsonSchema.Schema()
+}
+
+// StatusCode returns the status code of the response when the request doesn't match the schema, if not configured
+// it returns http.StatusBadRequest.
+func (r *RequestSchema) StatusCode() int {
+	if helper.IsGreaterThan(r.statusCode, 0) {
+		return r.statusCode
+	}
+	return http.StatusBadRequest
+}
+
+// Validate checks the configuration of the request schema, returning an error if the schema is not informed, could
+// not be compiled, or the status code is not 400 or 422.
+func (r *RequestSchema) Validate() error {
+	if helper.IsNil(r.jsonSchema) {
+		return errors.New("file or schema must be informed")
+	} else if helper.IsNotNil(r.jsonSchema.Err()) {
+		return errors.New(errors.Details(r.jsonSchema.Err()).GetMessage())
+	} else if helper.IsNotEqualTo(r.StatusCode(), http.StatusBadRequest) &&
+		helper.IsNotEqualTo(r.StatusCode(), http.StatusUnprocessableEntity) {
+		return errors.New("status-code must be 400 or 422")
+	}
+	return nil
+}
+
+// Violations validates the request against the schema, returning the list of violations. If the instance is nil,
+// the request is considered valid.
+func (r *RequestSchema) Violations(requestVO *Request) []SchemaViolation {
+	if helper.IsNil(r) {
+		return nil
+	}
+	return r.jsonSchema.Validate(r.document(requestVO))
+}
+
+// document builds the document validated by the schema, with the fields "body", "query" and "params" of the
+// request. The query values with a single value are strings, and with multiple values are arrays of strings.
+func (r *RequestSchema) document(requestVO *Request) map[string]any {
+	var body any
+	if helper.IsNotNil(requestVO.Body()) {
+		body = requestVO.Body().Interface()
+	}
+
+	query := map[string]any{}
+	for key, values := range requestVO.Query() {
+		if helper.Equals(len(values), 1) {
+			query[key] = values[0]
+		} else {
+			query[key] = values
+		}
+	}
+
+	params := map[string]any{}
+	for key, value := range requestVO.Params() {
+		params[key] = value
+	}
+
+	return map[string]any{
+		"body":   body,
+		"query":  query,
+		"params": params,
+	}
+}
@@ -17,12 +17,17 @@
 package vo
 
 import (
+	"encoding/json"
 	"github.com/GabrielHCataldo/go-errors/errors"
 	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/mapper"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/consts"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
 	"net/http"
+	"strconv"
 	"time"
 )
 
@@ -61,6 +66,12 @@ type Response struct {
 	// The Abort method returns the value of the abort flag.
 	// The AbortResponse method checks if the abort flag is set to true.
 	abort bool
+	// failed is a flag in the Response object that indicates whether the response is a gateway error response, it
+	// is final and should not be rebuilt from the history.
+	failed bool
+	// formatted is a flag in the Response object that indicates whether the body of the gateway error response was
+	// already formatted with the error format of the endpoint, see FormatError.
+	formatted bool
 	// history represents the history of backend responses in the Response object.
 	history responseHistory
 }
@@ -77,6 +88,10 @@ type responseHistory []*backendResponse
 // This struct is typically used in conjunction with the newErrorBody function to generate a JSON response body
 // with error details based on the Endpoint and error provided.
 type errorResponseBody struct {
+	// Code represents the stable code of the error, see mapper.GatewayError.
+	Code string `json:"code,omitempty"`
+	// Retryable indicates whether the request may succeed if it is retried.
+	Retryable bool `json:"retryable,omitempty"`
 	// File represents the file name or path where the error occurred.
 	File string `json:"file"`
 	// Line represents the line number where the error occurred
@@ -85,10 +100,37 @@ type errorResponseBody struct {
 	Endpoint string `json:"endpoint"`
 	// Message represents the error message.
 	Message string `json:"message"`
+	// Modifier represents the modifier that failed, only present on modifier errors.
+	Modifier *errorModifier `json:"modifier,omitempty"`
+	// Violations represents the list of schema violations, only present on schema errors.
+	Violations []SchemaViolation `json:"violations,omitempty"`
 	// Timestamp represents the timestamp when the error occurred.
 	Timestamp time.Time `json:"timestamp"`
 }
 
+// errorModifier represents the identification of a modifier that failed in the error response body.
+type errorModifier struct {
+	// Context represents the context of the modifier.
+	Context enum.ModifierContext `json:"context,omitempty"`
+	// Scope represents the scope of the modifier.
+	Scope enum.ModifierScope `json:"scope,omitempty"`
+	// Action represents the action of the modifier.
+	Action enum.ModifierAction `json:"action,omitempty"`
+	// Key represents the key of the modifier.
+	Key string `json:"key,omitempty"`
+}
+
+// newErrorModifier creates a new errorModifier with the identification of the modifier.
+func newErrorModifier(context enum.ModifierContext, scope enum.ModifierScope, action enum.ModifierAction,
+	key string) *errorModifier {
+	return &errorModifier{
+		Context: context,
+		Scope:   scope,
+		Action:  action,
+		Key:     key,
+	}
+}
+
 // NewResponse creates a new Response object with the given Endpoint.
 // The StatusCode is set to http.StatusNoContent.
 // Returns the newly created Response object.
@@ -100,12 +142,14 @@ func NewResponse(endpointVO *Endpoint) *Response {
 }
 
 // NewResponseByCache creates a new Response object with the given endpoint and cache response.
-// The header of the cache response is modified to include the XGopenCache and XGopenCacheTTL headers.
+// The header of the cache response is modified to include the XGopenCache header, with the cache status, the
+// XGopenCacheTTL header and the Age header.
 // Returns the newly created Response object.
-func NewResponseByCache(endpointVO *Endpoint, cacheResponseVO *CacheResponse) *Response {
+func NewResponseByCache(endpointVO *Endpoint, cacheResponseVO *CacheResponse, cacheStatus enum.CacheStatus) *Response {
 	header := cacheResponseVO.Header
-	header = header.Set(consts.XGopenCache, helper.SimpleConvertToString(true))
+	header = header.Set(consts.XGopenCache, string(cacheStatus))
 	header = header.Set(consts.XGopenCacheTTL, cacheResponseVO.TTL())
+	header = header.Set("Age", cacheResponseVO.Age())
 	return &Response{
 		endpoint:   endpointVO,
 		statusCode: cacheResponseVO.StatusCode,
@@ -123,6 +167,7 @@ func NewResponseByErr(endpointVO *Endpoint, statusCode int, err error) *Response
 		statusCode: statusCode,
 		header:     newHeaderFailed(),
 		abort:      true,
+		failed:     true,
 		body:       newErrorBody(endpointVO.path, err),
 	}
 }
@@ -148,6 +193,18 @@ func NewCacheResponse(responseVO *Response, duration time.Duration) *CacheRespon
 	}
 }
 
+// NewCacheResponseByBackendResponse creates a new CacheResponse object with the status code, header and body of the
+// backend response, cached by the BackendCache before the response modifiers, and the given duration.
+func NewCacheResponseByBackendResponse(backendResponseVO *backendResponse, duration time.Duration) *CacheResponse {
+	return &CacheResponse{
+		StatusCode: backendResponseVO.StatusCode(),
+		Header:     backendResponseVO.Header(),
+		Body:       newCacheBody(backendResponseVO.Body()),
+		Duration:   duration.String(),
+		CreatedAt:  time.Now(),
+	}
+}
+
 // ModifyLastBackendResponse modifies the last backendResponse in the history list of the Response object.
 // Replaces the last backendResponse with the provided backendResponseVO.
 // Returns the modified Response object with the updated history.
@@ -160,35 +217,271 @@ func (r *Response) ModifyLastBackendResponse(backendResponseVO *backendResponse)
 	return r.notifyDataChanged(history)
 }
 
+// TransformLastBackendResponse applies the transform steps to the body of the last backendResponse in the history
+// list, in the order provided. Returns the modified Response object with the updated history.
+func (r *Response) TransformLastBackendResponse(transforms []Transform) *Response {
+	lastBackendResponse := r.LastBackendResponse()
+	if helper.IsNil(lastBackendResponse) || helper.IsEmpty(transforms) {
+		return r
+	}
+
+	// aplicamos os passos no body da última resposta
+	body := lastBackendResponse.Body()
+	for _, transform := range transforms {
+		body = transform.Apply(body)
+	}
+
+	return r.ModifyLastBackendResponse(lastBackendResponse.ModifyBody(body))
+}
+
+// ProjectLastBackendResponse applies the projections to the body of the last backendResponse in the history list,
+// in the order provided, nil projections are ignored. Returns the modified Response object with the updated history.
+func (r *Response) ProjectLastBackendResponse(projections ...*Projection) *Response {
+	lastBackendResponse := r.LastBackendResponse()
+	if helper.IsNil(lastBackendResponse) {
+		return r
+	}
+
+	// aplicamos as projeções no body da última resposta
+	body := lastBackendResponse.Body()
+	for _, projection := range projections {
+		body = projection.Apply(body)
+	}
+	if body == lastBackendResponse.Body() {
+		return r
+	}
+
+	return r.ModifyLastBackendResponse(lastBackendResponse.ModifyBody(body))
+}
+
+// Redact applies the redaction rules to the header and body of the final response, the history remains unchanged.
+// If the rules are empty, it returns the Response unchanged.
+func (r *Response) Redact(rules RedactionRules) *Response {
+	if helper.IsEmpty(rules) {
+		return r
+	}
+	return r.SetHeader(rules.Header(r.Header())).SetBody(rules.Body(r.Body()))
+}
+
+// ValidateLastBackendResponse validates the body of the last backendResponse in the history list against the
+// response schema, before the modifiers. With the enum.ResponseSchemaModeStrip mode, the properties not declared on
+// the schema are removed before the validation. If the body doesn't match the schema, the violations are logged with
+// the trace id, and with the enum.ResponseSchemaModeFail mode the last backendResponse is replaced by a bad gateway
+// response with the violations, so the abort rules of the endpoint apply. Returns the Response object with the
+// updated history, or unchanged if the schema is nil.
+func (r *Response) ValidateLastBackendResponse(responseSchema *ResponseSchema, traceId string) *Response {
+	lastBackendResponse := r.LastBackendResponse()
+	if helper.IsNil(responseSchema) || helper.IsNil(lastBackendResponse) {
+		return r
+	}
+
+	// removemos as propriedades não declaradas caso configurado
+	body := lastBackendResponse.Body()
+	if helper.Equals(responseSchema.Mode(), enum.ResponseSchemaModeStrip) {
+		body = responseSchema.Strip(body)
+	}
+
+	// validamos o body, caso tenha violações imprimimos o log com elas
+	violations := responseSchema.Violations(body)
+	if helper.IsNotEmpty(violations) {
+		logger.Warning("Backend response does not match the response-schema! traceId:", traceId, "violations:",
+			helper.SimpleConvertToString(violations))
+	}
+
+	// caso seja para falhar, substituímos a resposta do backend por um erro de gateway
+	if helper.IsNotEmpty(violations) && helper.Equals(responseSchema.Mode(), enum.ResponseSchemaModeFail) {
+		err := errors.New("Backend response does not match the response-schema!")
+		lastBackendResponse = lastBackendResponse.ModifyStatusCode(http.StatusBadGateway).
+			ModifyBody(newSchemaErrorBody(r.endpoint.path, violations, err))
+		return r.ModifyLastBackendResponse(lastBackendResponse)
+	} else if body == lastBackendResponse.Body() {
+		return r
+	}
+
+	return r.ModifyLastBackendResponse(lastBackendResponse.ModifyBody(body))
+}
+
+// SetStatusCode returns a new Response with the provided status code, the other fields remain unchanged.
+// It doesn't notify the history, so it is used to modify the final response.
+func (r *Response) SetStatusCode(statusCode int) *Response {
+	return &Response{
+		endpoint:   r.endpoint,
+		statusCode: statusCode,
+		header:     r.header,
+		body:       r.body,
+		abort:      r.abort,
+		failed:     r.failed,
+		formatted:  r.formatted,
+		history:    r.history,
+	}
+}
+
+// SetHeader returns a new Response with the provided header, the other fields remain unchanged.
+// It doesn't notify the history, so it is used to modify the final response.
+func (r *Response) SetHeader(header Header) *Response {
+	return &Response{
+		endpoint:   r.endpoint,
+		statusCode: r.statusCode,
+		header:     header,
+		body:       r.body,
+		abort:      r.abort,
+		failed:     r.failed,
+		formatted:  r.formatted,
+		history:    r.history,
+	}
+}
+
+// SetBody returns a new Response with the provided body, the other fields remain unchanged.
+// It doesn't notify the history, so it is used to modify the final response.
+func (r *Response) SetBody(body *Body) *Response {
+	return &Response{
+		endpoint:   r.endpoint,
+		statusCode: r.statusCode,
+		header:     r.header,
+		body:       body,
+		abort:      r.abort,
+		failed:     r.failed,
+		formatted:  r.formatted,
+		history:    r.history,
+	}
+}
+
+// SetValidators returns a new Response with the ETag and Last-Modified validators, used to answer the conditional
+// requests, see NotModified. The validators of the backends are forwarded if there is only one of each, otherwise
+// the ETag is generated with the hash of the body and the Last-Modified with the current time.
+// It doesn't notify the history, so it is used to modify the final response.
+func (r *Response) SetValidators() *Response {
+	header := r.header
+	if helper.IsNotEqualTo(len(header.Http().Values(etagHeader)), 1) {
+		header = header.Set(etagHeader, newETag(r.BytesBody()))
+	}
+	if helper.IsNotEqualTo(len(header.Http().Values("Last-Modified")), 1) {
+		header = header.Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+	}
+	return r.SetHeader(header)
+}
+
+// NotModified returns whether the conditional request can be answered with http.StatusNotModified, only checked on
+// the GET and HEAD requests with http.StatusOK responses. If the request has the If-None-Match header, it is matched
+// against the ETag header, see matchETag, otherwise the If-Modified-Since header is compared to the Last-Modified
+// header.
+func (r *Response) NotModified(requestVO *Request) bool {
+	// verificamos se a requisição e a resposta podem ser condicionais
+	if helper.IsNotEqualTo(r.statusCode, http.StatusOK) || (helper.IsNotEqualTo(requestVO.Method(), http.MethodGet) &&
+		helper.IsNotEqualTo(requestVO.Method(), http.MethodHead)) {
+		return false
+	}
+
+	// o If-None-Match tem prioridade sobre o If-Modified-Since
+	if ifNoneMatch := requestVO.Header().Get("If-None-Match"); helper.IsNotEmpty(ifNoneMatch) {
+		return matchETag(ifNoneMatch, r.header.Get(etagHeader))
+	}
+	ifModifiedSince, err := http.ParseTime(requestVO.Header().Get("If-Modified-Since"))
+	if helper.IsNotNil(err) {
+		return false
+	}
+	lastModified, err := http.ParseTime(r.header.Get("Last-Modified"))
+	if helper.IsNotNil(err) {
+		return false
+	}
+	return !lastModified.After(ifModifiedSince)
+}
+
 // Append appends the backendResponseVO to the history list of the Response object.
 // Returns the modified Response object with updated history.
 // Does not modify other properties of the Response object.
 func (r *Response) Append(backendResponseVO *backendResponse) *Response {
-	// adicionamos na nova lista de histórico
-	history := r.history
-	history = append(history, backendResponseVO)
+	// adicionamos na nova lista de histórico, sem compartilhar o array com outras instâncias
+	history := append(r.history[:r.history.Size():r.history.Size()], backendResponseVO)
+
+	// atualizamos os dados a partir do histórico alterado
+	return r.notifyDataChanged(history)
+}
+
+// ForEachElements returns the elements of the array iterated by the fan-out, obtained from the body of the backend
+// response named by the fan-out, or the last backend response if not named. If the backend response is not found,
+// or the path is not an array, it returns nil.
+func (r *Response) ForEachElements(forEachVO *ForEach) []gjson.Result {
+	index := r.history.indexByName(forEachVO.Backend())
+	if helper.IsLessThan(index, 0) || helper.IsNil(r.history[index].body) || r.history[index].body.IsNotJson() {
+		return nil
+	}
+	result := gjson.Parse(r.history[index].body.String())
+	if helper.IsNotEmpty(forEachVO.Path()) {
+		result = result.Get(forEachVO.Path())
+	}
+	if !result.IsArray() {
+		return nil
+	}
+	return result.Array()
+}
+
+// ForEach merges the bodies of the responses of the fan-out calls into the elements of the iterated backend
+// response, each under the key of the fan-out, and appends a backend response of the fan-out to the history. The
+// responses are aligned with the elements returned by ForEachElements. The fan-out backend response is always
+// omitted, its status code is http.StatusOK if all calls succeeded, otherwise the status code of the first call that
+// failed in the order of the elements. If the endpoint aborts by this status code, the response of the first call that
+// failed is returned.
+func (r *Response) ForEach(forEachVO *ForEach, backendVO *Backend, responses []*Response) *Response {
+	// mesclamos o body de cada chamada bem-sucedida no seu elemento
+	statusCode := http.StatusOK
+	var firstFailedResponseVO *Response
+	raws := map[int]string{}
+	for i, responseVO := range responses {
+		lastBackendResponseVO := responseVO.LastBackendResponse()
+		if responseVO.Failed() || helper.IsNil(lastBackendResponseVO) || !lastBackendResponseVO.Ok() {
+			if helper.IsNil(firstFailedResponseVO) {
+				firstFailedResponseVO = responseVO
+				statusCode = responseVO.StatusCode()
+			}
+		} else if helper.IsNotNil(lastBackendResponseVO.body) && lastBackendResponseVO.body.IsJson() {
+			raws[i] = lastBackendResponseVO.body.String()
+		} else if helper.IsNotNil(lastBackendResponseVO.body) {
+			rawBytes, _ := json.Marshal(lastBackendResponseVO.body.String())
+			raws[i] = string(rawBytes)
+		}
+	}
+
+	// caso o endpoint aborte pela falha, retornamos a resposta da primeira chamada que falhou
+	if helper.IsNotNil(firstFailedResponseVO) && r.endpoint.AbortSequencial(statusCode) {
+		return firstFailedResponseVO
+	}
+
+	// substituímos a resposta iterada pela enriquecida, caso tenha algum resultado
+	history := append(responseHistory{}, r.history...)
+	if index := history.indexByName(forEachVO.Backend()); helper.IsNotEmpty(raws) {
+		bodyStr := history[index].body.String()
+		for i := range responses {
+			if raw, ok := raws[i]; ok {
+				bodyStr, _ = sjson.SetRaw(bodyStr, forEachVO.elementPath(i), raw)
+			}
+		}
+		history[index] = history[index].ModifyBody(&Body{
+			contentType: enum.ContentTypeJson,
+			value:       helper.SimpleConvertToBuffer(bodyStr),
+		})
+	}
+
+	// adicionamos a resposta do for-each, sempre omitida
+	history = append(history, &backendResponse{
+		name:       backendVO.Name(),
+		omit:       true,
+		statusCode: statusCode,
+		header:     Header{},
+	})
 
 	// atualizamos os dados a partir do histórico alterado
 	return r.notifyDataChanged(history)
 }
 
 // Error constructs a standard gateway error response based on the received error.
-// It builds the response's status code from the received error.
-// If the error contains mapper.ErrBadGateway, the status code is set to http.StatusBadGateway.
-// If the error contains mapper.ErrGatewayTimeout, the status code is set to http.StatusGatewayTimeout.
-// Otherwise, the status code is set to http.StatusInternalServerError.
+// It builds the response's status code from the received error, the status code of the mapper.GatewayError found on
+// the chain of the error, or http.StatusInternalServerError if the error is not a mapper.GatewayError.
 // It constructs the default gateway error response by setting the status code, header, body, and abort properties.
 // Returns the constructed Response object representing the gateway error response.
 func (r *Response) Error(path string, err error) *Response {
 	// construímos o statusCode de resposta a partir do erro recebido
-	var statusCode int
-	if errors.Contains(err, mapper.ErrBadGateway) {
-		statusCode = http.StatusBadGateway
-	} else if errors.Contains(err, mapper.ErrGatewayTimeout) {
-		statusCode = http.StatusGatewayTimeout
-	} else {
-		statusCode = http.StatusInternalServerError
-	}
+	statusCode := mapper.StatusCodeByErr(err)
 	// construímos a resposta de erro padrão do gateway
 	return &Response{
 		endpoint:   r.endpoint,
@@ -196,6 +489,56 @@ func (r *Response) Error(path string, err error) *Response {
 		header:     newHeaderFailed(),
 		body:       newErrorBody(path, err),
 		abort:      true,
+		failed:     true,
+	}
+}
+
+// FormatError formats the body of the error response generated by the gateway with the error format of the endpoint,
+// using the request to fill the instance of the problem details and the #request values of the template. If the
+// response is not a gateway error response, see Failed, or is already formatted, it returns the same instance.
+func (r *Response) FormatError(requestVO *Request) *Response {
+	if !r.failed || r.formatted {
+		return r
+	}
+	return &Response{
+		endpoint:   r.endpoint,
+		statusCode: r.statusCode,
+		header:     r.header,
+		body:       r.endpoint.ErrorFormat().Body(r.statusCode, r.body, requestVO),
+		abort:      r.abort,
+		failed:     r.failed,
+		formatted:  true,
+		history:    r.history,
+	}
+}
+
+// ModifierError constructs the gateway error response of a modifier that failed with the on-error policy
+// enum.ModifierOnErrorFail. The status code is set to http.StatusInternalServerError and the body names the modifier
+// that failed. The history is kept, but the response is final, see Failed.
+func (r *Response) ModifierError(modifier *errorModifier, err error) *Response {
+	return &Response{
+		endpoint:   r.endpoint,
+		statusCode: http.StatusInternalServerError,
+		header:     newHeaderFailed(),
+		body:       newModifierErrorBody(r.endpoint.path, modifier, err),
+		abort:      true,
+		failed:     true,
+		history:    r.history,
+	}
+}
+
+// SchemaError constructs the gateway error response of a document that doesn't match a JSON Schema, with the
+// provided status code and the list of violations in the body. The history is kept, but the response is final,
+// see Failed.
+func (r *Response) SchemaError(statusCode int, violations []SchemaViolation, err error) *Response {
+	return &Response{
+		endpoint:   r.endpoint,
+		statusCode: statusCode,
+		header:     newHeaderFailed(),
+		body:       newSchemaErrorBody(r.endpoint.path, violations, err),
+		abort:      true,
+		failed:     true,
+		history:    r.history,
 	}
 }
 
@@ -206,6 +549,12 @@ func (r *Response) Abort() bool {
 	return r.abort
 }
 
+// Failed returns true if the Response object is a gateway error response, which is final and should be returned
+// without further processing.
+func (r *Response) Failed() bool {
+	return r.failed
+}
+
 // AbortResponse constructs a new Response object to represent an aborted response.
 // It obtains the last backendResponse from the history of the original Response object.
 // It checks if all backends of the endpoint have been processed.
@@ -251,6 +600,22 @@ func (r *Response) LastBackendResponse() *backendResponse {
 	return r.history[len(r.history)-1]
 }
 
+// Endpoint returns the endpoint of the Response object.
+func (r *Response) Endpoint() *Endpoint {
+	return r.endpoint
+}
+
+// BackendResponseByName returns the last backendResponse in the history list of the Response object
+// generated by the backend with the provided name. If not found, it returns nil.
+func (r *Response) BackendResponseByName(name string) *backendResponse {
+	for i := len(r.history) - 1; i >= 0; i-- {
+		if helper.Equals(r.history[i].Name(), name) {
+			return r.history[i]
+		}
+	}
+	return nil
+}
+
 // StatusCode returns the status code of the Response object.
 func (r *Response) StatusCode() int {
 	return r.statusCode
@@ -270,7 +635,11 @@ func (r *Response) Header() Header {
 // it returns an empty string.
 func (r *Response) ContentType() enum.ContentType {
 	responseEncode := r.endpoint.ResponseEncode()
-	if responseEncode.IsEnumValid() {
+	// as respostas de erro do gateway em JSON usam o content type do formato de erro
+	if r.failed && (!responseEncode.IsEnumValid() || helper.Equals(responseEncode, enum.ResponseEncodeJson)) &&
+		helper.IsNotNil(r.body) && r.body.IsJson() {
+		return r.endpoint.ErrorFormat().ContentType()
+	} else if responseEncode.IsEnumValid() {
 		return responseEncode.ContentType()
 	} else if helper.IsNotNil(r.Body()) {
 		return r.Body().ContentType()
@@ -344,15 +713,33 @@ func (r *Response) notifyDataChanged(history responseHistory) *Response {
 	filteredHistory := history.Filter(completed)
 
 	// obtemos o status code a partir do histórico filtrado
-	statusCodeByHistory := filteredHistory.StatusCode()
+	statusCodeByHistory := filteredHistory.StatusCode(r.endpoint.StatusCodeStrategy())
+	// mapeamos o código de status resolvido caso configurado
+	statusCodeByHistory = r.endpoint.MapStatusCode(statusCodeByHistory)
 
 	// criamos o header a partir dos valores complete e success
 	header := newResponseHeader(completed, filteredHistory.Success())
 	// agregamos os headers do histórico filtrado
 	header = header.Aggregate(filteredHistory.Header())
+	// caso a estratégia seja parcial, indicamos se alguma resposta falhou
+	if helper.Equals(r.endpoint.StatusCodeStrategy(), enum.StatusCodeStrategyPartial) {
+		header = header.Set(consts.XGopenPartial, helper.SimpleConvertToString(!filteredHistory.Success()))
+	}
 
 	// obtemos o body a partir do histórico filtrado
-	bodyByHistory := filteredHistory.Body(r.endpoint.AggregateResponses())
+	bodyByHistory, err := filteredHistory.Body(r.endpoint.AggregateResponses(), r.endpoint.Aggregation())
+	// caso a agregação falhe por conflito, retornamos o erro como resposta final mantendo o histórico
+	if helper.IsNotNil(err) {
+		return &Response{
+			endpoint:   r.endpoint,
+			statusCode: http.StatusBadGateway,
+			header:     newHeaderFailed(),
+			body:       newErrorBody(r.endpoint.Path(), err),
+			abort:      true,
+			failed:     true,
+			history:    history,
+		}
+	}
 
 	// construímos o novo objeto de valor,
 	return &Response{
@@ -384,14 +771,37 @@ func (r *Response) abortByLastResponse() (abortSequencial bool) {
 }
 
 // TTL calculates the time to live (TTL) for the CacheResponse object.
-// It subtracts the current time from the sum of the CreatedAt time and the Duration of the CacheResponse.
+// It subtracts the current time from the sum of the CreatedAt time and the Duration of the CacheResponse, 0 if the
+// cache response is already stale.
 // Returns the TTL duration as a string representation.
 func (c CacheResponse) TTL() string {
-	duration, _ := time.ParseDuration(c.Duration)
-	sub := c.CreatedAt.Add(duration).Sub(time.Now())
+	sub := max(c.expiresAt().Sub(time.Now()), 0)
 	return sub.String()
 }
 
+// Age returns the age of the CacheResponse in seconds, the time since the CreatedAt time plus the Age header of the
+// origin response, as a string.
+func (c CacheResponse) Age() string {
+	age := time.Since(c.CreatedAt) + originAge(c.Header)
+	return strconv.Itoa(int(age.Seconds()))
+}
+
+// Fresh returns whether the CacheResponse is still within its Duration.
+func (c CacheResponse) Fresh() bool {
+	return time.Now().Before(c.expiresAt())
+}
+
+// StaleWithin returns whether the CacheResponse is stale, but expired less than the window ago.
+func (c CacheResponse) StaleWithin(window time.Duration) bool {
+	return !c.Fresh() && time.Now().Before(c.expiresAt().Add(window))
+}
+
+// expiresAt returns the time when the CacheResponse expires, the CreatedAt time plus the Duration.
+func (c CacheResponse) expiresAt() time.Time {
+	duration, _ := time.ParseDuration(c.Duration)
+	return c.CreatedAt.Add(duration)
+}
+
 // Size returns the number of elements in the responseHistory.
 func (r responseHistory) Size() int {
 	return len(r)
@@ -410,6 +820,16 @@ func (r responseHistory) Success() bool {
 	return true
 }
 
+// anySuccess returns true if at least one backend response of the history succeeded, see backendResponse.Ok.
+func (r responseHistory) anySuccess() bool {
+	for _, backendResponseVO := range r {
+		if backendResponseVO.Ok() {
+			return true
+		}
+	}
+	return false
+}
+
 // Filter filters the response history based on the performOmission flag.
 // If performOmission is true, backend responses with the omit flag set to true are skipped.
 // The filtered backend responses are added to a new responseHistory slice, which is returned.
@@ -439,20 +859,40 @@ func (r responseHistory) MultipleResponse() bool {
 	return helper.IsGreaterThan(r.Size(), 1)
 }
 
-// StatusCode function provides HTTP Status code based on the response history.
-// It checks if there are multiple or single responses, and returns the relevant HTTP status code.
-// If there is more than one response, it returns HTTP status code 200 (OK).
+// StatusCode function provides HTTP Status code based on the response history and the strategy.
+// If there is more than one response, it resolves the status code by the strategy:
+//   - enum.StatusCodeStrategyMostFrequent: the most frequent status code, the ties are resolved by the worst one.
+//   - enum.StatusCodeStrategyWorst: the greatest status code.
+//   - enum.StatusCodeStrategyFirst: the status code of the first response.
+//   - enum.StatusCodeStrategyLast: the status code of the last response.
+//
 // If there is a single response, it returns the HTTP status code of that particular response.
+// With the enum.StatusCodeStrategyPartial strategy, it returns HTTP status code 200 (OK) if at least one response
+// succeeded, otherwise the worst status code, as there is no partial result.
 // If there are no responses, it returns HTTP status code 204 (No Content).
-func (r responseHistory) StatusCode() int {
-	// se tiver mais de 1 resposta obtemos o código de status mais frequente
-	if r.MultipleResponse() {
-		return r.mostFrequentStatusCode()
+func (r responseHistory) StatusCode(strategy enum.StatusCodeStrategy) int {
+	if helper.IsEmpty(r) {
+		// resposta padrão de sucesso
+		return http.StatusNoContent
+	} else if helper.Equals(strategy, enum.StatusCodeStrategyPartial) && r.anySuccess() {
+		return http.StatusOK
+	} else if helper.Equals(strategy, enum.StatusCodeStrategyPartial) {
+		return r.worstStatusCode()
 	} else if r.SingleResponse() {
 		return r[0].statusCode
 	}
-	// resposta padrão de sucesso
-	return http.StatusNoContent
+
+	// se tiver mais de 1 resposta obtemos o código de status pela estratégia
+	switch strategy {
+	case enum.StatusCodeStrategyWorst:
+		return r.worstStatusCode()
+	case enum.StatusCodeStrategyFirst:
+		return r[0].statusCode
+	case enum.StatusCodeStrategyLast:
+		return r.last().statusCode
+	default:
+		return r.mostFrequentStatusCode()
+	}
 }
 
 // Header iterates over the responseHistory and aggregates non-nil headers from each backendResponseVO.
@@ -474,23 +914,27 @@ func (r responseHistory) Header() Header {
 	return h
 }
 
-// Body function takes 'aggregateResponses' boolean as argument.
+// Body function takes 'aggregateResponses' boolean and the aggregation options as arguments.
 // If there are multiple responses present, it aggregates them based on
 // the boolean parameter. If the boolean parameter is true, it returns the result
-// of 'aggregateBody' function else 'aggregatedBodies' function is called.
+// of 'aggregateBody' function, or 'aggregateBodyWithOptions' if the aggregation options are configured,
+// else 'aggregatedBodies' function is called.
 // For a single response case, the body of the single response is returned.
 // If no responses are available, returns an empty Body struct.
-func (r responseHistory) Body(aggregateResponses bool) *Body {
+// An error is returned only when the aggregation options reject a conflict.
+func (r responseHistory) Body(aggregateResponses bool, aggregation *Aggregation) (*Body, error) {
 	if r.MultipleResponse() {
-		if aggregateResponses {
-			return r.aggregateBody()
+		if aggregateResponses && helper.IsNotNil(aggregation) {
+			return r.aggregateBodyWithOptions(aggregation)
+		} else if aggregateResponses {
+			return r.aggregateBody(), nil
 		} else {
-			return r.sliceOfBodies()
+			return r.sliceOfBodies(), nil
 		}
 	} else if r.SingleResponse() {
-		return r.body()
+		return r.body(), nil
 	}
-	return nil
+	return nil, nil
 }
 
 // Eval iterates over the responseHistory and calls the Eval method on each backendResponseVO.
@@ -504,6 +948,20 @@ func (r responseHistory) Eval() []any {
 	return evalHistory
 }
 
+// indexByName returns the index of the last backendResponse with the provided name, or the index of the last
+// backendResponse if the name is empty. If not found, it returns -1.
+func (r responseHistory) indexByName(name string) int {
+	if helper.IsEmpty(name) {
+		return r.Size() - 1
+	}
+	for i := r.Size() - 1; i >= 0; i-- {
+		if helper.Equals(r[i].Name(), name) {
+			return i
+		}
+	}
+	return -1
+}
+
 // last returns the last backendResponse in the responseHistory list.
 // Returns the last backendResponse object.
 // Does not modify the responseHistory.
@@ -554,6 +1012,42 @@ func (r responseHistory) aggregateBody() *Body {
 	return bodyHistory
 }
 
+// aggregateBodyWithOptions aggregates the body from each backend response in the response history using the
+// aggregation options. The responses grouped by key are aggregated as an object with only the key, so the conflict
+// policy is applied to them too. Returns an error if the aggregation options reject a conflict.
+func (r responseHistory) aggregateBodyWithOptions(aggregation *Aggregation) (*Body, error) {
+	// listamos os objetos na ordem do histórico, garantindo o mesmo resultado independente da ordem de execução
+	var objects []string
+	for index, backendResponseVO := range r {
+		// se tiver nil pulamos para o próximo
+		if helper.IsNil(backendResponseVO.body) {
+			continue
+		}
+		body := backendResponseVO.Body()
+		if backendResponseVO.GroupResponse() {
+			var value any = body.Interface()
+			if helper.Equals(body.ContentType(), enum.ContentTypeText) {
+				value = body.String()
+			}
+			objects = append(objects, helper.SimpleConvertToString(map[string]any{
+				backendResponseVO.Key(index): value,
+			}))
+		} else if body.IsJson() {
+			objects = append(objects, body.String())
+		}
+	}
+
+	// agregamos os objetos
+	aggregated, err := aggregation.Aggregate(objects)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return &Body{
+		contentType: enum.ContentTypeJson,
+		value:       helper.SimpleConvertToBuffer(aggregated),
+	}, nil
+}
+
 // sliceOfBodies iterates over the response history and constructs a slice of bodies from the backend responses.
 // If a backend response has an empty body, it is skipped.
 // For each backend response with a non-empty body, a bodyBackendResponse object is created and added to the list of bodies.
@@ -578,7 +1072,7 @@ func (r responseHistory) sliceOfBodies() *Body {
 
 // mostFrequentStatusCode returns the most frequent status code from the response history.
 // It counts the occurrences of each status code and finds the one with the highest count.
-// If multiple status codes have the same highest count, it returns the first one encountered.
+// If multiple status codes have the same highest count, it returns the greatest one, so the result is deterministic.
 // Returns the most frequent status code as an integer.
 // If the response history is empty, it returns 0.
 func (r responseHistory) mostFrequentStatusCode() int {
@@ -587,10 +1081,12 @@ func (r responseHistory) mostFrequentStatusCode() int {
 		statusCodes[backendResponseVO.statusCode]++
 	}
 
+	// em caso de empate, o pior código de status é o escolhido, para o resultado ser sempre o mesmo
 	maxCount := 0
 	mostFrequentCode := 0
 	for code, count := range statusCodes {
-		if count >= maxCount {
+		if helper.IsGreaterThan(count, maxCount) || (helper.Equals(count, maxCount) &&
+			helper.IsGreaterThan(code, mostFrequentCode)) {
 			mostFrequentCode = code
 			maxCount = count
 		}
@@ -598,3 +1094,14 @@ func (r responseHistory) mostFrequentStatusCode() int {
 
 	return mostFrequentCode
 }
+
+// worstStatusCode returns the greatest status code of the response history.
+func (r responseHistory) worstStatusCode() int {
+	worstCode := 0
+	for _, backendResponseVO := range r {
+		if helper.IsGreaterThan(backendResponseVO.statusCode, worstCode) {
+			worstCode = backendResponseVO.statusCode
+		}
+	}
+	return worstCode
+}
@@ -0,0 +1,183 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"github.com/GabrielHCataldo/go-errors/errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
+	"github.com/tidwall/gjson"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// ResponseSchema represents the JSON Schema validation of the backend response body.
+// The violations counter is shared by all copies of the instance, so it counts the violations of the backend during
+// the whole life of the configuration.
+type ResponseSchema struct {
+	// jsonSchema represents the compiled JSON Schema.
+	jsonSchema *JsonSchema
+	// mode represents what is done when the body doesn't match the schema.
+	mode enum.ResponseSchemaMode
+	// violations represents the number of backend responses that didn't match the schema.
+	violations *atomic.Int64
+}
+
+// newResponseSchema creates a new instance of ResponseSchema based on the provided responseSchemaDTO.
+// If the responseSchemaDTO is nil, it returns nil.
+func newResponseSchema(responseSchemaDTO *dto.ResponseSchema) *ResponseSchema {
+	if helper.IsNil(responseSchemaDTO) {
+		return nil
+	}
+	return &ResponseSchema{
+		jsonSchema: newJsonSchema(responseSchemaDTO.File, responseSchemaDTO.Schema),
+		mode:       responseSchemaDTO.Mode,
+		violations: &atomic.Int64{},
+	}
+}
+
+// File returns the path of the JSON Schema file.
+func (r *ResponseSchema) File() string {
+	if helper.IsNil(r.jsonSchema) {
+		return ""
+	}
+	return r.jsonSchema.File()
+}
+
+// Schema returns the inline JSON Schema.
+func (r *ResponseSchema) Schema() any {
+	if helper.IsNil(r.jsonSchema) {
+		return nil
+	}
+	return r.jsonSchema.Schema()
+}
+
+// Mode returns what is done when the body doesn't match the schema, if not configured it returns
+// enum.ResponseSchemaModeLog.
+func (r *ResponseSchema) Mode() enum.ResponseSchemaMode {
+	if helper.IsNotEmpty(r.mode) {
+		return r.mode
+	}
+	return enum.ResponseSchemaModeLog
+}
+
+// ViolationsCount returns the number of backend responses that didn't match the schema. If the instance is nil, it
+// returns 0.
+func (r *ResponseSchema) ViolationsCount() int64 {
+	if helper.IsNil(r) {
+		return 0
+	}
+	return r.violations.Load()
+}
+
+// Validate checks the configuration of the response schema, returning an error if the schema is not informed, could
+// not be compiled, or the mode is invalid.
+func (r *ResponseSchema) Validate() error {
+	if helper.IsNil(r.jsonSchema) {
+		return errors.New("file or schema must be informed")
+	} else if helper.IsNotNil(r.jsonSchema.Err()) {
+		return errors.New(errors.Details(r.jsonSchema.Err()).GetMessage())
+	} else if !r.Mode().IsEnumValid() {
+		return errors.New("mode", r.mode, "is invalid")
+	}
+	return nil
+}
+
+// Violations validates the body against the schema, returning the list of violations and incrementing the violations
+// counter if it doesn't match. If the instance or the body is nil, the body is considered valid.
+func (r *ResponseSchema) Violations(body *Body) []SchemaViolation {
+	if helper.IsNil(r) || helper.IsNil(body) {
+		return nil
+	}
+	violations := r.jsonSchema.Validate(body.Interface())
+	if helper.IsNotEmpty(violations) {
+		r.violations.Add(1)
+	}
+	return violations
+}
+
+// Strip removes the properties of the JSON body that are not declared on the properties or patternProperties of the
+// schema, walking the nested objects and the items of the arrays, returning a new Body instance. The objects whose
+// schema doesn't declare properties are kept unchanged. If the instance or the body is nil, or the body is not JSON,
+// the body is returned unchanged.
+func (r *ResponseSchema) Strip(body *Body) *Body {
+	if helper.IsNil(r) || helper.IsNil(r.jsonSchema) || helper.IsNil(body) || body.IsNotJson() {
+		return body
+	}
+	schema, ok := r.jsonSchema.Document().(map[string]any)
+	if !ok {
+		return body
+	}
+	return &Body{
+		contentType: body.contentType,
+		value:       helper.SimpleConvertToBuffer(stripJson(gjson.Parse(body.String()), schema)),
+	}
+}
+
+// stripJson returns the raw JSON value without the properties not declared on the schema.
+func stripJson(result gjson.Result, schema map[string]any) string {
+	if result.IsArray() {
+		items, ok := schema["items"].(map[string]any)
+		if !ok {
+			return result.Raw
+		}
+		var elements []string
+		result.ForEach(func(_, value gjson.Result) bool {
+			elements = append(elements, stripJson(value, items))
+			return true
+		})
+		return "[" + strings.Join(elements, ",") + "]"
+	} else if !result.IsObject() {
+		return result.Raw
+	}
+
+	properties, hasProperties := schema["properties"].(map[string]any)
+	patternProperties, hasPatternProperties := schema["patternProperties"].(map[string]any)
+	if !hasProperties && !hasPatternProperties {
+		return result.Raw
+	}
+
+	var fields []string
+	result.ForEach(func(key, value gjson.Result) bool {
+		// caso a propriedade não esteja declarada no schema, removemos
+		propertySchema, declared := stripPropertySchema(key.String(), properties, patternProperties)
+		if !declared {
+			return true
+		}
+		fields = append(fields, key.Raw+":"+stripJson(value, propertySchema))
+		return true
+	})
+	return "{" + strings.Join(fields, ",") + "}"
+}
+
+// stripPropertySchema returns the schema of the property declared on the properties or patternProperties, and false
+// if the property is not declared.
+func stripPropertySchema(key string, properties, patternProperties map[string]any) (map[string]any, bool) {
+	if propertySchema, ok := properties[key]; ok {
+		schema, _ := propertySchema.(map[string]any)
+		return schema, true
+	}
+	for pattern, propertySchema := range patternProperties {
+		if matched, err := regexp.MatchString(pattern, key); helper.IsNil(err) && matched {
+			schema, _ := propertySchema.(map[string]any)
+			return schema, true
+		}
+	}
+	return nil, false
+}
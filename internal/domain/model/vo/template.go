@@ -0,0 +1,55 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"regexp"
+)
+
+// templateRegex represents the regex used to find the `{{ expression }}` templates in a modifier value.
+var templateRegex = regexp.MustCompile(`\{\{(.+?)}}`)
+
+// findAllTemplates returns the indexes of all templates found in the value, each item contains the start and end of
+// the template followed by the start and end of the expression inside it.
+func findAllTemplates(value string) [][]int {
+	return templateRegex.FindAllStringSubmatchIndex(value, -1)
+}
+
+// isSingleTemplate checks if the value is composed by only one template, in this case the typed result of the
+// expression is used as the value.
+func isSingleTemplate(value string, templates [][]int) bool {
+	return helper.Equals(len(templates), 1) && helper.Equals(templates[0][0], 0) &&
+		helper.Equals(templates[0][1], len(value))
+}
+
+// parseTemplates parses all templates found in the value, returning the expressions by their raw expression, so
+// they are parsed only once, and the first parse error found. The malformed templates are not returned.
+func parseTemplates(value string) (map[string]*Expression, error) {
+	var firstErr error
+	expressions := map[string]*Expression{}
+	for _, template := range findAllTemplates(value) {
+		raw := value[template[2]:template[3]]
+		expression, err := newExpression(raw)
+		if helper.IsNotNil(err) && helper.IsNil(firstErr) {
+			firstErr = err
+		} else if helper.IsNil(err) {
+			expressions[raw] = expression
+		}
+	}
+	return expressions, firstErr
+}
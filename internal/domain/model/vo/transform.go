@@ -0,0 +1,340 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vo
+
+import (
+	"encoding/json"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"sort"
+	"strings"
+)
+
+// Transform represents a step of the transform pipeline applied to the backend response body.
+// Each step works over the array found on the path, or over the whole body if the path is empty, and produces a
+// new body with the array reshaped.
+type Transform struct {
+	// transformType represents the type of the step.
+	transformType enum.TransformType
+	// path represents the gjson path of the array to be transformed.
+	path string
+	// fields represents the mapping of the MAP step, new key by gjson path inside the element.
+	fields map[string]string
+	// keepOthers indicates if the MAP step keeps the fields that were not mapped.
+	keepOthers bool
+	// condition represents the predicate of the FILTER step.
+	condition *Condition
+	// key represents the gjson path inside the element used by the PLUCK and SORT steps.
+	key string
+	// desc indicates if the SORT step sorts in descending order.
+	desc bool
+	// separator represents the separator of the keys joined by the FLATTEN step.
+	separator string
+	// offset represents the number of elements skipped by the LIMIT step.
+	offset int
+	// limit represents the maximum number of elements kept by the LIMIT step.
+	limit int
+}
+
+// newTransforms creates a slice of Transform based on the provided slice of transformDTO.
+func newTransforms(transformsDTO []dto.Transform) []Transform {
+	var transforms []Transform
+	for _, transformDTO := range transformsDTO {
+		transforms = append(transforms, newTransform(transformDTO))
+	}
+	return transforms
+}
+
+// newTransform creates a new instance of Transform based on the provided transformDTO.
+// If the filter expression is malformed, a warning is logged and the filter removes all elements.
+func newTransform(transformDTO dto.Transform) Transform {
+	condition, err := newCondition(transformDTO.If)
+	if helper.IsNotNil(err) {
+		logger.Warning("Parse transform.if err:", err)
+		condition = newFalseCondition(transformDTO.If)
+	}
+	separator := transformDTO.Separator
+	if helper.IsEmpty(separator) {
+		separator = "_"
+	}
+	return Transform{
+		transformType: transformDTO.Type,
+		path:          transformDTO.Path,
+		fields:        transformDTO.Fields,
+		keepOthers:    transformDTO.KeepOthers,
+		condition:     condition,
+		key:           transformDTO.Key,
+		desc:          transformDTO.Desc,
+		separator:     separator,
+		offset:        transformDTO.Offset,
+		limit:         transformDTO.Limit,
+	}
+}
+
+// Type returns the type of the transform step.
+func (t Transform) Type() enum.TransformType {
+	return t.transformType
+}
+
+// Path returns the gjson path of the array to be transformed.
+func (t Transform) Path() string {
+	return t.path
+}
+
+// Fields returns the mapping of the MAP step.
+func (t Transform) Fields() map[string]string {
+	return t.fields
+}
+
+// KeepOthers returns true if the MAP step keeps the fields that were not mapped.
+func (t Transform) KeepOthers() bool {
+	return t.keepOthers
+}
+
+// If returns the raw expression of the FILTER step, or empty if not configured.
+func (t Transform) If() string {
+	if helper.IsNil(t.condition) {
+		return ""
+	}
+	return t.condition.Expression()
+}
+
+// Key returns the gjson path inside the element used by the PLUCK and SORT steps.
+func (t Transform) Key() string {
+	return t.key
+}
+
+// Desc returns true if the SORT step sorts in descending order.
+func (t Transform) Desc() bool {
+	return t.desc
+}
+
+// Separator returns the separator of the keys joined by the FLATTEN step.
+func (t Transform) Separator() string {
+	return t.separator
+}
+
+// Offset returns the number of elements skipped by the LIMIT step.
+func (t Transform) Offset() int {
+	return t.offset
+}
+
+// Limit returns the maximum number of elements kept by the LIMIT step.
+func (t Transform) Limit() int {
+	return t.limit
+}
+
+// Apply applies the transform step to the body, returning a new Body instance. If the body is nil or not JSON,
+// the type is invalid, or the value on the path is not compatible with the step, the body is returned unchanged.
+func (t Transform) Apply(body *Body) *Body {
+	if helper.IsNil(body) || body.IsNotJson() || !t.transformType.IsEnumValid() {
+		return body
+	}
+
+	// obtemos o valor a ser transformado pelo caminho configurado
+	document := body.String()
+	value := gjson.Parse(document)
+	if helper.IsNotEmpty(t.path) {
+		value = value.Get(t.path)
+	}
+
+	// transformamos o valor, caso não seja compatível retornamos o body original
+	transformed, ok := t.transform(value)
+	if !ok {
+		return body
+	}
+
+	// substituímos o valor transformado no documento
+	if helper.IsEmpty(t.path) {
+		document = transformed
+	} else {
+		var err error
+		document, err = sjson.SetRaw(document, t.path, transformed)
+		if helper.IsNotNil(err) {
+			logger.Warning("Error transform body with type", t.transformType, "path:", t.path, "err:", err)
+			return body
+		}
+	}
+
+	return &Body{
+		contentType: body.contentType,
+		value:       helper.SimpleConvertToBuffer(document),
+	}
+}
+
+// transform executes the step over the value, returning the raw JSON result, and false if the value is not
+// compatible with the step. The MAP and FLATTEN steps also accept a single object.
+func (t Transform) transform(value gjson.Result) (string, bool) {
+	if value.IsObject() {
+		switch t.transformType {
+		case enum.TransformTypeMap:
+			return t.mapElement(value), true
+		case enum.TransformTypeFlatten:
+			return flattenTransformObject(value, t.separator), true
+		default:
+			return "", false
+		}
+	} else if !value.IsArray() {
+		return "", false
+	}
+
+	elements := value.Array()
+	var result []string
+	switch t.transformType {
+	case enum.TransformTypeMap:
+		for _, element := range elements {
+			result = append(result, t.mapElement(element))
+		}
+	case enum.TransformTypeFilter:
+		for _, element := range elements {
+			if helper.IsNil(t.condition) || t.condition.Evaluate(transformEvalFunc(element)) {
+				result = append(result, element.Raw)
+			}
+		}
+	case enum.TransformTypeFlatten:
+		for _, element := range elements {
+			if element.IsArray() {
+				result = append(result, jsonArrayElements(element)...)
+			} else {
+				result = append(result, flattenTransformObject(element, t.separator))
+			}
+		}
+	case enum.TransformTypePluck:
+		for _, element := range elements {
+			if plucked := element.Get(t.key); plucked.Exists() {
+				result = append(result, plucked.Raw)
+			}
+		}
+	case enum.TransformTypeSort:
+		sort.SliceStable(elements, func(i, j int) bool {
+			if t.desc {
+				return t.sortValue(elements[j]).Less(t.sortValue(elements[i]), true)
+			}
+			return t.sortValue(elements[i]).Less(t.sortValue(elements[j]), true)
+		})
+		for _, element := range elements {
+			result = append(result, element.Raw)
+		}
+	case enum.TransformTypeLimit:
+		result = t.limitElements(jsonArrayElements(value))
+	}
+	return "[" + strings.Join(result, ",") + "]", true
+}
+
+// mapElement builds the new element of the MAP step, if the element is not an object it is returned unchanged.
+func (t Transform) mapElement(element gjson.Result) string {
+	if !element.IsObject() {
+		return element.Raw
+	}
+
+	// obtemos os valores mapeados antes de alterar o elemento
+	targets := make([]string, 0, len(t.fields))
+	values := map[string]gjson.Result{}
+	for target, source := range t.fields {
+		targets = append(targets, target)
+		values[target] = element.Get(source)
+	}
+	sort.Strings(targets)
+
+	// caso configurado mantemos os outros campos, removendo os campos de origem
+	mapped := "{}"
+	if t.keepOthers {
+		mapped = element.Raw
+		for _, source := range t.fields {
+			mapped, _ = sjson.Delete(mapped, source)
+		}
+	}
+
+	// preenchemos os novos campos com os valores obtidos
+	for _, target := range targets {
+		if value := values[target]; value.Exists() {
+			mapped, _ = sjson.SetRaw(mapped, target, value.Raw)
+		}
+	}
+	return mapped
+}
+
+// sortValue returns the value of the element used to sort it.
+func (t Transform) sortValue(element gjson.Result) gjson.Result {
+	if helper.IsEmpty(t.key) {
+		return element
+	}
+	return element.Get(t.key)
+}
+
+// limitElements returns the elements after the offset, up to the limit if it is greater than zero.
+func (t Transform) limitElements(elements []string) []string {
+	if helper.IsGreaterThan(t.offset, 0) {
+		if helper.IsGreaterThanOrEqual(t.offset, len(elements)) {
+			return nil
+		}
+		elements = elements[t.offset:]
+	}
+	if helper.IsGreaterThan(t.limit, 0) && helper.IsLessThan(t.limit, len(elements)) {
+		elements = elements[:t.limit]
+	}
+	return elements
+}
+
+// transformEvalFunc returns the expressionEvalFunc used by the FILTER step, where the eval words with the prefix
+// #item obtain the values of the current element, any other eval word results in nil.
+func transformEvalFunc(element gjson.Result) expressionEvalFunc {
+	return func(word string) any {
+		// limpamos a # e convertemos os índices no formato [0] para a sintaxe .0
+		eval := evalPath(word)
+		if helper.Equals(eval, "item") {
+			return element.Value()
+		} else if !strings.HasPrefix(eval, "item.") {
+			return nil
+		}
+		result := element.Get(strings.TrimPrefix(eval, "item."))
+		if !result.Exists() {
+			return nil
+		}
+		return result.Value()
+	}
+}
+
+// flattenTransformObject returns the object with the nested objects flattened, their keys are joined with the
+// separator. If the value is not an object, it is returned unchanged.
+func flattenTransformObject(value gjson.Result, separator string) string {
+	if !value.IsObject() {
+		return value.Raw
+	}
+	var fields []string
+	flattenTransformFields(value, "", separator, &fields)
+	return "{" + strings.Join(fields, ",") + "}"
+}
+
+// flattenTransformFields appends the fields of the object to the fields, walking recursively the nested objects
+// and prefixing their keys.
+func flattenTransformFields(value gjson.Result, prefix, separator string, fields *[]string) {
+	value.ForEach(func(key, child gjson.Result) bool {
+		name := prefix + key.String()
+		if child.IsObject() && helper.IsNotEmpty(child.Map()) {
+			flattenTransformFields(child, name+separator, separator, fields)
+		} else {
+			quotedName, _ := json.Marshal(name)
+			*fields = append(*fields, string(quotedName)+":"+child.Raw)
+		}
+		return true
+	})
+}
@@ -18,11 +18,16 @@ package service
 
 import (
 	"context"
+	"github.com/GabrielHCataldo/go-errors/errors"
 	"github.com/GabrielHCataldo/go-helper/helper"
 	"github.com/GabrielHCataldo/go-logger/logger"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/interfaces"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/mapper"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/consts"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/vo"
 	"net/http"
+	"net/url"
+	"time"
 )
 
 // backend represents a type that encapsulates the functionality for interacting with a backend service.
@@ -30,6 +35,7 @@ import (
 type backend struct {
 	modifierService Modifier
 	restTemplate    interfaces.RestTemplate
+	cacheStore      interfaces.CacheStore
 }
 
 // Backend represents a type that encapsulates the functionality for interacting with a backend service.
@@ -58,13 +64,17 @@ type Backend interface {
 // modifierService: Provides the service for modifying backend information. Must conform to the Modifier interface.
 // restTemplate: Provides the functionality for conducting RESTful operations. Must conform to the RestTemplate
 // interface from interfaces package.
+// cacheStore: Provides the store where the backend responses are cached. Must conform to the CacheStore interface
+// from interfaces package.
 //
 // Returns:
-// A Backend instance with modifierService and restTemplate composed in.
-func NewBackend(modifierService Modifier, restTemplate interfaces.RestTemplate) Backend {
+// A Backend instance with modifierService, restTemplate and cacheStore composed in.
+func NewBackend(modifierService Modifier, restTemplate interfaces.RestTemplate, cacheStore interfaces.CacheStore,
+) Backend {
 	return backend{
 		modifierService: modifierService,
 		restTemplate:    restTemplate,
+		cacheStore:      cacheStore,
 	}
 }
 
@@ -74,10 +84,13 @@ func NewBackend(modifierService Modifier, restTemplate interfaces.RestTemplate)
 //  1. The function constructs the backend request. This also includes a potential response modification.
 //  2. The backend request gets converted to an HTTP request. If this operation fails then the error will be returned in
 //     the response object.
-//  3. The function performs an HTTP request by calling a REST client. If this operation fails,
+//  3. If the backend has cache, the function looks up the cached backend response, which goes through the
+//     response modifiers like a response of the backend, see modifyBackendResponse. Any error reading the cache is
+//     handled as a cache miss.
+//  4. The function performs an HTTP request by calling a REST client. If this operation fails,
 //     then the error will be returned in the response object with its abort flag set to true.
-//  4. Finally, the function creates a backend response object from the returned HTTP response.
-//     This response is again able to include a request modification.
+//  5. Finally, the function creates a backend response object from the returned HTTP response, caching it if the
+//     backend has cache. This response is again able to include a request modification.
 //
 // The function returns the updated request and response value objects.
 // An already constructed response object is returned if any error occurs during the function execution.
@@ -95,6 +108,10 @@ func NewBackend(modifierService Modifier, restTemplate interfaces.RestTemplate)
 func (b backend) Execute(ctx context.Context, executeData *vo.ExecuteBackend) (*vo.Request, *vo.Response) {
 	// construímos o backend request, junto pode vir uma possível alteração no response pelo modifier
 	requestVO, responseVO := b.buildBackendRequest(executeData)
+	// caso algum modificador tenha falhado, retornamos a resposta de erro sem chamar o backend
+	if responseVO.Failed() {
+		return requestVO, responseVO
+	}
 
 	// locamos o objeto de valor
 	backendRequestVO := requestVO.CurrentBackendRequest()
@@ -106,6 +123,18 @@ func (b backend) Execute(ctx context.Context, executeData *vo.ExecuteBackend) (*
 		return requestVO, responseVO.Error(executeData.Endpoint().Path(), err)
 	}
 
+	// caso o backend tenha cache, consultamos a resposta do backend em cache antes de chamar o backend
+	backendVO := executeData.Backend()
+	var cacheKey string
+	if backendVO.HasCache() {
+		cacheKey = backendVO.Cache().StrategyKey(executeData.Endpoint(), requestVO)
+		var cacheResponse vo.CacheResponse
+		if err = b.cacheStore.Get(ctx, cacheKey, &cacheResponse); helper.IsNil(err) {
+			responseVO = responseVO.Append(vo.NewBackendResponseByCache(backendVO, &cacheResponse))
+			return b.modifyBackendResponse(backendVO, requestVO, responseVO)
+		}
+	}
+
 	// chamamos a interface de infra para chamar a conexão http e tratar a resposta
 	httpResponse, err := b.restTemplate.MakeRequest(httpRequest)
 	// caso ocorra um erro, retornamos o response como abort = true e a resposta formatada
@@ -116,7 +145,7 @@ func (b backend) Execute(ctx context.Context, executeData *vo.ExecuteBackend) (*
 	defer b.closeBodyResponse(httpResponse)
 
 	// construímos o objeto de valor de resposta do backend, junto pode vir uma possível alteração no request pelo modifier
-	return b.buildBackendResponse(executeData.Backend(), requestVO, responseVO, httpResponse)
+	return b.buildBackendResponse(ctx, backendVO, requestVO, responseVO, httpResponse, cacheKey)
 }
 
 // buildBackendRequest is a method in the backend framework that uses executeData of type vo.ExecuteBackend.
@@ -162,17 +191,18 @@ func (b backend) closeBodyResponse(response *http.Response) {
 //
 // Parameters:
 //
+//	ctx: the execution context, used to request the next pages.
 //	backendVO: the backend value object.
 //	requestVO: the request value object.
 //	responseVO: the response value object.
 //	httpResponse: the HTTP response object.
+//	cacheKey: the cache key of the backend response, empty if the backend has no cache.
 //
 // Steps:
-// 1. Constructs a new backend response value object using backendVO and httpResponse.
-// 2. Appends the new backend request to the response value object.
-// 3. Calls the modifierService's Execute method to modify the backend response.
-// 4. If the response indicates abort, returns the request and an abort response.
-// 5. If all steps are successful, returns the modified request and response.
+// 1. Constructs a new backend response value object using backendVO and httpResponse, following the next pages if
+// the backend is paginated.
+// 2. Caches the backend response if the backend has cache and its status code is allowed, see writeCache.
+// 3. Appends the new backend response to the response value object and modifies it, see modifyBackendResponse.
 //
 // Returns:
 //
@@ -180,19 +210,73 @@ func (b backend) closeBodyResponse(response *http.Response) {
 //	- requestVO: the potentially modified backend request.
 //	- responseVO: the backend response. If an error occurred, it contains the error information.
 func (b backend) buildBackendResponse(
+	ctx context.Context,
 	backendVO *vo.Backend,
 	requestVO *vo.Request,
 	responseVO *vo.Response,
 	httpResponse *http.Response,
+	cacheKey string,
 ) (*vo.Request, *vo.Response) {
 	// construímos o novo objeto de valor da resposta do backend
 	backendResponseVO := vo.NewBackendResponse(backendVO, httpResponse)
 
-	// adicionamos o novo backend request no objeto de valor de resposta
+	// caso o backend seja paginado, seguimos as próximas páginas concatenando os itens
+	if helper.IsNotNil(backendVO.Paginate()) && backendResponseVO.Ok() {
+		body, err := b.paginate(ctx, backendVO, requestVO, httpResponse.Request.URL, backendResponseVO.Header(),
+			backendResponseVO.Body())
+		if helper.IsNotNil(err) {
+			return requestVO, responseVO.Error(responseVO.Endpoint().Path(), err)
+		}
+		backendResponseVO = backendResponseVO.ModifyBody(body)
+	}
+
+	// caso o backend tenha cache e o status code seja permitido, gravamos a resposta do backend antes dos modificadores
+	if helper.IsNotEmpty(cacheKey) && backendVO.Cache().AllowStatusCode(backendResponseVO.StatusCode()) {
+		duration := backendVO.Cache().Duration()
+		b.writeCache(ctx, cacheKey, vo.NewCacheResponseByBackendResponse(backendResponseVO, duration), duration)
+	}
+
+	// adicionamos o novo backend request no objeto de valor de resposta e modificamos a resposta do backend
 	responseVO = responseVO.Append(backendResponseVO)
+	return b.modifyBackendResponse(backendVO, requestVO, responseVO)
+}
+
+// writeCache caches the backend response with the cache key and the duration of the backend cache.
+// If an error occurs, a warning message will be logged.
+func (b backend) writeCache(ctx context.Context, cacheKey string, cacheResponse *vo.CacheResponse,
+	duration time.Duration) {
+	err := b.cacheStore.Set(ctx, cacheKey, cacheResponse, duration)
+	if helper.IsNotNil(err) {
+		logger.Warning("Error write backend cache key:", cacheKey, "err:", err)
+	}
+}
+
+// modifyBackendResponse is a method in the backend framework that modifies the last backend response of the response
+// value object, obtained from the backend or from the cache:
+// 1. Validates the last backend response by the response schema.
+// 2. Calls the modifierService's Execute method to modify the backend response.
+// 3. If the response indicates abort, returns the request and an abort response.
+// 4. If all steps are successful, returns the modified request and response.
+func (b backend) modifyBackendResponse(
+	backendVO *vo.Backend,
+	requestVO *vo.Request,
+	responseVO *vo.Response,
+) (*vo.Request, *vo.Response) {
+	// validamos o contrato da resposta do backend antes dos modificadores
+	traceId := requestVO.Header().Get(consts.XTraceId)
+	responseVO = responseVO.ValidateLastBackendResponse(backendVO.ResponseSchema(), traceId)
 
 	// chamamos o sub-dominio para modificar a resposta do backend
 	requestVO, responseVO = b.modifierService.Execute(vo.NewExecuteResponseModifier(backendVO, requestVO, responseVO))
+	// caso algum modificador tenha falhado, retornamos a resposta de erro
+	if responseVO.Failed() {
+		return requestVO, responseVO
+	}
+
+	// aplicamos os passos de transformação do backend e depois a projeção de campos do backend e do endpoint antes da
+	// agregação
+	responseVO = responseVO.TransformLastBackendResponse(backendVO.Transforms())
+	responseVO = responseVO.ProjectLastBackendResponse(backendVO.Projection(), responseVO.Endpoint().Projection())
 
 	// se resposta é para abortar retornamos
 	if responseVO.Abort() {
@@ -202,3 +286,58 @@ func (b backend) buildBackendResponse(
 	// se tudo ocorrer bem retornamos a requisição e o response resultante
 	return requestVO, responseVO
 }
+
+// paginate requests the next pages of the backend, using the same backend request with the URL of the next page,
+// until there is no next page, or the maximum number of pages or items is reached. The context of the endpoint is
+// respected, so the pagination stops with a gateway timeout error when it expires. If a page doesn't respond with a
+// success status code, a bad gateway error is returned. Returns the body of the first page with the items of all
+// pages concatenated.
+func (b backend) paginate(
+	ctx context.Context,
+	backendVO *vo.Backend,
+	requestVO *vo.Request,
+	currentUrl *url.URL,
+	header vo.Header,
+	body *vo.Body,
+) (*vo.Body, error) {
+	paginateVO := backendVO.Paginate()
+
+	pages := []*vo.Body{body}
+	countItems := paginateVO.CountItems(body)
+	for len(pages) < paginateVO.MaxPages() && !paginateVO.ReachedMaxItems(countItems) {
+		// obtemos a url da próxima página, caso não tenha finalizamos
+		nextUrl := paginateVO.NextUrl(currentUrl, header, body)
+		if helper.IsNil(nextUrl) {
+			break
+		} else if helper.IsNotNil(ctx.Err()) {
+			return nil, mapper.NewErrGatewayTimeoutByErr(ctx.Err())
+		}
+
+		// montamos o http request da próxima página a partir do backend request atual
+		httpRequest, err := requestVO.CurrentBackendRequest().Http(ctx)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		httpRequest.URL = nextUrl
+
+		// chamamos a próxima página
+		httpResponse, err := b.restTemplate.MakeRequest(httpRequest)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		backendResponseVO := vo.NewBackendResponse(backendVO, httpResponse)
+		b.closeBodyResponse(httpResponse)
+		if !backendResponseVO.Ok() {
+			return nil, mapper.NewErrBadGateway(errors.New("Paginate page", len(pages)+1, "responded with status code",
+				backendResponseVO.StatusCode()))
+		}
+
+		// adicionamos a página
+		currentUrl, header, body = nextUrl, backendResponseVO.Header(), backendResponseVO.Body()
+		pages = append(pages, body)
+		countItems += paginateVO.CountItems(body)
+	}
+
+	// concatenamos os itens das páginas
+	return paginateVO.Concat(pages), nil
+}
@@ -18,8 +18,13 @@ package service
 
 import (
 	"context"
+	"github.com/GabrielHCataldo/go-errors/errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
 	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/mapper"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/vo"
+	"github.com/tidwall/gjson"
+	"sync"
 )
 
 // endpoint is a struct type that represents an `endpoint` service domain in the Gopen server.
@@ -27,7 +32,8 @@ import (
 // It contains a backendService field of type Backend, which encapsulates the functionality
 // for interacting with a backend service.
 type endpoint struct {
-	backendService Backend
+	modifierService Modifier
+	backendService  Backend
 }
 
 // Endpoint represents an interface for executing a specific endpoint in the Gopen server.
@@ -39,10 +45,11 @@ type Endpoint interface {
 	Execute(ctx context.Context, executeData *vo.ExecuteEndpoint) *vo.Response
 }
 
-// NewEndpoint returns a new instance of the endpoint struct with the provided backendService.
-func NewEndpoint(backendService Backend) Endpoint {
+// NewEndpoint returns a new instance of the endpoint struct with the provided modifierService and backendService.
+func NewEndpoint(modifierService Modifier, backendService Backend) Endpoint {
 	return endpoint{
-		backendService: backendService,
+		modifierService: modifierService,
+		backendService:  backendService,
 	}
 }
 
@@ -63,6 +70,13 @@ func NewEndpoint(backendService Backend) Endpoint {
 // After processing the backends, the method processes the middlewares configured in the
 // afterware field, updating the request and response value objects accordingly.
 //
+// Before anything, the client request is validated by the request schema of the endpoint, if it doesn't match, a
+// response with the violations is returned and no backend is called.
+//
+// The endpoint modifiers with REQUEST context are executed before the first beforeware, and the endpoint
+// modifiers with RESPONSE context are executed on the final aggregated response, including aborted responses.
+// The redaction rules of the endpoint are applied last, masking the sensitive data of the final response.
+//
 // Finally, the method returns the final response value object.
 //
 // Parameters:
@@ -73,15 +87,53 @@ func NewEndpoint(backendService Backend) Endpoint {
 // Returns:
 // The vo.Response object representing the response of the executed endpoint.
 func (e endpoint) Execute(ctx context.Context, executeData *vo.ExecuteEndpoint) *vo.Response {
-	// instanciamos o objeto gopenVO
-	gopenVO := executeData.Gopen()
 	// instanciamos o objeto de valor do endpoint
 	endpointVO := executeData.Endpoint()
-	// instanciamos o objeto de valor da requisição
-	requestVO := executeData.Request()
 	// inicializamos o objeto de valor de resposta do serviço
 	responseVO := vo.NewResponse(endpointVO)
 
+	// instanciamos o objeto de valor da requisição
+	requestVO := executeData.Request()
+
+	// validamos a requisição do cliente pelo schema do endpoint, caso inválida nenhum backend é chamado
+	requestSchemaVO := endpointVO.RequestSchema()
+	if violations := requestSchemaVO.Violations(requestVO); helper.IsNotEmpty(violations) {
+		err := errors.New("Request does not match the endpoint request-schema!")
+		responseVO = responseVO.SchemaError(requestSchemaVO.StatusCode(), violations, err)
+	} else {
+		// executamos os modificadores do endpoint no contexto de requisição, antes do primeiro beforeware
+		requestVO, responseVO = e.modifierService.ExecuteEndpoint(
+			vo.NewExecuteEndpointRequestModifier(endpointVO, requestVO, responseVO),
+		)
+	}
+
+	// processamos os middlewares e os backends do endpoint, caso a validação e os modificadores não tenham falhado
+	if !responseVO.Failed() {
+		requestVO, responseVO = e.process(ctx, executeData.Gopen(), endpointVO, requestVO, responseVO)
+	}
+
+	// executamos os modificadores do endpoint no contexto de resposta, na resposta final agregada
+	_, responseVO = e.modifierService.ExecuteEndpoint(
+		vo.NewExecuteEndpointResponseModifier(endpointVO, requestVO, responseVO),
+	)
+
+	// aplicamos as regras de redação configuradas no endpoint na resposta final
+	responseVO = responseVO.Redact(endpointVO.RedactionRules())
+
+	// retornamos o objeto de valor de resposta final
+	return responseVO
+}
+
+// process executes the beforeware, the main backends and the afterware of the endpoint, in this order.
+// If the response object indicates that the response needs to be aborted after any step, the method returns the
+// current request and response value objects without further processing.
+func (e endpoint) process(
+	ctx context.Context,
+	gopenVO *vo.Gopen,
+	endpointVO *vo.Endpoint,
+	requestVO *vo.Request,
+	responseVO *vo.Response,
+) (*vo.Request, *vo.Response) {
 	// TODO: pensarmos em futuramente ter backends para chamadas paralelas
 
 	// iteramos o beforeware, chaves configuradas para middlewares antes das requisições principais
@@ -96,18 +148,18 @@ func (e endpoint) Execute(ctx context.Context, executeData *vo.ExecuteEndpoint)
 	)
 	// verificamos a resposta precisa ser abortada
 	if responseVO.Abort() {
-		return responseVO
+		return requestVO, responseVO
 	}
 
 	// iteramos os backends principais para executa-las
 	requestVO, responseVO = e.processBackends(ctx, endpointVO, requestVO, responseVO)
 	// verificamos a resposta precisa ser abortada
 	if responseVO.Abort() {
-		return responseVO
+		return requestVO, responseVO
 	}
 
 	// iteramos o afterware, chaves configuradas para middlewares depois das requisições principais
-	requestVO, responseVO = e.processMiddlewares(
+	return e.processMiddlewares(
 		ctx,
 		"afterware",
 		endpointVO.Afterware(),
@@ -116,13 +168,6 @@ func (e endpoint) Execute(ctx context.Context, executeData *vo.ExecuteEndpoint)
 		requestVO,
 		responseVO,
 	)
-	// verificamos a resposta precisa ser abortada
-	if responseVO.Abort() {
-		return responseVO
-	}
-
-	// retornamos o objeto de valor de resposta final
-	return responseVO
 }
 
 // processMiddlewares processes the middleware backends for the given middleware keys.
@@ -175,6 +220,7 @@ func (e endpoint) processMiddlewares(
 }
 
 // processBackends iterates through the provided backends and executes each backend.
+// The backends with a fan-out configured are executed once per element by processForEach.
 // It updates the request and response value objects accordingly. If the response object
 // indicates that the response needs to be aborted, the iteration stops and the current
 // request and response value objects are returned.
@@ -195,10 +241,15 @@ func (e endpoint) processBackends(
 ) (*vo.Request, *vo.Response) {
 	// iteramos os backends fornecidos
 	for _, backendVO := range endpointVO.Backends() {
-		// instanciamos
-		executeBackendVO := vo.NewExecuteBackend(endpointVO, &backendVO, requestVO, responseVO)
-		// processamos o backend principal iterado
-		requestVO, responseVO = e.backendService.Execute(ctx, executeBackendVO)
+		// caso o backend tenha for-each, executamos uma chamada por elemento
+		if helper.IsNotNil(backendVO.ForEach()) {
+			responseVO = e.processForEach(ctx, endpointVO, &backendVO, requestVO, responseVO)
+		} else {
+			// instanciamos
+			executeBackendVO := vo.NewExecuteBackend(endpointVO, &backendVO, requestVO, responseVO)
+			// processamos o backend principal iterado
+			requestVO, responseVO = e.backendService.Execute(ctx, executeBackendVO)
+		}
 		// verificamos a resposta precisa ser abortada
 		if responseVO.Abort() {
 			break
@@ -206,3 +257,52 @@ func (e endpoint) processBackends(
 	}
 	return requestVO, responseVO
 }
+
+// processForEach executes the backend once per element of the array iterated by its fan-out, with the path params and
+// query values filled from the element, running at most the configured concurrency of calls at the same time. The
+// calls don't change the request value object, and the responses are merged back into the elements in the order of
+// the elements, regardless of the order in which the calls finished. If the context is done while the calls wait for
+// a slot, the remaining elements are not called and respond with the gateway timeout error.
+func (e endpoint) processForEach(
+	ctx context.Context,
+	endpointVO *vo.Endpoint,
+	backendVO *vo.Backend,
+	requestVO *vo.Request,
+	responseVO *vo.Response,
+) *vo.Response {
+	forEachVO := backendVO.ForEach()
+	// obtemos os elementos iterados da resposta anterior
+	elements := responseVO.ForEachElements(forEachVO)
+
+	// executamos as chamadas limitando a concorrência configurada
+	responses := make([]*vo.Response, len(elements))
+	semaphore := make(chan struct{}, forEachVO.Concurrency())
+	var wg sync.WaitGroup
+calls:
+	for index, element := range elements {
+		// aguardamos uma vaga, caso o contexto finalize não chamamos os elementos restantes
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			err := mapper.NewErrGatewayTimeoutByErr(ctx.Err())
+			for i := index; i < len(elements); i++ {
+				responses[i] = vo.NewResponseByErr(endpointVO, mapper.StatusCodeByErr(err), err)
+			}
+			break calls
+		}
+		wg.Add(1)
+		go func(index int, element gjson.Result) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			executeBackendVO := vo.NewExecuteBackend(endpointVO, backendVO, forEachVO.Request(requestVO, element),
+				responseVO)
+			_, responses[index] = e.backendService.Execute(ctx, executeBackendVO)
+		}(index, element)
+	}
+	wg.Wait()
+
+	// mesclamos as respostas nos elementos
+	return responseVO.ForEach(forEachVO, backendVO, responses)
+}
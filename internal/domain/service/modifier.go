@@ -32,6 +32,11 @@ type Modifier interface {
 	// backend modifiers, request, and response. It returns a vo.Request object and a vo.Response object.
 	// This method allows modification of the request and response based on the provided modifier.
 	Execute(executeData *vo.ExecuteModifier) (*vo.Request, *vo.Response)
+	// ExecuteEndpoint is a method that executes the endpoint modifiers on the request, before the first beforeware,
+	// or on the final aggregated response.
+	// It takes a vo.ExecuteModifier as a parameter, which contains the modifier context,
+	// endpoint modifiers, request, and response. It returns a vo.Request object and a vo.Response object.
+	ExecuteEndpoint(executeData *vo.ExecuteModifier) (*vo.Request, *vo.Response)
 }
 
 // NewModifier creates and returns a new Modifier instance.
@@ -93,10 +98,66 @@ func (m modifier) Execute(executeData *vo.ExecuteModifier) (*vo.Request, *vo.Res
 	return requestVO, responseVO
 }
 
+// ExecuteEndpoint executes the given endpoint modifiers on the request or on the final aggregated response.
+// Unlike Execute, the modifications are applied directly to the request and response objects, and not to the
+// current backend request or last backend response.
+//
+// Parameters:
+//   - executeData: An ExecuteModifier object containing the modifier context, endpoint modifiers,
+//     request, and response to be modified.
+//
+// Returns:
+// - *vo.Request: The potentially altered request object.
+// - *vo.Response: The potentially altered response object.
+func (m modifier) ExecuteEndpoint(executeData *vo.ExecuteModifier) (*vo.Request, *vo.Response) {
+	// checamos se o endpointModifier veio nil e ja retornamos
+	if helper.IsNil(executeData.BackendModifiers()) {
+		return executeData.Request(), executeData.Response()
+	}
+
+	// instanciamos o requestVO para ser modificado ou não
+	requestVO := executeData.Request()
+	// instanciamos o responseVO para ser modificado ou não
+	responseVO := executeData.Response()
+
+	// executamos o modificador de código de status
+	if helper.IsNotEmpty(executeData.ModifierStatusCode()) &&
+		helper.Equals(enum.ModifierContextResponse, executeData.Context()) {
+		modifyVO := vo.NewModifyEndpointStatusCodes(executeData.ModifierStatusCode(), requestVO, responseVO)
+		requestVO, responseVO = modifyVO.Execute()
+	}
+
+	// executamos os modificadores de cabeçalho
+	modifierHeader := executeData.ModifierHeader()
+	requestVO, responseVO = m.modify(modifierHeader, executeData.Context(), requestVO, responseVO,
+		vo.NewModifyEndpointHeaders)
+
+	// executamos os modificadores de parâmetros, apenas no contexto de requisição
+	if helper.Equals(enum.ModifierContextRequest, executeData.Context()) {
+		modifierParam := executeData.ModifierParam()
+		requestVO, responseVO = m.modify(modifierParam, executeData.Context(), requestVO, responseVO,
+			vo.NewModifyEndpointParams)
+
+		modifierQuery := executeData.ModifierQuery()
+		requestVO, responseVO = m.modify(modifierQuery, executeData.Context(), requestVO, responseVO,
+			vo.NewModifyEndpointQueries)
+	}
+
+	// executamos os modificadores de body
+	modifierBody := executeData.ModifierBody()
+	requestVO, responseVO = m.modify(modifierBody, executeData.Context(), requestVO, responseVO,
+		vo.NewModifyEndpointBodies)
+
+	// retornamos os objetos de valore
+	return requestVO, responseVO
+}
+
 // The method modify iterates over a list of provided modifiers and applies them to the request
-// and response value objects if the modifier is valid, and it matches the given context.
+// and response value objects if the modifier is valid, it matches the given context and its `if`
+// expression, when configured, is satisfied.
 // A Modification strategy is created for each individual valid and matching modifier
 // Then, this strategy is executed, potentially altering the provided request and response value objects.
+// If a modifier fails with the on-error policy FAIL, the remaining modifiers are not executed.
 //
 // Parameters:
 // - modifiers: A slice of Modifier value objects to iterate over and potentially apply
@@ -110,16 +171,23 @@ func (m modifier) Execute(executeData *vo.ExecuteModifier) (*vo.Request, *vo.Res
 // - vo.Response: The potentially altered response value object
 func (m modifier) modify(modifiers []vo.Modifier, context enum.ModifierContext, requestVO *vo.Request,
 	responseVO *vo.Response, newModifyVO vo.NewModifyVOFunc) (*vo.Request, *vo.Response) {
+	// verificamos se a resposta já chegou como falha, para interromper apenas nas falhas dos modificadores
+	failed := responseVO.Failed()
 	// iteramos os modificadores
 	for _, modifierVO := range modifiers {
-		// caso ele seja invalido ou não tiver no context vamos para o próximo
-		if modifierVO.Invalid() || modifierVO.NotEqualsContext(context) {
+		// caso ele seja invalido, não tiver no context ou a condição não for satisfeita vamos para o próximo
+		if modifierVO.Invalid() || modifierVO.NotEqualsContext(context) ||
+			modifierVO.NotSatisfied(requestVO, responseVO) {
 			continue
 		}
 		// damos o new modify vo para instanciar a estratégia
 		strategy := newModifyVO(&modifierVO, requestVO, responseVO)
 		// executamos a estrátegia, substituímos os objetos de valor modificados, ou não
 		requestVO, responseVO = strategy.Execute()
+		// caso o modificador tenha falhado com a política de falha, interrompemos as próximas modificações
+		if !failed && responseVO.Failed() {
+			break
+		}
 	}
 	// retornamos os objetos de valor modificados ou não
 	return requestVO, responseVO
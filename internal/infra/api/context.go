@@ -17,12 +17,15 @@
 package api
 
 import (
+	"context"
 	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/consts"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/vo"
 	"github.com/gin-gonic/gin"
-	"golang.org/x/net/context"
 	"net/http"
 	"sync"
+	"time"
 )
 
 // Context is a struct that represents the context of the current request.
@@ -45,6 +48,10 @@ type Context struct {
 	request *vo.Request
 	// response is a structure that represents the HTTP response, written by the context.
 	response *vo.Response
+	// staleIfError represents the stale cache response written in place of the failed responses, see SetStaleIfError.
+	staleIfError *vo.CacheResponse
+	// detached indicates that the context is detached from the client, so the responses are only kept, see Detach.
+	detached bool
 }
 
 // Context returns the context of the Context. It delegates the call to the underlying framework's Context.Context() method.
@@ -81,6 +88,30 @@ func (c *Context) Http() *http.Request {
 	return c.framework.Request
 }
 
+// Detach returns a copy of the Context detached from the client, to execute the handlers outside the request, like the
+// revalidation of the stale cache responses. The context of the copy is not canceled with the request, expiring after
+// the timeout instead, and the responses written to the copy are only kept, see Write. The returned cancel function
+// releases the resources of the context, and the copy must be created before the request returns.
+func (c *Context) Detach(timeout time.Duration) (*Context, context.CancelFunc) {
+	detachedContext, cancel := context.WithTimeout(context.WithoutCancel(c.Context()), timeout)
+
+	// copiamos o contexto do gin, que pode ser utilizado fora da requisição
+	framework := c.framework.Copy()
+	framework.Request = framework.Request.WithContext(detachedContext)
+
+	detached := &Context{
+		mutex:     &sync.RWMutex{},
+		framework: framework,
+		gopen:     c.gopen,
+		endpoint:  c.endpoint,
+		request:   c.Request(),
+		response:  vo.NewResponse(c.endpoint),
+		detached:  true,
+	}
+	framework.Set("context", detached)
+	return detached, cancel
+}
+
 // SetRequestContext sets the context of the Context to the provided context.
 // It updates the underlying framework's Context.Context() method to use the new context.
 func (c *Context) SetRequestContext(ctx context.Context) {
@@ -191,7 +222,11 @@ func (c *Context) Next() {
 
 // Write writes the response to the client.
 // It first checks if the request has already been aborted, in which case it does nothing.
-// Then, it writes the response headers.
+// If the response failed and a stale cache response is set, see SetStaleIfError, it writes the stale response instead.
+// Then, it formats the gateway error responses and, if the endpoint has cache, sets the ETag and Last-Modified
+// validators. If the context is detached from the client, see Detach, the response is only kept. Otherwise, it writes
+// only the headers with http.StatusNotModified if the conditional request matches the validators, see
+// vo.Response.NotModified, or it writes the response headers.
 // It retrieves the status code and body from the responseVO.
 // If the body is not empty, it writes the body along with the status code.
 // Otherwise, it only writes the status code.
@@ -199,14 +234,44 @@ func (c *Context) Write(responseVO *vo.Response) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	// se ja tiver abortado não fazemos nada
-	if c.framework.IsAborted() {
+	// se ja tiver abortado não fazemos nada, o contexto destacado é sempre abortado
+	if c.framework.IsAborted() && !c.detached {
+		return
+	}
+
+	// caso tenha uma resposta stale, respondemos ela no lugar das respostas de falha
+	if helper.IsNotNil(c.staleIfError) {
+		if responseVO.Failed() || helper.IsGreaterThanOrEqual(responseVO.StatusCode(), http.StatusInternalServerError) {
+			responseVO = vo.NewResponseByCache(c.endpoint, c.staleIfError, enum.CacheStatusStale)
+		} else if helper.Equals(responseVO.Header().Get(consts.XGopenCache), string(enum.CacheStatusMiss)) {
+			responseVO = responseVO.SetHeader(responseVO.Header().Set(consts.XGopenCache,
+				string(enum.CacheStatusRevalidated)))
+		}
+	}
+
+	// formatamos as respostas de erro do gateway com o formato de erro do endpoint
+	responseVO = responseVO.FormatError(c.Request())
+
+	// caso o endpoint tenha cache, adicionamos os validadores da resposta
+	if c.endpoint.HasCache() && !responseVO.Failed() {
+		responseVO = responseVO.SetValidators()
+	}
+
+	// caso o contexto esteja destacado do cliente, apenas guardamos a resposta
+	if c.detached {
+		c.response = responseVO
 		return
 	}
 
 	// escrevemos os headers de resposta
 	c.writeHeader(responseVO.Header())
 
+	// caso a requisição condicional corresponda aos validadores, respondemos sem o body
+	if c.endpoint.HasCache() && responseVO.NotModified(c.Request()) {
+		c.writeNotModified(responseVO)
+		return
+	}
+
 	// instanciamos os valores a serem utilizados
 	statusCode := responseVO.StatusCode()
 	contentType := responseVO.ContentType()
@@ -227,14 +292,23 @@ func (c *Context) Write(responseVO *vo.Response) {
 }
 
 // WriteCacheResponse writes the cache response to the client's response.
-// It creates a new response using the cache response and writes it.
-func (c *Context) WriteCacheResponse(cacheResponse *vo.CacheResponse) {
+// It creates a new response using the cache response, with the cache status on the X-Gopen-Cache header, and
+// writes it.
+func (c *Context) WriteCacheResponse(cacheResponse *vo.CacheResponse, cacheStatus enum.CacheStatus) {
 	// preparamos a resposta
-	responseVO := vo.NewResponseByCache(c.endpoint, cacheResponse)
+	responseVO := vo.NewResponseByCache(c.endpoint, cacheResponse, cacheStatus)
 	// escrevemos a resposta
 	c.Write(responseVO)
 }
 
+// SetStaleIfError sets the stale cache response written in place of the response if it fails, see Write.
+func (c *Context) SetStaleIfError(cacheResponse *vo.CacheResponse) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.staleIfError = cacheResponse
+}
+
 // WriteError writes an error response to the client.
 // It creates a new Response object with the provided code and error, and delegates the writing of the response to the
 // Write method.
@@ -259,6 +333,15 @@ func (c *Context) writeHeader(header vo.Header) {
 	}
 }
 
+// writeNotModified writes the http.StatusNotModified status code, without the body, and aborts the request. The
+// written response is kept in the context with its body, so it can still be cached.
+func (c *Context) writeNotModified(responseVO *vo.Response) {
+	c.framework.Status(http.StatusNotModified)
+	c.framework.Writer.WriteHeaderNow()
+	c.framework.Abort()
+	c.response = responseVO
+}
+
 // writeBody writes the response body based on the configured response encoding of the endpoint.
 // If the framework is aborted, the method returns early without writing the body.
 // If the response encoding is set to ResponseEncodeText, the body is written as a string using the given code.
@@ -18,9 +18,28 @@ package infra
 
 import (
 	"context"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/vo"
 	"time"
 )
 
+// CacheStats represents the counters of a cache store, exposed by the cache admin routes.
+type CacheStats struct {
+	// Entries represents the number of entries on the cache store.
+	Entries int
+	// Memory represents the size of the serialized entries on the cache store.
+	Memory vo.Bytes
+	// MaxEntries represents the maximum number of entries on the cache store, 0 if unlimited.
+	MaxEntries int
+	// MaxMemory represents the maximum size of the serialized entries on the cache store, 0 if unlimited.
+	MaxMemory vo.Bytes
+	// Hits represents the number of reads that found the key.
+	Hits uint64
+	// Misses represents the number of reads that did not find the key, or found it expired.
+	Misses uint64
+	// Evictions represents the number of entries evicted to respect the limits of the cache store.
+	Evictions uint64
+}
+
 // CacheStore is an interface that defines methods for interacting with a cache store.
 type CacheStore interface {
 	// Set stores the given value with the provided key in the cache store. The value will expire after the specified duration.
@@ -37,6 +56,28 @@ type CacheStore interface {
 	// If the cache entry is not found, or an error occurs while retrieving the value, an error is returned.
 	// The method takes in a context.Context object to support cancellation and timeouts.
 	Get(ctx context.Context, key string, dest any) error
+	// Peek retrieves the value like Get, but the read is not counted on the hits and misses, see Stats. It is used by
+	// the internal reads, like the polling of the coalesced requests.
+	Peek(ctx context.Context, key string, dest any) error
+	// Scan returns the keys of the cache store matching the glob pattern, where `*` matches any sequence of characters,
+	// `?` matches any single character and `[...]` matches any character of the set.
+	// If an error occurs while scanning the keys, it is returned.
+	Scan(ctx context.Context, pattern string) ([]string, error)
+	// DelByPattern deletes the cache entries with the keys matching the glob pattern, see Scan, returning the number of
+	// deleted entries.
+	// If an error occurs while scanning or deleting the keys, it is returned with the number of entries deleted so far.
+	DelByPattern(ctx context.Context, pattern string) (int, error)
+	// Lock sets the lock key on the cache store with the token only if it does not exist, expiring after the specified
+	// duration, returning true if the lock was acquired. The lock is released with the same token, see Unlock.
+	// If an error occurs while setting the lock key, it is returned.
+	Lock(ctx context.Context, key, token string, expire time.Duration) (bool, error)
+	// Unlock deletes the lock key from the cache store only if it still holds the token, so a lock expired and
+	// acquired by another gateway instance is not released.
+	// If an error occurs while deleting the lock key, it is returned.
+	Unlock(ctx context.Context, key, token string) error
+	// Stats returns the counters of the cache store, see CacheStats.
+	// If an error occurs while obtaining the counters, it is returned.
+	Stats(ctx context.Context) (CacheStats, error)
 	// Close is a method defined in the CacheStore interface. It is used to close the cache store and release any resources
 	// associated with it. The method returns an error if there was a problem closing the store.
 	Close() error
@@ -31,6 +31,8 @@ import (
 )
 
 type logProvider struct {
+	// redactionRules represents the redaction rules applied to the logged request and response bodies.
+	redactionRules vo.RedactionRules
 }
 
 // LogProvider is an interface that defines methods for logging in a software application.
@@ -48,9 +50,12 @@ type LogProvider interface {
 	BuildFinishRequestMessage(responseVO *vo.Response, startTime time.Time) string
 }
 
-// NewLogProvider creates and returns a new instance of LogProvider.
-func NewLogProvider() LogProvider {
-	return logProvider{}
+// NewLogProvider creates and returns a new instance of LogProvider, the redactionRules are applied to the logged
+// request and response bodies.
+func NewLogProvider(redactionRules vo.RedactionRules) LogProvider {
+	return logProvider{
+		redactionRules: redactionRules,
+	}
 }
 
 // InitializeLoggerOptions initializes the logger options for the current request.
@@ -85,8 +90,8 @@ func (l logProvider) BuildInitialRequestMessage(ctx *api.Context) string {
 	if helper.ContainsIgnoreCase(bodyType, "application/json") ||
 		helper.ContainsIgnoreCase(bodyType, "application/xml") ||
 		helper.ContainsIgnoreCase(bodyType, "plain/text") {
-		// convertemos esses bytes para o any inicializado
-		bodyInfo = ctx.BodyString()
+		// convertemos esses bytes para o any inicializado, aplicando as regras de redação
+		bodyInfo = l.redactionRules.Text(ctx.BodyString())
 	} else if helper.IsNotEmpty(bodyType, bodySize) {
 		var msg string
 		if helper.IsNotEmpty(bodyType) {
@@ -129,7 +134,7 @@ func (l logProvider) BuildFinishRequestMessage(responseVO *vo.Response, startTim
 	text.WriteString(" ")
 	text.WriteString(logger.StyleReset)
 	if helper.IsNotEmpty(bodyBytes) {
-		s := string(bodyBytes)
+		s := l.redactionRules.Text(string(bodyBytes))
 		if helper.IsNotEqualTo(responseVO.ContentType(), enum.ContentTypeText) {
 			s = l.replaceAllBreakLineText(s)
 		}
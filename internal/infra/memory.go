@@ -17,65 +17,369 @@
 package infra
 
 import (
+	"container/list"
 	"context"
+	"encoding/json"
+	"fmt"
 	"github.com/GabrielHCataldo/go-errors/errors"
 	"github.com/GabrielHCataldo/go-helper/helper"
 	appmapper "github.com/GabrielHCataldo/gopen-gateway/internal/app/mapper"
-	"github.com/jellydator/ttlcache/v2"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/vo"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 )
 
+// memoryStoreCleanupInterval represents the interval in which the expired entries are removed from the memory cache.
+const memoryStoreCleanupInterval = time.Minute
+
 // memoryStore represents an in-memory cache store that implements the CacheStore interface.
+// The entries are kept in a least recently used (LRU) list, with the most recently used at the front, and when the
+// maxEntries or maxMemory limits are exceeded, the entries at the back of the list are evicted. The lock keys are kept
+// apart from the entries, so they are never evicted nor counted on the stats.
 type memoryStore struct {
-	ttlCache *ttlcache.Cache
+	// mutex synchronizes the access to the entries, the list, the locks and the counters.
+	mutex *sync.Mutex
+	// entries represents the elements of the lru list by key.
+	entries map[string]*list.Element
+	// locks represents the lock keys held, see Lock.
+	locks map[string]memoryLock
+	// lru represents the list of memoryEntry, ordered from the most to the least recently used.
+	lru *list.List
+	// maxEntries represents the maximum number of entries, unlimited if 0.
+	maxEntries int
+	// maxMemory represents the maximum size of the entries, unlimited if 0.
+	maxMemory vo.Bytes
+	// memory represents the size of the entries, see memoryEntry.size.
+	memory vo.Bytes
+	// hits represents the number of reads that found the key.
+	hits uint64
+	// misses represents the number of reads that did not find the key, or found it expired.
+	misses uint64
+	// evictions represents the number of entries evicted by the limits.
+	evictions uint64
+	// cancel stops the cleanup of the expired entries, see Close.
+	cancel context.CancelFunc
+}
+
+// memoryEntry represents an entry of the memory cache, with the value serialized in JSON.
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// memoryLock represents a lock key of the memory cache, held by the token until it expires.
+type memoryLock struct {
+	token     string
+	expiresAt time.Time
 }
 
 // NewMemoryStore returns a new instance of the MemoryStore structure that implements the CacheStore interface.
-// This implementation uses an in-memory cache with a time-to-live (TTL)
-func NewMemoryStore() CacheStore {
-	ttlCache := ttlcache.NewCache()
-	ttlCache.SkipTTLExtensionOnHit(true)
-	return &memoryStore{
-		ttlCache: ttlCache,
+// This implementation uses an in-memory cache with a time-to-live (TTL) per entry, limited by the maxEntries and the
+// maxMemory, unlimited if 0, evicting the least recently used entries when they are exceeded. The expired entries are
+// removed every memoryStoreCleanupInterval until the store is closed.
+func NewMemoryStore(maxEntries int, maxMemory vo.Bytes) CacheStore {
+	ctx, cancel := context.WithCancel(context.Background())
+	store := &memoryStore{
+		mutex:      &sync.Mutex{},
+		entries:    map[string]*list.Element{},
+		locks:      map[string]memoryLock{},
+		lru:        list.New(),
+		maxEntries: max(maxEntries, 0),
+		maxMemory:  max(maxMemory, 0),
+		cancel:     cancel,
 	}
+	go store.cleanup(ctx)
+	return store
 }
 
-// Set sets a key-value pair in the memory cache with the specified expiration duration.
-// The key is a string that serves as the identifier, the value is any data that can be stored in the cache,
-// and the expiry duration determines how long the key-value pair will remain in the cache.
-// The error returned indicates any issues encountered while setting the key-value pair.
-// Implementing the CacheStore interface, this method uses the underlying ttlCache to store the data.
-// The ttlCache.SetWithTTL function is used to set the key-value pair with the specified expiration.
-func (r memoryStore) Set(_ context.Context, key string, value any, expire time.Duration) error {
-	return r.ttlCache.SetWithTTL(key, value, expire)
+// Set sets a key-value pair in the memory cache with the specified expiration duration, never expiring if it is 0.
+// The value is serialized in JSON, and its size with the key is accounted in the memory of the store. If the entry is
+// larger than the maxMemory, an error is returned. After it is set as the most recently used entry, the least
+// recently used entries are evicted while the limits are exceeded.
+func (m *memoryStore) Set(_ context.Context, key string, value any, expire time.Duration) error {
+	bytes, err := json.Marshal(value)
+	if helper.IsNotNil(err) {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.set(key, bytes, expire)
+}
+
+// set sets the serialized value of the key as the most recently used entry, see Set. It must be called with the mutex
+// locked.
+func (m *memoryStore) set(key string, value []byte, expire time.Duration) error {
+	entry := &memoryEntry{key: key, value: value}
+	if helper.IsGreaterThan(expire, 0) {
+		entry.expiresAt = time.Now().Add(expire)
+	}
+	if helper.IsGreaterThan(m.maxMemory, 0) && helper.IsGreaterThan(entry.size(), m.maxMemory) {
+		return errors.New(fmt.Sprintf("cache key %s size %v exceeds the max-memory %v", key, entry.size(),
+			m.maxMemory))
+	}
+
+	// substituímos a entrada existente ou adicionamos como a mais recente
+	m.remove(key)
+	m.entries[key] = m.lru.PushFront(entry)
+	m.memory += entry.size()
+
+	// removemos as entradas menos usadas enquanto os limites forem excedidos
+	for m.exceeded() {
+		m.remove(m.lru.Back().Value.(*memoryEntry).key)
+		m.evictions++
+	}
+	return nil
 }
 
 // Del removes a key-value pair from the memory cache with the specified key.
 // The key is a string that serves as the identifier for the key-value pair to be removed.
-// The error returned indicates any issues encountered while removing the key-value pair.
-// Implementing the CacheStore interface, this method uses the underlying ttlCache to remove the data.
-// The ttlCache.Remove function is used to remove the key-value pair from the cache.
-func (r memoryStore) Del(_ context.Context, key string) error {
-	return r.ttlCache.Remove(key)
-}
-
-// Get retrieves the value associated with the specified key from the memory cache.
-// The key is a string that serves as the identifier.
-// The dest parameter represents the destination where the retrieved value will be stored.
-// The error returned indicates any issues encountered while retrieving the value.
-// If the specified key is not found in the memory cache, it returns a cache not found error.
-// If an error other than cache not found occurs, it returns that error.
-// The helper.ConvertToDest function is used to convert the retrieved value to the destination type.
-func (r memoryStore) Get(_ context.Context, key string, dest any) error {
-	value, err := r.ttlCache.Get(key)
-	if errors.Is(err, ttlcache.ErrNotFound) {
+// If the key does not exist, nothing is done, like the Redis DEL command.
+func (m *memoryStore) Del(_ context.Context, key string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.remove(key)
+	return nil
+}
+
+// Get retrieves the value associated with the specified key from the memory cache, deserializing it into the dest.
+// If the specified key is not found in the memory cache, or is expired, it returns a cache not found error and the
+// miss is counted. Otherwise, the hit is counted and the entry becomes the most recently used.
+func (m *memoryStore) Get(_ context.Context, key string, dest any) error {
+	return m.read(key, dest, true)
+}
+
+// Peek retrieves the value associated with the specified key like Get, but the hit or miss is not counted and the
+// entry doesn't become the most recently used.
+func (m *memoryStore) Peek(_ context.Context, key string, dest any) error {
+	return m.read(key, dest, false)
+}
+
+// read retrieves the value of the key, deserializing it into the dest, see Get. If counted, the hit or miss is
+// counted and the entry found becomes the most recently used.
+func (m *memoryStore) read(key string, dest any, counted bool) error {
+	m.mutex.Lock()
+	entry := m.get(key)
+	if helper.IsNil(entry) {
+		if counted {
+			m.misses++
+		}
+		m.mutex.Unlock()
 		return appmapper.NewErrCacheNotFound()
-	} else if helper.IsNotNil(err) {
-		return err
 	}
-	return helper.ConvertToDest(value, dest)
+	if counted {
+		m.hits++
+		m.lru.MoveToFront(m.entries[key])
+	}
+	m.mutex.Unlock()
+
+	return json.Unmarshal(entry.value, dest)
 }
 
-func (r memoryStore) Close() error {
+// Scan returns the keys of the memory cache matching the glob pattern, ignoring the expired keys.
+// The pattern is converted to a regular expression by globRegexp, and each key is matched against it.
+func (m *memoryStore) Scan(_ context.Context, pattern string) ([]string, error) {
+	regex, err := globRegexp(pattern)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var keys []string
+	for key, element := range m.entries {
+		if !element.Value.(*memoryEntry).expired() && regex.MatchString(key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// DelByPattern removes the key-value pairs of the memory cache with the keys matching the glob pattern, returning the
+// number of removed pairs. The keys already expired are not counted.
+func (m *memoryStore) DelByPattern(ctx context.Context, pattern string) (int, error) {
+	keys, err := m.Scan(ctx, pattern)
+	if helper.IsNotNil(err) {
+		return 0, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	count := 0
+	for _, key := range keys {
+		if m.remove(key) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Lock sets the lock key with the token and the expire duration only if it is not held or is expired, returning true
+// if the lock was acquired. The lock keys are kept apart from the entries, so they are not evicted by the limits nor
+// counted on the stats, and the check and set are synchronized.
+func (m *memoryStore) Lock(_ context.Context, key, token string, expire time.Duration) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if lock, ok := m.locks[key]; ok && !lock.expired() {
+		return false, nil
+	}
+	lock := memoryLock{token: token}
+	if helper.IsGreaterThan(expire, 0) {
+		lock.expiresAt = time.Now().Add(expire)
+	}
+	m.locks[key] = lock
+	return true, nil
+}
+
+// Unlock removes the lock key only if it holds the token, the check and remove are synchronized.
+func (m *memoryStore) Unlock(_ context.Context, key, token string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if lock, ok := m.locks[key]; ok && helper.Equals(lock.token, token) {
+		delete(m.locks, key)
+	}
 	return nil
 }
+
+// Stats returns the number of entries, the size of the entries and the limits of the memory cache, with the
+// counters of hits, misses and evictions since the store was created.
+func (m *memoryStore) Stats(_ context.Context) (CacheStats, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return CacheStats{
+		Entries:    m.lru.Len(),
+		Memory:     m.memory,
+		MaxEntries: m.maxEntries,
+		MaxMemory:  m.maxMemory,
+		Hits:       m.hits,
+		Misses:     m.misses,
+		Evictions:  m.evictions,
+	}, nil
+}
+
+// Close stops the cleanup of the expired entries, returning nil.
+func (m *memoryStore) Close() error {
+	m.cancel()
+	return nil
+}
+
+// cleanup removes the expired entries and lock keys every memoryStoreCleanupInterval, until the context is canceled.
+func (m *memoryStore) cleanup(ctx context.Context) {
+	ticker := time.NewTicker(memoryStoreCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.mutex.Lock()
+			for key, element := range m.entries {
+				if element.Value.(*memoryEntry).expired() {
+					m.remove(key)
+				}
+			}
+			for key, lock := range m.locks {
+				if lock.expired() {
+					delete(m.locks, key)
+				}
+			}
+			m.mutex.Unlock()
+		}
+	}
+}
+
+// get returns the entry of the key, or nil if not found. If the entry is expired, it is removed and nil is returned.
+// It must be called with the mutex locked.
+func (m *memoryStore) get(key string) *memoryEntry {
+	element, ok := m.entries[key]
+	if !ok {
+		return nil
+	}
+	entry := element.Value.(*memoryEntry)
+	if entry.expired() {
+		m.remove(key)
+		return nil
+	}
+	return entry
+}
+
+// remove removes the entry of the key, returning whether it was found and not expired. It must be called with the
+// mutex locked.
+func (m *memoryStore) remove(key string) bool {
+	element, ok := m.entries[key]
+	if !ok {
+		return false
+	}
+	entry := element.Value.(*memoryEntry)
+	m.lru.Remove(element)
+	delete(m.entries, key)
+	m.memory -= entry.size()
+	return !entry.expired()
+}
+
+// exceeded returns whether the maxEntries or the maxMemory limits are exceeded. It must be called with the mutex
+// locked.
+func (m *memoryStore) exceeded() bool {
+	return (helper.IsGreaterThan(m.maxEntries, 0) && helper.IsGreaterThan(m.lru.Len(), m.maxEntries)) ||
+		(helper.IsGreaterThan(m.maxMemory, 0) && helper.IsGreaterThan(m.memory, m.maxMemory))
+}
+
+// size returns the size of the entry accounted in the memory of the store, the length of the key and of the
+// serialized value.
+func (e *memoryEntry) size() vo.Bytes {
+	return vo.Bytes(len(e.key) + len(e.value))
+}
+
+// expired returns whether the entry has an expiration and it is in the past.
+func (e *memoryEntry) expired() bool {
+	return !e.expiresAt.IsZero() && time.Now().After(e.expiresAt)
+}
+
+// expired returns whether the lock has an expiration and it is in the past.
+func (l memoryLock) expired() bool {
+	return !l.expiresAt.IsZero() && time.Now().After(l.expiresAt)
+}
+
+// globRegexp converts the glob pattern, in the same syntax of the Redis SCAN MATCH, to an anchored regular expression.
+// `*` matches any sequence of characters, `?` matches any single character, `[...]` matches any character of the set,
+// with `[^...]` negating it, and `\` escapes the next character.
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	var builder strings.Builder
+	builder.WriteString("^")
+	inSet := false
+	for i := 0; i < len(pattern); i++ {
+		char := pattern[i]
+		switch {
+		case char == '\\' && i+1 < len(pattern):
+			i++
+			builder.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		case inSet:
+			if char == ']' {
+				inSet = false
+			}
+			builder.WriteByte(char)
+		case char == '*':
+			builder.WriteString(".*")
+		case char == '?':
+			builder.WriteString(".")
+		case char == '[':
+			inSet = true
+			builder.WriteByte(char)
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+	builder.WriteString("$")
+	return regexp.Compile(builder.String())
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package infra
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-errors/errors"
+	appmapper "github.com/GabrielHCataldo/gopen-gateway/internal/app/mapper"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreUnlock(t *testing.T) {
+	store := NewMemoryStore(0, 0)
+	defer store.Close()
+	ctx := context.Background()
+
+	if locked, err := store.Lock(ctx, "lock", "first", time.Minute); err != nil || !locked {
+		t.Fatalf("Lock() = %v, %v, want true, nil", locked, err)
+	}
+	if locked, err := store.Lock(ctx, "lock", "second", time.Minute); err != nil || locked {
+		t.Fatalf("Lock() while locked = %v, %v, want false, nil", locked, err)
+	}
+
+	// o token de outra instância não libera o lock
+	if err := store.Unlock(ctx, "lock", "second"); err != nil {
+		t.Fatalf("Unlock() err = %v", err)
+	}
+	if locked, _ := store.Lock(ctx, "lock", "second", time.Minute); locked {
+		t.Fatal("Lock() after Unlock() with other token = true, want false")
+	}
+
+	if err := store.Unlock(ctx, "lock", "first"); err != nil {
+		t.Fatalf("Unlock() err = %v", err)
+	}
+	if locked, _ := store.Lock(ctx, "lock", "second", time.Minute); !locked {
+		t.Fatal("Lock() after Unlock() = false, want true")
+	}
+}
+
+func TestMemoryStoreLockAndPeekStats(t *testing.T) {
+	store := NewMemoryStore(1, 0)
+	defer store.Close()
+	ctx := context.Background()
+
+	if err := store.Set(ctx, "key", "value", time.Minute); err != nil {
+		t.Fatalf("Set() err = %v", err)
+	}
+	// o lock não é contado nas entradas nem remove a entrada pelo limite
+	if locked, err := store.Lock(ctx, "lock", "token", time.Minute); err != nil || !locked {
+		t.Fatalf("Lock() = %v, %v, want true, nil", locked, err)
+	}
+
+	var value string
+	if err := store.Peek(ctx, "key", &value); err != nil || value != "value" {
+		t.Fatalf("Peek() = %q, %v, want %q, nil", value, err, "value")
+	}
+	if err := store.Peek(ctx, "missing", &value); errors.IsNot(err, appmapper.ErrCacheNotFound) {
+		t.Fatalf("Peek() of missing key err = %v, want not found", err)
+	}
+
+	stats, _ := store.Stats(ctx)
+	if stats.Entries != 1 || stats.Evictions != 0 || stats.Hits != 0 || stats.Misses != 0 {
+		t.Fatalf("Stats() = %+v, want 1 entry without evictions, hits and misses", stats)
+	}
+
+	if err := store.Get(ctx, "missing", &value); errors.IsNot(err, appmapper.ErrCacheNotFound) {
+		t.Fatalf("Get() of missing key err = %v, want not found", err)
+	}
+	if stats, _ = store.Stats(ctx); stats.Misses != 1 {
+		t.Fatalf("Stats().Misses = %d, want 1", stats.Misses)
+	}
+}
+
+func TestMemoryStoreConcurrentNotFound(t *testing.T) {
+	store := NewMemoryStore(0, 0)
+	defer store.Close()
+	ctx := context.Background()
+
+	// os erros de chave não encontrada são criados por goroutines concorrentes sem alterar o sentinel
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var value string
+			if err := store.Get(ctx, "missing", &value); errors.IsNot(err, appmapper.ErrCacheNotFound) {
+				t.Errorf("Get() of missing key err = %v, want not found", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
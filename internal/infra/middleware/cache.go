@@ -21,14 +21,35 @@ import (
 	"github.com/GabrielHCataldo/go-helper/helper"
 	"github.com/GabrielHCataldo/go-logger/logger"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/app/mapper"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/consts"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/vo"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/infra"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/infra/api"
+	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
 )
 
+// cacheLockPollInterval represents the first interval in which the cache store is read while waiting for the gateway
+// instance holding the lock key to cache the response, see EndpointCache.CoalesceLock. The interval is doubled after
+// each read, up to cacheLockMaxPollInterval, see cache.waitCache.
+const cacheLockPollInterval = 50 * time.Millisecond
+
+// cacheLockMaxPollInterval represents the maximum interval in which the cache store is read while waiting for the
+// lock key, see cacheLockPollInterval.
+const cacheLockMaxPollInterval = time.Second
+
 // cache represents a Cache implementation that uses the provided infra.CacheStore for caching operations.
+// The group collapses the concurrent requests with the same cache key, so only one of them calls the next handlers,
+// and the revalidations keep the cache keys being revalidated in background, so only one revalidation runs per key.
 type cache struct {
-	cacheStore infra.CacheStore
+	cacheStore    infra.CacheStore
+	group         *singleflight.Group
+	revalidations *sync.Map
 }
 
 // Cache represents an interface for caching operations.
@@ -41,58 +62,232 @@ type Cache interface {
 	// The cacheVO object contains information about cache configuration
 	// such as duration, strategy headers, allowed status codes, and allowed methods.
 	//
+	// The handler is the endpoint handler, called outside the request to revalidate the stale cache responses.
+	//
 	// The returned HandlerFunc is responsible for handling the HTTP request,
 	// implementing cache-related logic based on the provided cache configuration.
-	Do(endpointCacheVO *vo.EndpointCache) api.HandlerFunc
+	Do(endpointCacheVO *vo.EndpointCache, handler api.HandlerFunc) api.HandlerFunc
 }
 
 // NewCache returns a Cache implementation that uses the provided CacheStore for caching operations.
 func NewCache(cacheStore infra.CacheStore) Cache {
 	return cache{
-		cacheStore: cacheStore,
+		cacheStore:    cacheStore,
+		group:         &singleflight.Group{},
+		revalidations: &sync.Map{},
 	}
 }
 
 // Do execute the cache logic based on the provided endpoint cache value object and returns a HandlerFunc.
-// It initializes the cache key based on the strategy, checks if the cache can be read, and responds with the cached value if available.
-// If the cache cannot be read or is not found, it proceeds to the next handler.
-// After the next handler is executed, it checks if the response can be cached, sets the cache value, and logs any errors.
-func (c cache) Do(endpointCacheVO *vo.EndpointCache) api.HandlerFunc {
+// It initializes the cache key based on the strategy, checks if the cache can be read, and responds with the cached value if fresh.
+// If the cached value is stale within the stale-while-revalidate duration, it responds with it and revalidates it in
+// background with the handler, see revalidate. If it is stale within the stale-if-error duration, it is responded
+// only if the handler fails.
+// If the cache cannot be read or is not found, it collapses the concurrent requests with the same key, so only the
+// first one executes the handler, on a context detached from its client, see api.Context.Detach, so its cancellation
+// doesn't affect the others. The others respond with the same response only if it can be cached, see
+// vo.EndpointCache.CanWrite, otherwise they proceed to the next handler.
+// After the handler is executed, it checks if the response can be cached, sets the cache value, and logs any errors.
+func (c cache) Do(endpointCacheVO *vo.EndpointCache, handler api.HandlerFunc) api.HandlerFunc {
 	return func(ctx *api.Context) {
 		// inicializamos a chave que vai ser utilizada
 		key := endpointCacheVO.StrategyKey(ctx.Request())
 
-		// verificamos se ele permite ler o cache
-		if endpointCacheVO.CanRead(ctx.Request()) {
-			// inicializamos o valor a ser obtido
-			var cacheResponse vo.CacheResponse
+		// caso não possa ler o cache, seguimos normalmente sem agrupar as requisições
+		if !endpointCacheVO.CanRead(ctx.Request()) {
+			c.next(ctx, endpointCacheVO, key)
+			return
+		}
+
+		// inicializamos o valor a ser obtido
+		var cacheResponse vo.CacheResponse
 
-			// obtemos através do cache store se a chave exists respondemos, se não seguimos normalmente
-			err := c.cacheStore.Get(ctx.Context(), key, &cacheResponse)
-			if helper.IsNil(err) {
-				ctx.WriteCacheResponse(&cacheResponse)
-				return
-			} else if errors.IsNot(err, mapper.ErrCacheNotFound) {
-				logger.Warning("Error read cache key:", key, "err:", err)
+		// obtemos através do cache store se a chave exists respondemos, se não seguimos normalmente
+		err := c.cacheStore.Get(ctx.Context(), key, &cacheResponse)
+		if helper.IsNil(err) && cacheResponse.Fresh() {
+			ctx.WriteCacheResponse(&cacheResponse, enum.CacheStatusHit)
+			return
+		} else if helper.IsNil(err) && endpointCacheVO.CanServeStaleWhileRevalidate(&cacheResponse) {
+			ctx.WriteCacheResponse(&cacheResponse, enum.CacheStatusStale)
+			c.revalidate(ctx, endpointCacheVO, key, handler)
+			return
+		} else if helper.IsNil(err) && endpointCacheVO.CanServeStaleIfError(&cacheResponse) {
+			ctx.SetStaleIfError(&cacheResponse)
+		} else if helper.IsNotNil(err) && errors.IsNot(err, mapper.ErrCacheNotFound) {
+			logger.Warning("Error read cache key:", key, "err:", err)
+		}
+
+		// agrupamos as requisições concorrentes com a mesma chave, somente a primeira executa o handler
+		var responseVO *vo.Response
+		result, _, _ := c.group.Do(key, func() (any, error) {
+			responseVO = c.executeDetached(ctx, endpointCacheVO, key, handler)
+			// compartilhamos a resposta somente se ela for do cache ou puder ser gravada nele
+			if isCacheHit(responseVO) || endpointCacheVO.CanWrite(ctx.Request(), responseVO) {
+				return responseVO, nil
 			}
+			return nil, nil
+		})
+
+		// a primeira requisição responde a sua resposta, as outras a resposta compartilhada ou seguem no handler
+		if helper.IsNotNil(responseVO) {
+			ctx.Write(responseVO)
+		} else if helper.IsNotNil(result) {
+			ctx.Write(result.(*vo.Response))
+		} else {
+			c.next(ctx, endpointCacheVO, key)
 		}
+	}
+}
 
-		// damos próximo no handler
-		ctx.Next()
+// executeDetached executes the handler with the lock, see nextWithLock, on a context detached from the client with
+// the timeout of the endpoint, see api.Context.Detach, returning the response written by the handler.
+func (c cache) executeDetached(ctx *api.Context, endpointCacheVO *vo.EndpointCache, key string,
+	handler api.HandlerFunc) *vo.Response {
+	detached, cancel := ctx.Detach(ctx.Endpoint().Timeout())
+	defer cancel()
 
-		// verificamos se podemos gravar a resposta
-		if endpointCacheVO.CanWrite(ctx.Request(), ctx.Response()) {
-			// instanciamos a duração
-			duration := endpointCacheVO.Duration()
+	c.nextWithLock(detached, endpointCacheVO, key, handler)
+	return detached.Response()
+}
 
-			// construímos o valor a ser setado no cache
-			cacheResponse := vo.NewCacheResponse(ctx.Response(), duration)
+// nextWithLock calls execute, but if the EndpointCache has a coalesce lock, it first acquires the lock key of the
+// cache key on the cache store, so only one gateway instance executes the handler. If the lock is held by another
+// instance, it waits for the response to be cached, responding with it, or for the lock to expire, calling execute.
+func (c cache) nextWithLock(ctx *api.Context, endpointCacheVO *vo.EndpointCache, key string,
+	handler api.HandlerFunc) {
+	// caso não tenha lock configurado seguimos normalmente
+	if !endpointCacheVO.HasCoalesceLock() {
+		c.execute(ctx, endpointCacheVO, key, handler)
+		return
+	}
+
+	// tentamos obter o lock da chave, com um token único para que somente essa instância o libere
+	lockKey := vo.CacheLockKey(key)
+	token := uuid.New().String()
+	locked, err := c.cacheStore.Lock(ctx.Context(), lockKey, token, endpointCacheVO.CoalesceLock())
+	if helper.IsNotNil(err) {
+		logger.Warning("Error lock cache key:", lockKey, "err:", err)
+	} else if locked {
+		defer c.unlock(ctx, lockKey, token)
+	} else if cacheResponse := c.waitCache(ctx, key, endpointCacheVO.CoalesceLock()); helper.IsNotNil(cacheResponse) {
+		ctx.WriteCacheResponse(cacheResponse, enum.CacheStatusHit)
+		return
+	}
+
+	// executamos o handler
+	c.execute(ctx, endpointCacheVO, key, handler)
+}
+
+// waitCache reads the cache store until the fresh response of the key is found or the timeout elapses, returning nil
+// in that case or if the request is canceled. The reads start every cacheLockPollInterval and back off up to every
+// cacheLockMaxPollInterval, so the requests waiting for a slow backend don't overload the cache store. The reads are
+// made with infra.CacheStore.Peek, so they are not counted on the cache stats.
+func (c cache) waitCache(ctx *api.Context, key string, timeout time.Duration) *vo.CacheResponse {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	interval := cacheLockPollInterval
+	poll := time.NewTimer(interval)
+	defer poll.Stop()
 
-			// transformamos em cacheResponse e setamos
-			err := c.cacheStore.Set(ctx.Context(), key, cacheResponse, duration)
-			if helper.IsNotNil(err) {
-				logger.Warning("Error write cache key:", key, "err:", err)
+	for {
+		select {
+		case <-ctx.Context().Done():
+			return nil
+		case <-timer.C:
+			return nil
+		case <-poll.C:
+			var cacheResponse vo.CacheResponse
+			if err := c.cacheStore.Peek(ctx.Context(), key, &cacheResponse); helper.IsNil(err) && cacheResponse.Fresh() {
+				return &cacheResponse
 			}
+			// aumentamos o intervalo até o máximo para a próxima leitura
+			interval = min(interval*2, cacheLockMaxPollInterval)
+			poll.Reset(interval)
 		}
 	}
 }
+
+// unlock releases the lock key on the cache store if it still holds the token, logging any errors. If not released,
+// the lock key expires.
+func (c cache) unlock(ctx *api.Context, lockKey, token string) {
+	if err := c.cacheStore.Unlock(ctx.Context(), lockKey, token); helper.IsNotNil(err) {
+		logger.Warning("Error unlock cache key:", lockKey, "err:", err)
+	}
+}
+
+// revalidate executes the handler in background to revalidate the stale cache response of the key, on a context
+// detached from the client with the timeout of the endpoint, see api.Context.Detach, so the request returns right
+// after the stale response is written. Only one revalidation per key is executed by the gateway instance at a time,
+// the others are ignored. If the revalidation fails, the stale response is kept on the cache, see write.
+func (c cache) revalidate(ctx *api.Context, endpointCacheVO *vo.EndpointCache, key string, handler api.HandlerFunc) {
+	// caso a chave já esteja sendo revalidada, não fazemos nada
+	if _, loaded := c.revalidations.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+
+	// destacamos o contexto do cliente antes da requisição retornar
+	detached, cancel := ctx.Detach(ctx.Endpoint().Timeout())
+
+	go func() {
+		defer c.revalidations.Delete(key)
+		defer cancel()
+		// chamamos o panic recovery caso ocorra, já que a requisição retornou
+		defer func() {
+			if p := recover(); helper.IsNotNil(p) {
+				logger.Errorf("Error revalidate cache key: %s err: %s: %s", key, p, string(debug.Stack()))
+			}
+		}()
+
+		// executamos o handler e gravamos a resposta revalidada
+		handler(detached)
+		c.write(detached, endpointCacheVO, key, true)
+	}()
+}
+
+// next proceeds to the next handler and, after it is executed, writes the response on the cache, see write.
+func (c cache) next(ctx *api.Context, endpointCacheVO *vo.EndpointCache, key string) {
+	// damos próximo no handler
+	ctx.Next()
+
+	// gravamos a resposta caso possa
+	c.write(ctx, endpointCacheVO, key, false)
+}
+
+// execute executes the handler and, after it is executed, writes the response on the cache, see write.
+func (c cache) execute(ctx *api.Context, endpointCacheVO *vo.EndpointCache, key string, handler api.HandlerFunc) {
+	// executamos o handler
+	handler(ctx)
+
+	// gravamos a resposta caso possa
+	c.write(ctx, endpointCacheVO, key, false)
+}
+
+// write checks if the response can be cached, sets the cache value, and logs any errors. If the stale response is
+// being revalidated and the revalidation fails, the stale response is kept on the cache.
+func (c cache) write(ctx *api.Context, endpointCacheVO *vo.EndpointCache, key string, revalidating bool) {
+	// caso a revalidação tenha falhado, mantemos a resposta stale no cache
+	if revalidating && (ctx.Response().Failed() ||
+		helper.IsGreaterThanOrEqual(ctx.Response().StatusCode(), http.StatusInternalServerError)) {
+		return
+	}
+
+	// verificamos se podemos gravar a resposta
+	if endpointCacheVO.CanWrite(ctx.Request(), ctx.Response()) {
+		// instanciamos a duração, configurada ou obtida dos headers da resposta
+		duration := endpointCacheVO.TTL(ctx.Response())
+
+		// construímos o valor a ser setado no cache
+		cacheResponse := vo.NewCacheResponse(ctx.Response(), duration)
+
+		// transformamos em cacheResponse e setamos, mantendo no cache store enquanto puder ser servido stale
+		err := c.cacheStore.Set(ctx.Context(), key, cacheResponse, endpointCacheVO.StoreDuration(duration))
+		if helper.IsNotNil(err) {
+			logger.Warning("Error write cache key:", key, "err:", err)
+		}
+	}
+}
+
+// isCacheHit returns whether the response was obtained fresh from the cache, see enum.CacheStatusHit.
+func isCacheHit(responseVO *vo.Response) bool {
+	return helper.Equals(responseVO.Header().Get(consts.XGopenCache), string(enum.CacheStatusHit))
+}
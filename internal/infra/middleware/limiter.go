@@ -17,13 +17,11 @@
 package middleware
 
 import (
-	"github.com/GabrielHCataldo/go-errors/errors"
 	"github.com/GabrielHCataldo/go-helper/helper"
 	domainmapper "github.com/GabrielHCataldo/gopen-gateway/internal/domain/mapper"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/consts"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/infra"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/infra/api"
-	"net/http"
 )
 
 type limiter struct {
@@ -58,17 +56,14 @@ func (l limiter) Do(rateLimiterProvider infra.RateLimiterProvider, sizeLimiterPr
 		// aqui ja verificamos se a chave hoje sendo ela o IP está permitida
 		err := rateLimiterProvider.Allow(ctx.HeaderValue(consts.XForwardedFor))
 		if helper.IsNotNil(err) {
-			ctx.WriteError(http.StatusTooManyRequests, err)
+			ctx.WriteError(domainmapper.StatusCodeByErr(err), err)
 			return
 		}
 
 		// verificamos o tamanho da requisição, e tratamos o erro logo em seguida
 		err = sizeLimiterProvider.Allow(ctx.Http())
-		if errors.Contains(err, domainmapper.ErrHeaderTooLarge) {
-			ctx.WriteError(http.StatusRequestHeaderFieldsTooLarge, err)
-			return
-		} else if helper.IsNotNil(err) {
-			ctx.WriteError(http.StatusRequestEntityTooLarge, err)
+		if helper.IsNotNil(err) {
+			ctx.WriteError(domainmapper.StatusCodeByErr(err), err)
 			return
 		}
 
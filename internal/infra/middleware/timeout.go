@@ -18,11 +18,10 @@ package middleware
 
 import (
 	"context"
-	"github.com/GabrielHCataldo/go-errors/errors"
 	"github.com/GabrielHCataldo/go-helper/helper"
 	"github.com/GabrielHCataldo/go-logger/logger"
+	domainmapper "github.com/GabrielHCataldo/gopen-gateway/internal/domain/mapper"
 	"github.com/GabrielHCataldo/gopen-gateway/internal/infra/api"
-	"net/http"
 	"runtime/debug"
 	"time"
 )
@@ -50,9 +49,9 @@ func NewTimeout() Timeout {
 // It then spawns a goroutine that calls the panic recovery function in case of a panic and calls the next handler in the request.
 // If the request finishes before the timeout, it sends a signal to the finishChan.
 // If a panic occurs, it sends the panic value to the panicChan.
-// If the context timeout occurs before the request finishes, it sets the statusCode to http.StatusGatewayTimeout and the error to "gateway timeout: <timeoutDuration>".
+// If the context timeout occurs before the request finishes, it sets the error to a gateway timeout error with the timeoutDuration.
 // It then waits for one of the three channels to receive a signal by using a select statement.
-// Finally, it checks whether the statusCode is greater than 0 and writes the error response using ctx.WriteError if true.
+// Finally, it checks whether an error occurred and writes the error response with its status code using ctx.WriteError if true.
 func (t timeout) Do(timeoutDuration time.Duration) api.HandlerFunc {
 	return func(ctx *api.Context) {
 		// inicializamos o context com timeout fornecido na config do gateway
@@ -81,7 +80,6 @@ func (t timeout) Do(timeoutDuration time.Duration) api.HandlerFunc {
 		}()
 
 		// inicializamos as variáveis para serem utilizadas ou não no futuro
-		var statusCode int
 		var err error
 
 		// seguramos o goroutine principal aguardando os canais ou o context serem notificados
@@ -89,18 +87,16 @@ func (t timeout) Do(timeoutDuration time.Duration) api.HandlerFunc {
 		case <-finishChan:
 			break
 		case <-panicChan:
-			statusCode = http.StatusInternalServerError
-			err = errors.New("panic error occurred")
+			err = domainmapper.NewErrPanic()
 			break
 		case <-ctx.Context().Done():
-			statusCode = http.StatusGatewayTimeout
-			err = errors.New("gateway timeout:", timeoutDuration.String())
+			err = domainmapper.NewErrGatewayTimeout(timeoutDuration)
 			break
 		}
 
 		// caso tenha passado nos dois fluxos de timeout ou de erro, respondemos à requisição
-		if helper.IsGreaterThan(statusCode, 0) {
-			ctx.WriteError(statusCode, err)
+		if helper.IsNotNil(err) {
+			ctx.WriteError(domainmapper.StatusCodeByErr(err), err)
 		}
 	}
 }
@@ -18,39 +18,201 @@ package infra
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
 	"github.com/GabrielHCataldo/go-errors/errors"
 	"github.com/GabrielHCataldo/go-helper/helper"
-	"github.com/GabrielHCataldo/go-redis-template/redis"
-	"github.com/GabrielHCataldo/go-redis-template/redis/option"
 	appmapper "github.com/GabrielHCataldo/gopen-gateway/internal/app/mapper"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/vo"
+	"github.com/redis/go-redis/v9"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// redisScanCount represents the number of keys requested on each iteration of the Redis SCAN command, and the number
+// of keys deleted on each batch by DelByPattern.
+const redisScanCount = 100
+
+// redisUnlockScript deletes the lock key only if its value is the token, atomically on the Redis server, see
+// redisStore.Unlock.
+var redisUnlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
 // redisStore represents a Redis cache store that implements the CacheStore interface.
+// The client is connected to a single server, to the master monitored by the Sentinel nodes or to a Cluster,
+// according to the mode, and every key is prefixed with the keyPrefix.
+// The hits and misses count the reads of this gateway instance, see Stats.
 type redisStore struct {
-	redisTemplate *redis.Template
+	client    redis.UniversalClient
+	mode      enum.RedisMode
+	keyPrefix string
+	hits      *atomic.Uint64
+	misses    *atomic.Uint64
 }
 
-// NewRedisStore creates a new Redis cache store with the given address and password.
-// It returns a CacheStore interface that can be used to interact with the Redis cache.
-func NewRedisStore(address, password string) CacheStore {
+// NewRedisStore creates a new Redis cache store with the given configuration.
+// It returns a CacheStore interface that can be used to interact with the Redis cache, or an error if the
+// configuration is invalid, like an unknown mode, a Sentinel without master name, a Cluster with a DB other than 0,
+// an invalid duration or TLS files that cannot be loaded. The connections are opened lazily, by the commands.
+func NewRedisStore(redisDTO *dto.Redis) (CacheStore, error) {
+	mode := redisDTO.Mode
+	if helper.IsEmpty(mode) {
+		mode = enum.RedisModeStandalone
+	}
+	opts, err := newRedisOptions(mode, redisDTO)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+
+	// instanciamos o client de acordo com o modo
+	var client redis.UniversalClient
+	switch mode {
+	case enum.RedisModeSentinel:
+		client = redis.NewFailoverClient(opts.Failover())
+	case enum.RedisModeCluster:
+		client = redis.NewClusterClient(opts.Cluster())
+	default:
+		client = redis.NewClient(opts.Simple())
+	}
 	return &redisStore{
-		redisTemplate: redis.NewTemplate(option.Client{
-			Addr:     address,
-			Password: password,
-		}),
+		client:    client,
+		mode:      mode,
+		keyPrefix: redisDTO.KeyPrefix,
+		hits:      &atomic.Uint64{},
+		misses:    &atomic.Uint64{},
+	}, nil
+}
+
+// newRedisOptions validates the Redis configuration for the mode and converts it to the options of the Redis client.
+func newRedisOptions(mode enum.RedisMode, redisDTO *dto.Redis) (*redis.UniversalOptions, error) {
+	if !mode.IsEnumValid() {
+		return nil, errors.New(fmt.Sprintf("Invalid redis mode: %s", mode))
+	}
+
+	// juntamos o endereço com os demais endereços informados
+	var addresses []string
+	for _, address := range append([]string{redisDTO.Address}, redisDTO.Addresses...) {
+		if helper.IsNotEmpty(strings.TrimSpace(address)) {
+			addresses = append(addresses, strings.TrimSpace(address))
+		}
+	}
+	if helper.IsEmpty(addresses) {
+		return nil, errors.New("Redis address is required!")
+	} else if helper.Equals(mode, enum.RedisModeStandalone) && helper.IsGreaterThan(len(addresses), 1) {
+		return nil, errors.New("Redis standalone mode accepts only one address, use the sentinel or cluster mode!")
+	} else if helper.Equals(mode, enum.RedisModeSentinel) && helper.IsEmpty(redisDTO.MasterName) {
+		return nil, errors.New("Redis master-name is required on sentinel mode!")
+	} else if helper.Equals(mode, enum.RedisModeCluster) && helper.IsNotEqualTo(redisDTO.DB, 0) {
+		return nil, errors.New("Redis db is not supported on cluster mode!")
+	} else if helper.IsLessThan(redisDTO.DB, 0) {
+		return nil, errors.New(fmt.Sprintf("Invalid redis db: %d", redisDTO.DB))
+	}
+
+	opts := &redis.UniversalOptions{
+		Addrs:            addresses,
+		MasterName:       redisDTO.MasterName,
+		DB:               redisDTO.DB,
+		Username:         redisDTO.Username,
+		Password:         redisDTO.Password,
+		SentinelUsername: redisDTO.SentinelUsername,
+		SentinelPassword: redisDTO.SentinelPassword,
+		PoolSize:         redisDTO.PoolSize,
+		MinIdleConns:     redisDTO.MinIdleConns,
 	}
+
+	// convertemos as durações informadas
+	durations := []struct {
+		name  string
+		value string
+		dest  *time.Duration
+	}{
+		{"pool-timeout", redisDTO.PoolTimeout, &opts.PoolTimeout},
+		{"dial-timeout", redisDTO.DialTimeout, &opts.DialTimeout},
+		{"read-timeout", redisDTO.ReadTimeout, &opts.ReadTimeout},
+		{"write-timeout", redisDTO.WriteTimeout, &opts.WriteTimeout},
+	}
+	for _, duration := range durations {
+		if helper.IsEmpty(duration.value) {
+			continue
+		}
+		value, err := time.ParseDuration(duration.value)
+		if helper.IsNotNil(err) {
+			return nil, errors.New(fmt.Sprintf("Invalid redis %s: %s", duration.name, duration.value))
+		}
+		*duration.dest = value
+	}
+
+	// configuramos o TLS caso habilitado
+	if helper.IsNotNil(redisDTO.TLS) && redisDTO.TLS.Enabled {
+		tlsConfig, err := newRedisTLSConfig(redisDTO.TLS, addresses[0])
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		opts.TLSConfig = tlsConfig
+	}
+	return opts, nil
+}
+
+// newRedisTLSConfig builds the TLS configuration of the Redis connections, with TLS 1.2 as minimum version.
+// The server name defaults to the host of the address, the certificate authorities of the CA file are trusted
+// instead of the system ones, if informed, and the client certificate is loaded from the cert and key files.
+func newRedisTLSConfig(tlsDTO *dto.RedisTLS, address string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         tlsDTO.ServerName,
+		InsecureSkipVerify: tlsDTO.InsecureSkipVerify,
+	}
+	if helper.IsEmpty(tlsConfig.ServerName) {
+		host, _, err := net.SplitHostPort(address)
+		if helper.IsNotNil(err) {
+			host = address
+		}
+		tlsConfig.ServerName = host
+	}
+	if helper.IsNotEmpty(tlsDTO.CaFile) {
+		caPem, err := os.ReadFile(tlsDTO.CaFile)
+		if helper.IsNotNil(err) {
+			return nil, errors.New("Error read redis tls.ca-file:", err)
+		}
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caPem) {
+			return nil, errors.New("Error read redis tls.ca-file: no PEM certificate found")
+		}
+		tlsConfig.RootCAs = certPool
+	}
+	if helper.IsNotEmpty(tlsDTO.CertFile) || helper.IsNotEmpty(tlsDTO.KeyFile) {
+		certificate, err := tls.LoadX509KeyPair(tlsDTO.CertFile, tlsDTO.KeyFile)
+		if helper.IsNotNil(err) {
+			return nil, errors.New("Error load redis tls.cert-file and tls.key-file:", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	return tlsConfig, nil
 }
 
 // Set sets the value of the given key in the Redis cache.
 // It takes the context, key, value, and expiration duration as parameters.
-// The value can be of any type.
+// The value can be of any type, and is converted to string, structs and maps as JSON.
 // The expiry parameter specifies the time after which the key will expire in the cache.
 // It returns an error if there was a problem setting the value in the cache.
 // If the key does not exist in the cache, it will be created.
-// If the value is not already of type "any", it will be converted to "any".
 func (r redisStore) Set(ctx context.Context, key string, value any, expire time.Duration) error {
-	return r.redisTemplate.Set(ctx, key, value, option.NewSet().SetTTL(expire))
+	sValue, err := helper.ConvertToString(value)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	return r.client.Set(ctx, r.prefixed(key), sValue, expire).Err()
 }
 
 // Del deletes the value associated with the given key from the Redis cache.
@@ -59,7 +221,7 @@ func (r redisStore) Set(ctx context.Context, key string, value any, expire time.
 // If there is any error during the deletion, that error is returned.
 // If everything goes well, Del returns nil.
 func (r redisStore) Del(ctx context.Context, key string) error {
-	return r.redisTemplate.Del(ctx, key)
+	return r.client.Del(ctx, r.prefixed(key)).Err()
 }
 
 // Get retrieves the value associated with the given key from the Redis cache.
@@ -69,17 +231,129 @@ func (r redisStore) Del(ctx context.Context, key string) error {
 // If there is any other error during the retrieval, that error is returned.
 // If everything goes well, Get returns nil.
 func (r redisStore) Get(ctx context.Context, key string, dest any) error {
-	err := r.redisTemplate.Get(ctx, key, dest)
-	if errors.Is(err, redis.ErrKeyNotFound) {
+	result, err := r.client.Get(ctx, r.prefixed(key)).Result()
+	if errors.Is(err, redis.Nil) {
+		r.misses.Add(1)
 		return appmapper.NewErrCacheNotFound()
 	} else if helper.IsNotNil(err) {
 		return err
 	}
-	return nil
+	r.hits.Add(1)
+	return helper.ConvertToDest(result, dest)
 }
 
-// Close closes the connection to the Redis server.
-// It returns an error if there was a problem disconnecting from the server.
+// Peek retrieves the value associated with the given key from the Redis cache like Get, but the read is not counted
+// on the hits and misses of this gateway instance.
+func (r redisStore) Peek(ctx context.Context, key string, dest any) error {
+	result, err := r.client.Get(ctx, r.prefixed(key)).Result()
+	if errors.Is(err, redis.Nil) {
+		return appmapper.NewErrCacheNotFound()
+	} else if helper.IsNotNil(err) {
+		return err
+	}
+	return helper.ConvertToDest(result, dest)
+}
+
+// Scan returns the keys of the Redis cache matching the glob pattern, without the key prefix.
+// It iterates the keys with the SCAN command, using the escaped key prefix and the pattern as MATCH, until the cursor
+// returns to 0, so the Redis server is not blocked like with the KEYS command. On the cluster mode, every master
+// node is scanned, as each one has only its slots keys.
+func (r redisStore) Scan(ctx context.Context, pattern string) ([]string, error) {
+	match := vo.EscapeGlob(r.keyPrefix) + pattern
+	if !helper.Equals(r.mode, enum.RedisModeCluster) {
+		return r.scanNode(ctx, r.client, match)
+	}
+
+	var mutex sync.Mutex
+	var keys []string
+	err := r.client.(*redis.ClusterClient).ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+		nodeKeys, err := r.scanNode(ctx, client, match)
+		mutex.Lock()
+		defer mutex.Unlock()
+		keys = append(keys, nodeKeys...)
+		return err
+	})
+	return keys, err
+}
+
+// scanNode returns the keys of the node matching the glob pattern, without the key prefix, see Scan.
+func (r redisStore) scanNode(ctx context.Context, client redis.Cmdable, match string) ([]string, error) {
+	var keys []string
+	var cursor uint64
+	for {
+		page, nextCursor, err := client.Scan(ctx, cursor, match, redisScanCount).Result()
+		if helper.IsNotNil(err) {
+			return keys, err
+		}
+		for _, key := range page {
+			keys = append(keys, strings.TrimPrefix(key, r.keyPrefix))
+		}
+		cursor = nextCursor
+		if helper.Equals(cursor, uint64(0)) {
+			break
+		} else if helper.IsNotNil(ctx.Err()) {
+			return keys, ctx.Err()
+		}
+	}
+	return keys, nil
+}
+
+// DelByPattern deletes the values of the Redis cache with the keys matching the glob pattern, returning the number
+// of deleted keys. The keys are scanned first, see Scan, and then deleted in pipelines of redisScanCount commands,
+// one DEL per key, so the keys of different slots can be deleted on the cluster mode.
+func (r redisStore) DelByPattern(ctx context.Context, pattern string) (int, error) {
+	keys, err := r.Scan(ctx, pattern)
+	if helper.IsNotNil(err) {
+		return 0, err
+	}
+	count := 0
+	for start := 0; start < len(keys); start += redisScanCount {
+		end := min(start+redisScanCount, len(keys))
+		pipeline := r.client.Pipeline()
+		commands := make([]*redis.IntCmd, 0, end-start)
+		for _, key := range keys[start:end] {
+			commands = append(commands, pipeline.Del(ctx, r.prefixed(key)))
+		}
+		_, err = pipeline.Exec(ctx)
+		for _, command := range commands {
+			count += int(command.Val())
+		}
+		if helper.IsNotNil(err) {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// Lock sets the lock key on the Redis cache with the token, the NX mode, so it is only set if it does not exist, and
+// the expire duration as TTL. If the key already exists, the lock is not acquired.
+func (r redisStore) Lock(ctx context.Context, key, token string, expire time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, r.prefixed(key), token, expire).Result()
+}
+
+// Unlock deletes the lock key from the Redis cache only if its value is the token, comparing and deleting it
+// atomically with the redisUnlockScript.
+func (r redisStore) Unlock(ctx context.Context, key, token string) error {
+	return redisUnlockScript.Run(ctx, r.client, []string{r.prefixed(key)}, token).Err()
+}
+
+// Stats returns the counters of hits and misses of the reads made by this gateway instance since the store was
+// created. The entries, the memory and the evictions are managed by the Redis server, with its maxmemory policy, so
+// they are not counted.
+func (r redisStore) Stats(_ context.Context) (CacheStats, error) {
+	return CacheStats{
+		Hits:   r.hits.Load(),
+		Misses: r.misses.Load(),
+	}, nil
+}
+
+// Close closes the connections to the Redis servers.
+// It returns an error if there was a problem disconnecting from the servers.
 func (r redisStore) Close() error {
-	return r.redisTemplate.Disconnect()
+	return r.client.Close()
+}
+
+// prefixed returns the key with the key prefix.
+func (r redisStore) prefixed(key string) string {
+	return r.keyPrefix + key
 }
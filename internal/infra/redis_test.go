@@ -0,0 +1,326 @@
+/*
+ * Copyright 2024 Gabriel Cataldo
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package infra
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/GabrielHCataldo/go-errors/errors"
+	appmapper "github.com/GabrielHCataldo/gopen-gateway/internal/app/mapper"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/app/model/dto"
+	"github.com/GabrielHCataldo/gopen-gateway/internal/domain/model/enum"
+	"github.com/alicebob/miniredis/v2"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestNewRedisOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     enum.RedisMode
+		redisDTO dto.Redis
+		wantErr  bool
+	}{
+		{"invalid mode", "REPLICA", dto.Redis{Address: "localhost:6379"}, true},
+		{"without address", enum.RedisModeStandalone, dto.Redis{Addresses: []string{" "}}, true},
+		{"standalone with many addresses", enum.RedisModeStandalone,
+			dto.Redis{Address: "redis-1:6379", Addresses: []string{"redis-2:6379"}}, true},
+		{"sentinel without master name", enum.RedisModeSentinel, dto.Redis{Address: "sentinel:26379"}, true},
+		{"cluster with db", enum.RedisModeCluster, dto.Redis{Address: "redis-1:6379", DB: 1}, true},
+		{"negative db", enum.RedisModeStandalone, dto.Redis{Address: "localhost:6379", DB: -1}, true},
+		{"invalid duration", enum.RedisModeStandalone, dto.Redis{Address: "localhost:6379", DialTimeout: "5"}, true},
+		{"missing tls ca file", enum.RedisModeStandalone, dto.Redis{Address: "localhost:6379",
+			TLS: &dto.RedisTLS{Enabled: true, CaFile: filepath.Join(t.TempDir(), "ca.pem")}}, true},
+		{"standalone", enum.RedisModeStandalone, dto.Redis{Address: "localhost:6379", DB: 2}, false},
+		{"sentinel", enum.RedisModeSentinel, dto.Redis{Address: "sentinel-1:26379",
+			Addresses: []string{"sentinel-2:26379"}, MasterName: "gopen"}, false},
+		{"cluster", enum.RedisModeCluster, dto.Redis{Address: "redis-1:6379", Addresses: []string{"redis-2:6379"}},
+			false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newRedisOptions(tt.mode, &tt.redisDTO)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newRedisOptions() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewRedisOptionsValues(t *testing.T) {
+	opts, err := newRedisOptions(enum.RedisModeCluster, &dto.Redis{
+		Address:      " redis-1:6379 ",
+		Addresses:    []string{"", "redis-2:6379"},
+		Username:     "gopen",
+		Password:     "secret",
+		PoolSize:     20,
+		MinIdleConns: 2,
+		PoolTimeout:  "4s",
+		DialTimeout:  "5s",
+		ReadTimeout:  "1s",
+		WriteTimeout: "2s",
+		TLS:          &dto.RedisTLS{Enabled: true},
+	})
+	if err != nil {
+		t.Fatalf("newRedisOptions() err = %v", err)
+	}
+	if want := []string{"redis-1:6379", "redis-2:6379"}; !reflect.DeepEqual(opts.Addrs, want) {
+		t.Errorf("Addrs = %v, want %v", opts.Addrs, want)
+	}
+	if opts.Username != "gopen" || opts.Password != "secret" || opts.PoolSize != 20 || opts.MinIdleConns != 2 {
+		t.Errorf("credentials and pool = %s %s %d %d", opts.Username, opts.Password, opts.PoolSize, opts.MinIdleConns)
+	}
+	if opts.PoolTimeout != 4*time.Second || opts.DialTimeout != 5*time.Second || opts.ReadTimeout != time.Second ||
+		opts.WriteTimeout != 2*time.Second {
+		t.Errorf("timeouts = %v %v %v %v", opts.PoolTimeout, opts.DialTimeout, opts.ReadTimeout, opts.WriteTimeout)
+	}
+	if opts.TLSConfig == nil || opts.TLSConfig.ServerName != "redis-1" {
+		t.Errorf("TLSConfig = %+v, want the server name of the first address", opts.TLSConfig)
+	}
+}
+
+func TestNewRedisTLSConfig(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t)
+	invalidFile := filepath.Join(t.TempDir(), "invalid.pem")
+	if err := os.WriteFile(invalidFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		tlsDTO         dto.RedisTLS
+		address        string
+		wantServerName string
+		wantRootCAs    bool
+		wantClientCert bool
+		wantErr        bool
+	}{
+		{name: "server name by host", address: "redis.local:6380", wantServerName: "redis.local"},
+		{name: "server name by address without port", address: "redis.local", wantServerName: "redis.local"},
+		{name: "configured server name", tlsDTO: dto.RedisTLS{ServerName: "cache.local"}, address: "10.0.0.1:6380",
+			wantServerName: "cache.local"},
+		{name: "ca file", tlsDTO: dto.RedisTLS{CaFile: certFile}, address: "redis.local:6380",
+			wantServerName: "redis.local", wantRootCAs: true},
+		{name: "client certificate", tlsDTO: dto.RedisTLS{CertFile: certFile, KeyFile: keyFile},
+			address: "redis.local:6380", wantServerName: "redis.local", wantClientCert: true},
+		{name: "ca file without certificate", tlsDTO: dto.RedisTLS{CaFile: invalidFile}, wantErr: true},
+		{name: "cert file without key file", tlsDTO: dto.RedisTLS{CertFile: certFile}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := newRedisTLSConfig(&tt.tlsDTO, tt.address)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newRedisTLSConfig() err = %v, wantErr %v", err, tt.wantErr)
+			} else if tt.wantErr {
+				return
+			}
+			if tlsConfig.MinVersion != tls.VersionTLS12 {
+				t.Errorf("MinVersion = %x, want TLS 1.2", tlsConfig.MinVersion)
+			}
+			if tlsConfig.ServerName != tt.wantServerName {
+				t.Errorf("ServerName = %q, want %q", tlsConfig.ServerName, tt.wantServerName)
+			}
+			if (tlsConfig.RootCAs != nil) != tt.wantRootCAs {
+				t.Errorf("RootCAs = %v, want %v", tlsConfig.RootCAs != nil, tt.wantRootCAs)
+			}
+			if (len(tlsConfig.Certificates) == 1) != tt.wantClientCert {
+				t.Errorf("Certificates = %d, want client certificate %v", len(tlsConfig.Certificates),
+					tt.wantClientCert)
+			}
+		})
+	}
+}
+
+func TestRedisStoreStandalone(t *testing.T) {
+	server := miniredis.RunT(t)
+	store := newTestRedisStore(t, &dto.Redis{Address: server.Addr(), DB: 2, KeyPrefix: "gopen:"})
+	ctx := context.Background()
+
+	if err := store.Set(ctx, "GET:/users", map[string]any{"status": 200}, time.Minute); err != nil {
+		t.Fatalf("Set() err = %v", err)
+	}
+	// a chave é gravada com o prefixo no banco selecionado
+	if !server.DB(2).Exists("gopen:GET:/users") || server.Exists("gopen:GET:/users") {
+		t.Fatalf("keys of db 2 = %v, want gopen:GET:/users", server.DB(2).Keys())
+	}
+
+	var value map[string]any
+	if err := store.Get(ctx, "GET:/users", &value); err != nil || value["status"] != float64(200) {
+		t.Fatalf("Get() = %v, %v, want the status 200", value, err)
+	}
+	if err := store.Get(ctx, "GET:/orders", &value); errors.IsNot(err, appmapper.ErrCacheNotFound) {
+		t.Fatalf("Get() of missing key err = %v, want not found", err)
+	}
+	if err := store.Peek(ctx, "GET:/orders", &value); errors.IsNot(err, appmapper.ErrCacheNotFound) {
+		t.Fatalf("Peek() of missing key err = %v, want not found", err)
+	}
+	if stats, _ := store.Stats(ctx); stats.Hits != 1 || stats.Misses != 1 {
+		t.Fatalf("Stats() = %+v, want 1 hit and 1 miss", stats)
+	}
+
+	if err := store.Del(ctx, "GET:/users"); err != nil || server.DB(2).Exists("gopen:GET:/users") {
+		t.Fatalf("Del() err = %v, key exists %v", err, server.DB(2).Exists("gopen:GET:/users"))
+	}
+}
+
+func TestRedisStoreScanAndDelByPattern(t *testing.T) {
+	server := miniredis.RunT(t)
+	store := newTestRedisStore(t, &dto.Redis{Address: server.Addr(), KeyPrefix: "gopen[1]:"})
+	ctx := context.Background()
+
+	// mais chaves do que um lote, para percorrer o cursor e os pipelines
+	var want []string
+	for i := 0; i < redisScanCount+5; i++ {
+		key := "GET:/users/" + strconv.Itoa(i)
+		want = append(want, key)
+		if err := store.Set(ctx, key, "value", time.Minute); err != nil {
+			t.Fatalf("Set() err = %v", err)
+		}
+	}
+	_ = store.Set(ctx, "POST:/users", "value", time.Minute)
+	// chaves de outro prefixo não são retornadas, o prefixo é escapado no MATCH
+	_ = server.Set("gopen1:GET:/users/0", "value")
+	_ = server.Set("other:GET:/users/0", "value")
+
+	keys, err := store.Scan(ctx, "GET:*")
+	if err != nil {
+		t.Fatalf("Scan() err = %v", err)
+	}
+	sort.Strings(keys)
+	sort.Strings(want)
+	if !reflect.DeepEqual(keys, want) {
+		t.Fatalf("Scan() = %d keys, want %d keys without the prefix", len(keys), len(want))
+	}
+
+	count, err := store.DelByPattern(ctx, "GET:*")
+	if err != nil || count != len(want) {
+		t.Fatalf("DelByPattern() = %d, %v, want %d", count, err, len(want))
+	}
+	remaining := server.Keys()
+	if want = []string{"gopen1:GET:/users/0", "gopen[1]:POST:/users", "other:GET:/users/0"}; !reflect.DeepEqual(
+		remaining, want) {
+		t.Fatalf("remaining keys = %v", remaining)
+	}
+}
+
+func TestRedisStoreScanCluster(t *testing.T) {
+	server := miniredis.RunT(t)
+	store := newTestRedisStore(t, &dto.Redis{Mode: enum.RedisModeCluster, Address: server.Addr(),
+		KeyPrefix: "gopen:"})
+	ctx := context.Background()
+
+	for _, key := range []string{"GET:/users", "GET:/orders"} {
+		if err := store.Set(ctx, key, "value", time.Minute); err != nil {
+			t.Fatalf("Set() err = %v", err)
+		}
+	}
+
+	keys, err := store.Scan(ctx, "GET:*")
+	sort.Strings(keys)
+	if err != nil || !reflect.DeepEqual(keys, []string{"GET:/orders", "GET:/users"}) {
+		t.Fatalf("Scan() = %v, %v, want the keys of every master without the prefix", keys, err)
+	}
+	if count, err := store.DelByPattern(ctx, "GET:/users"); err != nil || count != 1 {
+		t.Fatalf("DelByPattern() = %d, %v, want 1", count, err)
+	}
+}
+
+func TestRedisStoreLock(t *testing.T) {
+	server := miniredis.RunT(t)
+	store := newTestRedisStore(t, &dto.Redis{Address: server.Addr(), KeyPrefix: "gopen:"})
+	ctx := context.Background()
+
+	if locked, err := store.Lock(ctx, "lock", "first", time.Minute); err != nil || !locked {
+		t.Fatalf("Lock() = %v, %v, want true, nil", locked, err)
+	}
+	if ttl := server.TTL("gopen:lock"); ttl != time.Minute {
+		t.Fatalf("lock TTL = %v, want %v", ttl, time.Minute)
+	}
+	if locked, err := store.Lock(ctx, "lock", "second", time.Minute); err != nil || locked {
+		t.Fatalf("Lock() while locked = %v, %v, want false, nil", locked, err)
+	}
+
+	// o token de outra instância não libera o lock
+	if err := store.Unlock(ctx, "lock", "second"); err != nil || !server.Exists("gopen:lock") {
+		t.Fatalf("Unlock() with other token err = %v, lock exists %v", err, server.Exists("gopen:lock"))
+	}
+	if err := store.Unlock(ctx, "lock", "first"); err != nil || server.Exists("gopen:lock") {
+		t.Fatalf("Unlock() err = %v, lock exists %v", err, server.Exists("gopen:lock"))
+	}
+}
+
+// newTestRedisStore returns the Redis store of the configuration, closed with the test.
+func newTestRedisStore(t *testing.T, redisDTO *dto.Redis) CacheStore {
+	t.Helper()
+	store, err := NewRedisStore(redisDTO)
+	if err != nil {
+		t.Fatalf("NewRedisStore() err = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+// writeTestCertificate writes a self-signed certificate and its key on PEM files, returning their paths.
+func writeTestCertificate(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "redis.local"},
+		DNSNames:              []string{"redis.local"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err = os.WriteFile(certFile, certPem, 0o600); err != nil {
+		t.Fatal(err)
+	} else if err = os.WriteFile(keyFile, keyPem, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}